@@ -0,0 +1,153 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"airline-booking/logger"
+	"airline-booking/services"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+const (
+	// fasthttp 關閉時不會中斷保持連接，設置空閒超時讓它們在關閉期間能被回收
+	serverIdleTimeout = 60 * time.Second
+	serverReadTimeout = 30 * time.Second
+)
+
+// App 管理應用的生命週期：啟動 HTTP 服務與後台工作協程，
+// 並在退出時按依賴順序停止它們、釋放外部資源。
+type App struct {
+	addr            string
+	server          *fasthttp.Server
+	searchPool      *services.SearchWorkerPool
+	holdReaper      *services.HoldReaper
+	tracerCloser    io.Closer
+	db              *sql.DB
+	redis           *redis.Client
+	shutdownTimeout time.Duration
+}
+
+// Options 是構造 App 所需的組件，tracerCloser、db 和 redis 由 App 在退出時關閉
+type Options struct {
+	Addr            string
+	Handler         fasthttp.RequestHandler
+	SearchPool      *services.SearchWorkerPool
+	HoldReaper      *services.HoldReaper
+	TracerCloser    io.Closer
+	DB              *sql.DB
+	Redis           *redis.Client
+	ShutdownTimeout time.Duration
+}
+
+func New(opts Options) *App {
+	return &App{
+		addr: opts.Addr,
+		server: &fasthttp.Server{
+			Handler:     opts.Handler,
+			ReadTimeout: serverReadTimeout,
+			IdleTimeout: serverIdleTimeout,
+		},
+		searchPool:      opts.SearchPool,
+		holdReaper:      opts.HoldReaper,
+		tracerCloser:    opts.TracerCloser,
+		db:              opts.DB,
+		redis:           opts.Redis,
+		shutdownTimeout: opts.ShutdownTimeout,
+	}
+}
+
+// Run 啟動所有組件並阻塞，直到 ctx 結束（通常是收到 SIGINT/SIGTERM）或 HTTP 服務異常退出，
+// 然後執行關閉流程。返回啟動或關閉過程中遇到的錯誤。
+func (a *App) Run(ctx context.Context) error {
+	// 先綁定端口，端口被佔用時在啟動工作協程之前就失敗
+	ln, err := net.Listen("tcp4", a.addr)
+	if err != nil {
+		return errors.Join(err, a.closeResources())
+	}
+
+	a.searchPool.Start()
+	a.holdReaper.Start()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- a.server.Serve(ln)
+	}()
+	logger.Info("Server is running", zap.String("addr", a.addr))
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		logger.Info("Shutdown signal received, stopping")
+	case runErr = <-serveErr:
+		logger.Error("Server stopped unexpectedly", zap.Error(runErr))
+	}
+
+	return errors.Join(runErr, a.shutdown())
+}
+
+// shutdown 依次停止接收新連接並等待處理中的請求、排空搜索工作池、停止保留清理，
+// 上報剩餘的 trace，最後關閉 Redis 與數據庫連接。
+// 前三步共享 shutdownTimeout，超時後仍會繼續釋放資源。
+func (a *App) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+
+	// 先停 HTTP，確保不再有新的搜索任務入隊
+	if err := a.server.ShutdownWithContext(ctx); err != nil {
+		logger.Error("Failed to shut down HTTP server gracefully", zap.Error(err))
+		errs = append(errs, err)
+	}
+
+	if err := a.searchPool.Shutdown(ctx); err != nil {
+		logger.Error("Search workers did not drain before the deadline", zap.Error(err))
+		errs = append(errs, err)
+	}
+
+	if err := a.holdReaper.Shutdown(ctx); err != nil {
+		logger.Error("Hold reaper did not stop before the deadline", zap.Error(err))
+		errs = append(errs, err)
+	}
+
+	errs = append(errs, a.closeResources())
+
+	logger.Info("Shutdown complete")
+	return errors.Join(errs...)
+}
+
+// closeResources 按與初始化相反的順序釋放外部資源，單個失敗不影響其餘的關閉
+func (a *App) closeResources() error {
+	var errs []error
+
+	if a.tracerCloser != nil {
+		if err := a.tracerCloser.Close(); err != nil {
+			logger.Error("Failed to flush tracer", zap.Error(err))
+			errs = append(errs, err)
+		}
+	}
+
+	if a.redis != nil {
+		if err := a.redis.Close(); err != nil {
+			logger.Error("Failed to close Redis", zap.Error(err))
+			errs = append(errs, err)
+		}
+	}
+
+	if a.db != nil {
+		if err := a.db.Close(); err != nil {
+			logger.Error("Failed to close database", zap.Error(err))
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -3,49 +3,143 @@ package config
 import (
 	"database/sql"
 	"fmt"
+	"time"
+
+	"airline-booking/models"
 
 	"github.com/go-redis/redis/v8"
 	_ "github.com/lib/pq"
 )
 
 type Config struct {
-	DBHost        string
-	DBPort        string
-	DBUser        string
-	DBPassword    string
-	DBName        string
-	ServerPort    string
-	RedisAddr     string
-	RedisPassword string
-	RedisDB       int
+	DBHost     string `yaml:"db_host"`
+	DBPort     string `yaml:"db_port"`
+	DBUser     string `yaml:"db_user"`
+	DBPassword string `yaml:"db_password"`
+	DBName     string `yaml:"db_name"`
+	// DBSSLMode 對應 lib/pq 的 sslmode：disable、require、verify-ca、verify-full
+	DBSSLMode      string `yaml:"db_sslmode"`
+	DBMaxOpenConns int    `yaml:"db_max_open_conns"`
+	DBMaxIdleConns int    `yaml:"db_max_idle_conns"`
+	// DBAutoMigrate 為 true 時啟動前執行所有未執行的遷移，否則需手動運行 migrate 子命令
+	DBAutoMigrate bool `yaml:"db_auto_migrate"`
+
+	ServerPort string `yaml:"server_port"`
+
+	RedisAddr     string `yaml:"redis_addr"`
+	RedisPassword string `yaml:"redis_password"`
+	RedisDB       int    `yaml:"redis_db"`
+	RedisPoolSize int    `yaml:"redis_pool_size"`
+
+	// 搜索隊列："memory" 只在本進程內處理，"redis" 讓多個實例共享任務與結果
+	SearchQueueBackend string        `yaml:"search_queue_backend"`
+	SearchQueueSize    int           `yaml:"search_queue_size"`
+	SearchJobTTL       time.Duration `yaml:"search_job_ttl"`
+	// 內存隊列保存已結束任務與結果的容量限制，排隊中與執行中的任務不計入也不會被淘汰
+	SearchJobMaxEntries int `yaml:"search_job_max_entries"`
+	SearchJobMaxMB      int `yaml:"search_job_max_mb"`
+	// SearchCacheTTL 是航班搜索結果在 Redis 中的緩存時間
+	SearchCacheTTL time.Duration `yaml:"search_cache_ttl"`
+
+	// 進程內 LRU 緩存：搜索結果在 Redis 前的本地層。
+	// LocalCacheTTL 是搜索結果在本地層的存活時間，為 0 時不啟用本地層
+	LocalCacheTTL        time.Duration `yaml:"local_cache_ttl"`
+	LocalCacheMaxEntries int           `yaml:"local_cache_max_entries"`
+	LocalCacheMaxMB      int           `yaml:"local_cache_max_mb"`
+
+	// FareBuckets 是按艙位載客率劃分的票價等級，按 max_load_factor 由低到高排列。
+	// 列表只能在 YAML 文件中配置，沒有對應的環境變量
+	FareBuckets []models.FareBucket `yaml:"fare_buckets"`
+
+	// BookingHoldTTL 是新預訂在支付前保留座位的時間，HoldReapInterval 是後台釋放到期保留的間隔
+	BookingHoldTTL   time.Duration `yaml:"booking_hold_ttl"`
+	HoldReapInterval time.Duration `yaml:"hold_reap_interval"`
+
+	// PaymentGateway 是收取票款的支付網關，目前只有進程內的 "fake" 實現
+	PaymentGateway string `yaml:"payment_gateway"`
+
+	// 搜索工作池：工作協程數量與單個任務的超時時間
+	SearchWorkers    int           `yaml:"search_workers"`
+	SearchJobTimeout time.Duration `yaml:"search_job_timeout"`
+
+	// ShutdownTimeout 是收到退出信號後等待 HTTP 請求與搜索任務完成的最長時間
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	// Jaeger 採樣器：const 時 param 為 0 或 1，probabilistic 時為採樣率，ratelimiting 時為每秒 span 數
+	TracingSamplerType  string  `yaml:"tracing_sampler_type"`
+	TracingSamplerParam float64 `yaml:"tracing_sampler_param"`
 }
 
+// NewConfig 返回默認配置，適用於本地開發
 func NewConfig() *Config {
 	return &Config{
-		DBHost:        "localhost",
-		DBPort:        "5432",
-		DBUser:        "username",
-		DBPassword:    "password",
-		DBName:        "airline_db",
-		ServerPort:    "8080",
+		DBHost:         "localhost",
+		DBPort:         "5432",
+		DBUser:         "username",
+		DBPassword:     "password",
+		DBName:         "airline_db",
+		DBSSLMode:      "disable",
+		DBMaxOpenConns: 25,
+		DBMaxIdleConns: 5,
+		DBAutoMigrate:  true,
+
+		ServerPort: "8080",
+
 		RedisAddr:     "localhost:6379",
 		RedisPassword: "", // 如果有密碼，請設置
 		RedisDB:       0,
+		RedisPoolSize: 10,
+
+		SearchQueueBackend: "redis",
+		SearchQueueSize:    100,
+		SearchJobTTL:       15 * time.Minute,
+		SearchCacheTTL:     15 * time.Minute,
+
+		SearchJobMaxEntries: 10000,
+		SearchJobMaxMB:      64,
+
+		LocalCacheTTL:        5 * time.Second,
+		LocalCacheMaxEntries: 10000,
+		LocalCacheMaxMB:      64,
+
+		FareBuckets: []models.FareBucket{
+			{Name: "Q", MaxLoadFactor: 0.5, Multiplier: 0.8},
+			{Name: "M", MaxLoadFactor: 0.7, Multiplier: 1},
+			{Name: "B", MaxLoadFactor: 0.85, Multiplier: 1.3},
+			{Name: "Y", MaxLoadFactor: 1, Multiplier: 1.7},
+		},
+
+		BookingHoldTTL:   15 * time.Minute,
+		HoldReapInterval: 30 * time.Second,
+
+		PaymentGateway: "fake",
+
+		SearchWorkers:    4,
+		SearchJobTimeout: 10 * time.Second,
+
+		ShutdownTimeout: 30 * time.Second,
+
+		TracingSamplerType:  "const",
+		TracingSamplerParam: 1,
 	}
 }
 
 func InitDB(cfg *Config) (*sql.DB, error) {
-	psqlInfo := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
+	psqlInfo := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBSSLMode)
 
 	db, err := sql.Open("postgres", psqlInfo)
 	if err != nil {
 		return nil, err
 	}
 
+	db.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	db.SetMaxIdleConns(cfg.DBMaxIdleConns)
+
 	err = db.Ping()
 	if err != nil {
-		return nil, err
+		db.Close()
+		return nil, fmt.Errorf("connect to postgres at %s:%s: %w", cfg.DBHost, cfg.DBPort, err)
 	}
 
 	return db, nil
@@ -56,12 +150,14 @@ func InitRedis(cfg *Config) (*redis.Client, error) {
 		Addr:     cfg.RedisAddr,
 		Password: cfg.RedisPassword,
 		DB:       cfg.RedisDB,
+		PoolSize: cfg.RedisPoolSize,
 	})
 
 	ctx := client.Context()
 	_, err := client.Ping(ctx).Result()
 	if err != nil {
-		return nil, err
+		client.Close()
+		return nil, fmt.Errorf("connect to redis at %s: %w", cfg.RedisAddr, err)
 	}
 
 	return client, nil
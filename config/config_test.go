@@ -0,0 +1,138 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"airline-booking/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := config.Load("")
+	assert.NoError(t, err)
+	assert.Equal(t, config.NewConfig(), cfg)
+}
+
+func TestLoad_EnvOverridesFile(t *testing.T) {
+	path := writeConfigFile(t, `
+db_host: db.internal
+db_user: fromfile
+search_cache_ttl: 5m
+search_workers: 8
+tracing_sampler_type: probabilistic
+tracing_sampler_param: 0.1
+`)
+	t.Setenv("DB_USER", "fromenv")
+	t.Setenv("REDIS_ADDR", "redis:6379")
+	t.Setenv("SEARCH_JOB_TIMEOUT", "3s")
+
+	cfg, err := config.Load(path)
+	assert.NoError(t, err)
+
+	// 文件覆蓋默認值，環境變量再覆蓋文件
+	assert.Equal(t, "db.internal", cfg.DBHost)
+	assert.Equal(t, "fromenv", cfg.DBUser)
+	assert.Equal(t, "redis:6379", cfg.RedisAddr)
+	assert.Equal(t, 5*time.Minute, cfg.SearchCacheTTL)
+	assert.Equal(t, 8, cfg.SearchWorkers)
+	assert.Equal(t, 3*time.Second, cfg.SearchJobTimeout)
+	assert.Equal(t, "probabilistic", cfg.TracingSamplerType)
+	// 未設置的值保留默認
+	assert.Equal(t, "5432", cfg.DBPort)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name:    "unknown key in file",
+			file:    "db_hots: typo\n",
+			wantErr: "field db_hots not found",
+		},
+		{
+			name:    "malformed env integer",
+			env:     map[string]string{"SEARCH_WORKERS": "many"},
+			wantErr: `SEARCH_WORKERS: "many" is not an integer`,
+		},
+		{
+			name:    "malformed env duration",
+			env:     map[string]string{"SEARCH_JOB_TTL": "15"},
+			wantErr: "SEARCH_JOB_TTL",
+		},
+		{
+			name:    "invalid queue backend",
+			env:     map[string]string{"SEARCH_QUEUE_BACKEND": "kafka"},
+			wantErr: "search_queue_backend",
+		},
+		{
+			name:    "invalid sslmode",
+			file:    "db_sslmode: sometimes\n",
+			wantErr: "db_sslmode",
+		},
+		{
+			name:    "local cache outlives shared cache",
+			env:     map[string]string{"LOCAL_CACHE_TTL": "1h"},
+			wantErr: "local_cache_ttl",
+		},
+		{
+			name:    "fare buckets out of order",
+			file:    "fare_buckets:\n  - {name: Y, max_load_factor: 1, multiplier: 1.7}\n  - {name: Q, max_load_factor: 0.5, multiplier: 0.8}\n",
+			wantErr: "fare_buckets[1]: max_load_factor",
+		},
+		{
+			name:    "hold reaped less often than it expires",
+			env:     map[string]string{"BOOKING_HOLD_TTL": "1m", "HOLD_REAP_INTERVAL": "5m"},
+			wantErr: "hold_reap_interval",
+		},
+		{
+			name:    "unknown payment gateway",
+			env:     map[string]string{"PAYMENT_GATEWAY": "stripe"},
+			wantErr: "payment_gateway",
+		},
+		{
+			name:    "sampler param out of range",
+			env:     map[string]string{"TRACING_SAMPLER_TYPE": "probabilistic", "TRACING_SAMPLER_PARAM": "2"},
+			wantErr: "tracing_sampler_param",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := ""
+			if tt.file != "" {
+				path = writeConfigFile(t, tt.file)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			_, err := config.Load(path)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestValidate_ReportsAllProblems(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.ServerPort = "http"
+	cfg.SearchWorkers = 0
+
+	err := cfg.Validate()
+	assert.ErrorContains(t, err, "server_port")
+	assert.ErrorContains(t, err, "search_workers")
+}
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Load 按 默認值 → YAML 文件 → 環境變量 的順序疊加配置，後者覆蓋前者，最後校驗。
+// path 為空時跳過 YAML 文件。
+func Load(path string) (*Config, error) {
+	cfg := NewConfig()
+
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	// 拼錯的鍵直接報錯，而不是被靜默忽略
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv 讀取環境變量覆蓋配置，lookup 便於測試時替換環境
+func (c *Config) loadEnv(lookup func(string) (string, bool)) error {
+	var errs []error
+
+	str := func(name string, dst *string) {
+		if v, ok := lookup(name); ok {
+			*dst = v
+		}
+	}
+	integer := func(name string, dst *int) {
+		if v, ok := lookup(name); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q is not an integer", name, v))
+				return
+			}
+			*dst = n
+		}
+	}
+	float := func(name string, dst *float64) {
+		if v, ok := lookup(name); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q is not a number", name, v))
+				return
+			}
+			*dst = f
+		}
+	}
+	boolean := func(name string, dst *bool) {
+		if v, ok := lookup(name); ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q is not a boolean", name, v))
+				return
+			}
+			*dst = b
+		}
+	}
+	duration := func(name string, dst *time.Duration) {
+		if v, ok := lookup(name); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q is not a duration (e.g. 30s, 15m)", name, v))
+				return
+			}
+			*dst = d
+		}
+	}
+
+	str("DB_HOST", &c.DBHost)
+	str("DB_PORT", &c.DBPort)
+	str("DB_USER", &c.DBUser)
+	str("DB_PASSWORD", &c.DBPassword)
+	str("DB_NAME", &c.DBName)
+	str("DB_SSLMODE", &c.DBSSLMode)
+	integer("DB_MAX_OPEN_CONNS", &c.DBMaxOpenConns)
+	integer("DB_MAX_IDLE_CONNS", &c.DBMaxIdleConns)
+	boolean("DB_AUTO_MIGRATE", &c.DBAutoMigrate)
+
+	str("SERVER_PORT", &c.ServerPort)
+
+	str("REDIS_ADDR", &c.RedisAddr)
+	str("REDIS_PASSWORD", &c.RedisPassword)
+	integer("REDIS_DB", &c.RedisDB)
+	integer("REDIS_POOL_SIZE", &c.RedisPoolSize)
+
+	str("SEARCH_QUEUE_BACKEND", &c.SearchQueueBackend)
+	integer("SEARCH_QUEUE_SIZE", &c.SearchQueueSize)
+	duration("SEARCH_JOB_TTL", &c.SearchJobTTL)
+	integer("SEARCH_JOB_MAX_ENTRIES", &c.SearchJobMaxEntries)
+	integer("SEARCH_JOB_MAX_MB", &c.SearchJobMaxMB)
+	duration("SEARCH_CACHE_TTL", &c.SearchCacheTTL)
+	duration("LOCAL_CACHE_TTL", &c.LocalCacheTTL)
+	integer("LOCAL_CACHE_MAX_ENTRIES", &c.LocalCacheMaxEntries)
+	integer("LOCAL_CACHE_MAX_MB", &c.LocalCacheMaxMB)
+	duration("BOOKING_HOLD_TTL", &c.BookingHoldTTL)
+	duration("HOLD_REAP_INTERVAL", &c.HoldReapInterval)
+	str("PAYMENT_GATEWAY", &c.PaymentGateway)
+	integer("SEARCH_WORKERS", &c.SearchWorkers)
+	duration("SEARCH_JOB_TIMEOUT", &c.SearchJobTimeout)
+
+	duration("SHUTDOWN_TIMEOUT", &c.ShutdownTimeout)
+
+	str("TRACING_SAMPLER_TYPE", &c.TracingSamplerType)
+	float("TRACING_SAMPLER_PARAM", &c.TracingSamplerParam)
+
+	return errors.Join(errs...)
+}
+
+// Validate 檢查配置是否可用，一次返回所有問題，便於啟動失敗時一併修正
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.DBHost != "", "db_host is required")
+	check(isPort(c.DBPort), "db_port: %q is not a valid port", c.DBPort)
+	check(c.DBUser != "", "db_user is required")
+	check(c.DBName != "", "db_name is required")
+	switch c.DBSSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		errs = append(errs, fmt.Errorf("db_sslmode: unsupported value %q", c.DBSSLMode))
+	}
+	check(c.DBMaxOpenConns > 0, "db_max_open_conns must be positive")
+	check(c.DBMaxIdleConns >= 0 && c.DBMaxIdleConns <= c.DBMaxOpenConns,
+		"db_max_idle_conns must be between 0 and db_max_open_conns")
+
+	check(isPort(c.ServerPort), "server_port: %q is not a valid port", c.ServerPort)
+
+	check(c.RedisAddr != "", "redis_addr is required")
+	check(c.RedisDB >= 0, "redis_db must not be negative")
+	check(c.RedisPoolSize > 0, "redis_pool_size must be positive")
+
+	check(c.SearchQueueBackend == "memory" || c.SearchQueueBackend == "redis",
+		"search_queue_backend: must be \"memory\" or \"redis\", got %q", c.SearchQueueBackend)
+	check(c.SearchQueueSize > 0, "search_queue_size must be positive")
+	check(c.SearchJobTTL > 0, "search_job_ttl must be positive")
+	check(c.SearchJobMaxEntries > 0, "search_job_max_entries must be positive")
+	check(c.SearchJobMaxMB > 0, "search_job_max_mb must be positive")
+	check(c.SearchCacheTTL > 0, "search_cache_ttl must be positive")
+	check(c.LocalCacheTTL >= 0 && c.LocalCacheTTL < c.SearchCacheTTL,
+		"local_cache_ttl must be between 0 and search_cache_ttl")
+	check(c.LocalCacheMaxEntries > 0, "local_cache_max_entries must be positive")
+	check(c.LocalCacheMaxMB > 0, "local_cache_max_mb must be positive")
+	check(len(c.FareBuckets) > 0, "fare_buckets must not be empty")
+	for i, bucket := range c.FareBuckets {
+		check(bucket.Name != "", "fare_buckets[%d]: name is required", i)
+		check(bucket.Multiplier > 0, "fare_buckets[%d]: multiplier must be positive", i)
+		check(i == 0 || bucket.MaxLoadFactor > c.FareBuckets[i-1].MaxLoadFactor,
+			"fare_buckets[%d]: max_load_factor must be greater than the previous bucket", i)
+	}
+	check(c.BookingHoldTTL > 0, "booking_hold_ttl must be positive")
+	check(c.HoldReapInterval > 0 && c.HoldReapInterval <= c.BookingHoldTTL,
+		"hold_reap_interval must be between 0 and booking_hold_ttl")
+	check(c.PaymentGateway == "fake", "payment_gateway: must be \"fake\", got %q", c.PaymentGateway)
+	check(c.SearchWorkers > 0, "search_workers must be positive")
+	check(c.SearchJobTimeout > 0, "search_job_timeout must be positive")
+	check(c.ShutdownTimeout > 0, "shutdown_timeout must be positive")
+
+	switch c.TracingSamplerType {
+	case "const":
+		check(c.TracingSamplerParam == 0 || c.TracingSamplerParam == 1,
+			"tracing_sampler_param must be 0 or 1 for the const sampler")
+	case "probabilistic":
+		check(c.TracingSamplerParam >= 0 && c.TracingSamplerParam <= 1,
+			"tracing_sampler_param must be between 0 and 1 for the probabilistic sampler")
+	case "ratelimiting":
+		check(c.TracingSamplerParam > 0, "tracing_sampler_param must be positive for the ratelimiting sampler")
+	default:
+		errs = append(errs, fmt.Errorf("tracing_sampler_type: unsupported value %q", c.TracingSamplerType))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func isPort(s string) bool {
+	n, err := strconv.Atoi(s)
+	return err == nil && n > 0 && n <= 65535
+}
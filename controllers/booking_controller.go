@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"time"
+
+	"airline-booking/models"
+	"airline-booking/services"
+
+	"github.com/valyala/fasthttp"
+)
+
+type BookingController struct {
+	service  services.BookingService
+	payments services.PaymentService
+}
+
+func NewBookingController(service services.BookingService, payments services.PaymentService) *BookingController {
+	return &BookingController{service: service, payments: payments}
+}
+
+// createBookingRequest 只接受客戶端可以決定的欄位，狀態、票價等由服務層設置
+type createBookingRequest struct {
+	PassengerID     int                `json:"passenger_id"`
+	FlightID        int                `json:"flight_id"`
+	Class           string             `json:"class"`
+	FareClass       string             `json:"fare_class"`
+	SeatNumber      string             `json:"seat_number"`
+	SpecialRequests []string           `json:"special_requests"`
+	BaggageInfo     models.BaggageInfo `json:"baggage_info"`
+}
+
+// checkInRequest 是登機請求的可選請求體
+type checkInRequest struct {
+	SeatNumber string `json:"seat_number"`
+}
+
+func (c *BookingController) CreateBooking(ctx *fasthttp.RequestCtx) {
+	var req createBookingRequest
+	if !decodeBody(ctx, &req) {
+		return
+	}
+
+	booking := &models.Booking{
+		PassengerID:     req.PassengerID,
+		FlightID:        req.FlightID,
+		Class:           req.Class,
+		FareClass:       req.FareClass,
+		SeatNumber:      req.SeatNumber,
+		SpecialRequests: req.SpecialRequests,
+		BaggageInfo:     req.BaggageInfo,
+	}
+	if err := c.service.CreateBooking(ctx, booking); err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+
+	writeJSON(ctx, fasthttp.StatusCreated, booking)
+}
+
+func (c *BookingController) GetBooking(ctx *fasthttp.RequestCtx) {
+	bookingID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	booking, err := c.service.GetBooking(ctx, bookingID)
+	if err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+
+	writeJSON(ctx, fasthttp.StatusOK, booking)
+}
+
+func (c *BookingController) UpdateBooking(ctx *fasthttp.RequestCtx) {
+	bookingID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var update models.BookingUpdate
+	if !decodeBody(ctx, &update) {
+		return
+	}
+
+	booking, err := c.service.UpdateBooking(ctx, bookingID, update)
+	if err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+
+	writeJSON(ctx, fasthttp.StatusOK, booking)
+}
+
+func (c *BookingController) CancelBooking(ctx *fasthttp.RequestCtx) {
+	bookingID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	booking, err := c.service.CancelBooking(ctx, bookingID)
+	if err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+
+	writeJSON(ctx, fasthttp.StatusOK, booking)
+}
+
+// QuoteCancellation 試算取消預訂的退款，讓客服在取消前向乘客說明結果
+func (c *BookingController) QuoteCancellation(ctx *fasthttp.RequestCtx) {
+	bookingID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	quote, err := c.service.QuoteCancellation(ctx, bookingID)
+	if err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+
+	writeJSON(ctx, fasthttp.StatusOK, quote)
+}
+
+// PayBooking 為保留中的預訂付款並在扣款成功後確認預訂，以同一個 idempotency_key 重試返回同一筆付款
+func (c *BookingController) PayBooking(ctx *fasthttp.RequestCtx) {
+	bookingID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req models.PaymentRequest
+	if !decodeBody(ctx, &req) {
+		return
+	}
+
+	payment, err := c.service.PayBooking(ctx, bookingID, req)
+	if err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+
+	writeJSON(ctx, fasthttp.StatusCreated, payment)
+}
+
+func (c *BookingController) ListPayments(ctx *fasthttp.RequestCtx) {
+	bookingID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	// 先查詢預訂，不存在的預訂返回 404 而不是空列表
+	if _, err := c.service.GetBooking(ctx, bookingID); err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+	payments, err := c.payments.ListPayments(ctx, bookingID)
+	if err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+	if payments == nil {
+		payments = []*models.Payment{}
+	}
+
+	writeJSON(ctx, fasthttp.StatusOK, payments)
+}
+
+func (c *BookingController) CheckIn(ctx *fasthttp.RequestCtx) {
+	bookingID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	// 請求體可選，只在登機時換座位才需要
+	var req checkInRequest
+	if len(ctx.PostBody()) > 0 && !decodeBody(ctx, &req) {
+		return
+	}
+
+	if err := c.service.CheckIn(ctx, bookingID, req.SeatNumber); err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+
+	ctx.SetStatusCode(fasthttp.StatusNoContent)
+}
+
+func (c *BookingController) ListBookings(ctx *fasthttp.RequestCtx) {
+	filter, ok := bookingFilterFromQuery(ctx)
+	if !ok {
+		return
+	}
+	if filter.PassengerID, ok = queryInt(ctx, "passenger_id"); !ok {
+		return
+	}
+
+	c.listBookings(ctx, filter)
+}
+
+func (c *BookingController) ListBookingsByPassenger(ctx *fasthttp.RequestCtx) {
+	passengerID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+	filter, ok := bookingFilterFromQuery(ctx)
+	if !ok {
+		return
+	}
+	filter.PassengerID = passengerID
+
+	c.listBookings(ctx, filter)
+}
+
+func (c *BookingController) listBookings(ctx *fasthttp.RequestCtx, filter models.BookingFilter) {
+	page, err := c.service.ListBookings(ctx, filter)
+	if err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+	if page.Items == nil {
+		page.Items = []*models.Booking{}
+	}
+
+	writeJSON(ctx, fasthttp.StatusOK, page)
+}
+
+// bookingFilterFromQuery 解析預訂列表共用的過濾與分頁參數，失敗時直接寫入 400 回應
+func bookingFilterFromQuery(ctx *fasthttp.RequestCtx) (models.BookingFilter, bool) {
+	args := ctx.QueryArgs()
+	filter := models.BookingFilter{
+		Status: string(args.Peek("status")),
+		Class:  string(args.Peek("class")),
+		Cursor: string(args.Peek("cursor")),
+	}
+
+	var ok bool
+	if filter.FlightID, ok = queryInt(ctx, "flight_id"); !ok {
+		return filter, false
+	}
+	if filter.PageSize, ok = queryInt(ctx, "page_size"); !ok {
+		return filter, false
+	}
+	for name, dst := range map[string]*time.Time{"date_from": &filter.DateFrom, "date_to": &filter.DateTo} {
+		if raw := args.Peek(name); len(raw) > 0 {
+			t, err := time.Parse("2006-01-02", string(raw))
+			if err != nil {
+				writeError(ctx, fasthttp.StatusBadRequest, "invalid "+name+", expected YYYY-MM-DD")
+				return filter, false
+			}
+			*dst = t
+		}
+	}
+	return filter, true
+}
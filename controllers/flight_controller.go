@@ -1,7 +1,11 @@
 package controllers
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"airline-booking/models"
@@ -10,25 +14,47 @@ import (
 	"github.com/valyala/fasthttp"
 )
 
+const (
+	// maxLongPollSeconds 是 /flights/results 長輪詢允許的最長等待時間
+	maxLongPollSeconds = 30
+	// maxStreamDuration 是單個 SSE 連接等待任務結束的最長時間
+	maxStreamDuration = 2 * time.Minute
+	// streamHeartbeatInterval 是 SSE 心跳的間隔
+	streamHeartbeatInterval = 15 * time.Second
+)
+
+// statusEvent 是 SSE status 事件的內容
+type statusEvent struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// searchStats 是 /flights/search/stats 的回應：工作池指標加上各層搜索緩存的命中統計
+type searchStats struct {
+	services.SearchWorkerPoolStats
+	Cache services.SearchCacheStats `json:"cache"`
+}
+
 type FlightController struct {
 	service services.FlightService
+	seats   services.SeatService
+	pool    *services.SearchWorkerPool
+	cache   services.SearchCache
 }
 
-func NewFlightController(service services.FlightService) *FlightController {
-	return &FlightController{service: service}
+func NewFlightController(service services.FlightService, seats services.SeatService, pool *services.SearchWorkerPool, cache services.SearchCache) *FlightController {
+	return &FlightController{service: service, seats: seats, pool: pool, cache: cache}
 }
 
 func (c *FlightController) SearchFlights(ctx *fasthttp.RequestCtx) {
 	var req models.SearchRequest
-	err := json.Unmarshal(ctx.PostBody(), &req)
-	if err != nil {
-		ctx.Error(err.Error(), fasthttp.StatusBadRequest)
+	if !decodeBody(ctx, &req) {
 		return
 	}
 
 	requestID, err := c.service.SearchFlights(ctx, req)
 	if err != nil {
-		ctx.Error(err.Error(), fasthttp.StatusInternalServerError)
+		writeServiceError(ctx, err)
 		return
 	}
 
@@ -36,23 +62,232 @@ func (c *FlightController) SearchFlights(ctx *fasthttp.RequestCtx) {
 	ctx.SetBodyString(requestID)
 }
 
+// SearchItineraries 同步返回含轉機的行程列表
+func (c *FlightController) SearchItineraries(ctx *fasthttp.RequestCtx) {
+	var req models.ItinerarySearchRequest
+	if !decodeBody(ctx, &req) {
+		return
+	}
+
+	itineraries, err := c.service.SearchItineraries(ctx, req)
+	if err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+
+	writeJSON(ctx, fasthttp.StatusOK, itineraries)
+}
+
+// GetFareCalendar 返回低價日曆：month=YYYY-MM 查詢整月，或 date=YYYY-MM-DD 配合 flex_days 查詢前後 N 天
+func (c *FlightController) GetFareCalendar(ctx *fasthttp.RequestCtx) {
+	args := ctx.QueryArgs()
+	req := models.FareCalendarRequest{
+		Origin:      string(args.Peek("origin")),
+		Destination: string(args.Peek("destination")),
+		Month:       string(args.Peek("month")),
+		Cabin:       string(args.Peek("cabin")),
+	}
+
+	if raw := args.Peek("date"); len(raw) > 0 {
+		date, err := time.Parse("2006-01-02", string(raw))
+		if err != nil {
+			writeError(ctx, fasthttp.StatusBadRequest, "invalid date, expected YYYY-MM-DD")
+			return
+		}
+		req.Date = date
+	}
+
+	var ok bool
+	if req.FlexDays, ok = queryInt(ctx, "flex_days"); !ok {
+		return
+	}
+	if req.Passengers.Adults, ok = queryInt(ctx, "adults"); !ok {
+		return
+	}
+	if req.Passengers.Children, ok = queryInt(ctx, "children"); !ok {
+		return
+	}
+	if req.Passengers.Infants, ok = queryInt(ctx, "infants"); !ok {
+		return
+	}
+
+	calendar, err := c.service.GetFareCalendar(ctx, req)
+	if err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+
+	writeJSON(ctx, fasthttp.StatusOK, calendar)
+}
+
+// GetSearchResults 返回搜索任務的狀態與結果。
+// 可選的 wait 參數（秒）啟用長輪詢：任務結束時立即返回，而不是在服務端睡眠輪詢。
 func (c *FlightController) GetSearchResults(ctx *fasthttp.RequestCtx) {
 	requestID := ctx.QueryArgs().Peek("request_id")
 	if requestID == nil {
-		ctx.Error("Missing request_id", fasthttp.StatusBadRequest)
+		writeError(ctx, fasthttp.StatusBadRequest, "missing request_id")
+		return
+	}
+
+	wait, ok := queryInt(ctx, "wait")
+	if !ok {
+		return
+	}
+	if wait < 0 || wait > maxLongPollSeconds {
+		writeError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("wait must be between 0 and %d seconds", maxLongPollSeconds))
+		return
+	}
+
+	var job *models.SearchJob
+	var err error
+	if wait == 0 {
+		job, err = c.service.GetSearchJob(ctx, string(requestID))
+	} else {
+		waitCtx, cancel := context.WithTimeout(ctx, time.Duration(wait)*time.Second)
+		defer cancel()
+		job, err = c.service.WaitForSearchJob(waitCtx, string(requestID))
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = nil
+			// 沒有拿到快照時按未等待處理，返回任務的當前狀態
+			if job == nil {
+				job, err = c.service.GetSearchJob(ctx, string(requestID))
+			}
+		}
+	}
+	if err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+
+	writeJSON(ctx, searchJobStatusCode(job), job)
+}
+
+// StreamSearchResults 以 server-sent events 推送搜索任務：
+// 先發送一個 status 事件，任務結束後發送 results（或 failed）事件並關閉連接
+func (c *FlightController) StreamSearchResults(ctx *fasthttp.RequestCtx) {
+	requestID := string(ctx.QueryArgs().Peek("request_id"))
+	if requestID == "" {
+		writeError(ctx, fasthttp.StatusBadRequest, "missing request_id")
+		return
+	}
+
+	job, err := c.service.GetSearchJob(ctx, requestID)
+	if err != nil {
+		writeServiceError(ctx, err)
 		return
 	}
 
-	maxRetries := 5
-	for i := 0; i < maxRetries; i++ {
-		flights, err := c.service.GetSearchResults(string(requestID))
-		if err == nil {
-			ctx.SetContentType("application/json")
-			json.NewEncoder(ctx).Encode(flights)
+	ctx.SetContentType("text/event-stream")
+	ctx.Response.Header.Set("Cache-Control", "no-cache")
+	ctx.Response.Header.Set("Connection", "keep-alive")
+	ctx.Response.Header.Set("X-Accel-Buffering", "no")
+
+	// 流寫入函數在處理函數返回後執行，不能再使用 RequestCtx
+	service := c.service
+	ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := writeEvent(w, "status", statusEvent{ID: job.ID, Status: job.Status}); err != nil {
 			return
 		}
-		time.Sleep(time.Second)
+
+		deadline := time.Now().Add(maxStreamDuration)
+		for !job.IsFinished() && time.Now().Before(deadline) {
+			waitCtx, cancel := context.WithTimeout(context.Background(), streamHeartbeatInterval)
+			next, err := service.WaitForSearchJob(waitCtx, requestID)
+			cancel()
+			if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				writeEvent(w, "error", errorResponse{Error: err.Error()})
+				return
+			}
+			// 等待超時而沒有拿到快照時沿用上一次的狀態
+			if next != nil {
+				job = next
+			}
+			if job.IsFinished() {
+				break
+			}
+			// 心跳註釋讓代理與客戶端保持連接，寫入失敗表示客戶端已斷開
+			if _, err := w.WriteString(": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+
+		switch job.Status {
+		case models.SearchJobDone:
+			writeEvent(w, "results", job)
+		case models.SearchJobFailed:
+			writeEvent(w, "failed", job)
+		default:
+			writeEvent(w, "timeout", statusEvent{ID: job.ID, Status: job.Status})
+		}
+	})
+}
+
+// GetFareClasses 返回航班的票價艙等、剩餘配額與退改規則
+func (c *FlightController) GetFareClasses(ctx *fasthttp.RequestCtx) {
+	flightID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	classes, err := c.service.GetFareClasses(ctx, flightID)
+	if err != nil {
+		writeServiceError(ctx, err)
+		return
 	}
 
-	ctx.Error("Results not ready, please try again later", fasthttp.StatusNotFound)
+	writeJSON(ctx, fasthttp.StatusOK, classes)
+}
+
+// GetSeatMap 返回航班每個座位的位置、屬性與當前是否可選
+func (c *FlightController) GetSeatMap(ctx *fasthttp.RequestCtx) {
+	flightID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	seatMap, err := c.seats.GetSeatMap(ctx, flightID)
+	if err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+
+	writeJSON(ctx, fasthttp.StatusOK, seatMap)
+}
+
+// GetSearchStats 返回搜索隊列深度、工作池的執行中任務數與緩存命中率，用於容量規劃
+func (c *FlightController) GetSearchStats(ctx *fasthttp.RequestCtx) {
+	stats, err := c.pool.Stats(ctx)
+	if err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+
+	writeJSON(ctx, fasthttp.StatusOK, searchStats{SearchWorkerPoolStats: stats, Cache: c.cache.Stats()})
+}
+
+// searchJobStatusCode 將任務狀態映射為 HTTP 狀態碼：完成 200，失敗 500，排隊或執行中 202
+func searchJobStatusCode(job *models.SearchJob) int {
+	switch job.Status {
+	case models.SearchJobDone:
+		return fasthttp.StatusOK
+	case models.SearchJobFailed:
+		return fasthttp.StatusInternalServerError
+	default:
+		return fasthttp.StatusAccepted
+	}
+}
+
+// writeEvent 寫入並立即刷新一個 SSE 事件
+func writeEvent(w *bufio.Writer, event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	return w.Flush()
 }
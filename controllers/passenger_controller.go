@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+
+	"airline-booking/models"
+	"airline-booking/services"
+
+	"github.com/valyala/fasthttp"
+)
+
+type PassengerController struct {
+	service services.PassengerService
+}
+
+func NewPassengerController(service services.PassengerService) *PassengerController {
+	return &PassengerController{service: service}
+}
+
+// registerPassengerRequest 只包含乘客自行填寫的資料，常旅客統計由系統維護
+type registerPassengerRequest struct {
+	FirstName             string    `json:"first_name"`
+	LastName              string    `json:"last_name"`
+	Email                 string    `json:"email"`
+	PhoneNumber           string    `json:"phone_number"`
+	DateOfBirth           time.Time `json:"date_of_birth"`
+	Nationality           string    `json:"nationality"`
+	PassportNumber        string    `json:"passport_number"`
+	PassportExpiry        time.Time `json:"passport_expiry"`
+	Address               string    `json:"address"`
+	City                  string    `json:"city"`
+	Country               string    `json:"country"`
+	PostalCode            string    `json:"postal_code"`
+	SpecialMealPreference string    `json:"special_meal_preference"`
+	SeatPreference        string    `json:"seat_preference"`
+	SpecialAssistance     bool      `json:"special_assistance"`
+	MarketingConsent      bool      `json:"marketing_consent"`
+	PreferredLanguage     string    `json:"preferred_language"`
+}
+
+func (c *PassengerController) RegisterPassenger(ctx *fasthttp.RequestCtx) {
+	var req registerPassengerRequest
+	if !decodeBody(ctx, &req) {
+		return
+	}
+
+	passenger := &models.Passenger{
+		FirstName:             req.FirstName,
+		LastName:              req.LastName,
+		Email:                 req.Email,
+		PhoneNumber:           req.PhoneNumber,
+		DateOfBirth:           req.DateOfBirth,
+		Nationality:           req.Nationality,
+		PassportNumber:        req.PassportNumber,
+		PassportExpiry:        req.PassportExpiry,
+		Address:               req.Address,
+		City:                  req.City,
+		Country:               req.Country,
+		PostalCode:            req.PostalCode,
+		SpecialMealPreference: req.SpecialMealPreference,
+		SeatPreference:        req.SeatPreference,
+		SpecialAssistance:     req.SpecialAssistance,
+		MarketingConsent:      req.MarketingConsent,
+		PreferredLanguage:     req.PreferredLanguage,
+	}
+	if err := c.service.RegisterPassenger(ctx, passenger); err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+
+	writeJSON(ctx, fasthttp.StatusCreated, passenger)
+}
+
+func (c *PassengerController) GetPassenger(ctx *fasthttp.RequestCtx) {
+	passengerID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	passenger, err := c.service.GetPassenger(ctx, passengerID)
+	if err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+
+	writeJSON(ctx, fasthttp.StatusOK, passenger)
+}
+
+func (c *PassengerController) UpdatePassenger(ctx *fasthttp.RequestCtx) {
+	passengerID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var update models.PassengerUpdate
+	if !decodeBody(ctx, &update) {
+		return
+	}
+
+	passenger, err := c.service.UpdatePassenger(ctx, passengerID, update)
+	if err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+
+	writeJSON(ctx, fasthttp.StatusOK, passenger)
+}
+
+func (c *PassengerController) SearchPassengers(ctx *fasthttp.RequestCtx) {
+	args := ctx.QueryArgs()
+	filter := models.PassengerFilter{
+		FirstName:           string(args.Peek("first_name")),
+		LastName:            string(args.Peek("last_name")),
+		Email:               string(args.Peek("email")),
+		FrequentFlyerNumber: string(args.Peek("frequent_flyer_number")),
+		Nationality:         string(args.Peek("nationality")),
+		FrequentFlyerTier:   string(args.Peek("frequent_flyer_tier")),
+		Cursor:              string(args.Peek("cursor")),
+	}
+
+	var ok bool
+	if filter.MinTotalFlights, ok = queryInt(ctx, "min_total_flights"); !ok {
+		return
+	}
+	if filter.PageSize, ok = queryInt(ctx, "page_size"); !ok {
+		return
+	}
+	if raw := args.Peek("min_total_spent"); len(raw) > 0 {
+		spent, err := strconv.ParseFloat(string(raw), 64)
+		if err != nil {
+			writeError(ctx, fasthttp.StatusBadRequest, "invalid min_total_spent")
+			return
+		}
+		filter.MinTotalSpent = spent
+	}
+	if raw := args.Peek("last_flight_after"); len(raw) > 0 {
+		after, err := time.Parse("2006-01-02", string(raw))
+		if err != nil {
+			writeError(ctx, fasthttp.StatusBadRequest, "invalid last_flight_after, expected YYYY-MM-DD")
+			return
+		}
+		filter.LastFlightAfter = after
+	}
+
+	page, err := c.service.SearchPassengers(ctx, filter)
+	if err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+	if page.Items == nil {
+		page.Items = []*models.Passenger{}
+	}
+
+	writeJSON(ctx, fasthttp.StatusOK, page)
+}
+
+func (c *PassengerController) GetPassengerHistory(ctx *fasthttp.RequestCtx) {
+	passengerID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	history, err := c.service.GetPassengerHistory(ctx, passengerID)
+	if err != nil {
+		writeServiceError(ctx, err)
+		return
+	}
+
+	writeJSON(ctx, fasthttp.StatusOK, history)
+}
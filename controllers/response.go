@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"airline-booking/logger"
+	"airline-booking/models"
+	"airline-booking/repositories"
+	"airline-booking/services"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// errorResponse 是所有 API 錯誤回應的統一格式
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(ctx *fasthttp.RequestCtx, status int, v interface{}) {
+	ctx.SetContentType("application/json")
+	ctx.SetStatusCode(status)
+	if err := json.NewEncoder(ctx).Encode(v); err != nil {
+		logger.Error("Failed to encode response", zap.Error(err))
+	}
+}
+
+func writeError(ctx *fasthttp.RequestCtx, status int, message string) {
+	writeJSON(ctx, status, errorResponse{Error: message})
+}
+
+// writeServiceError 將服務層與儲存庫層的錯誤映射為對應的 HTTP 狀態碼
+func writeServiceError(ctx *fasthttp.RequestCtx, err error) {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr),
+		errors.Is(err, repositories.ErrInvalidClass),
+		errors.Is(err, models.ErrInvalidCursor):
+		writeError(ctx, fasthttp.StatusBadRequest, err.Error())
+	case errors.Is(err, repositories.ErrNotFound), errors.Is(err, services.ErrSearchJobNotFound):
+		writeError(ctx, fasthttp.StatusNotFound, err.Error())
+	case errors.Is(err, repositories.ErrNoSeatsAvailable),
+		errors.Is(err, repositories.ErrAlreadyExists),
+		errors.Is(err, repositories.ErrSeatTaken),
+		errors.Is(err, services.ErrBookingAlreadyCancelled),
+		errors.Is(err, services.ErrBookingNotConfirmed),
+		errors.Is(err, services.ErrFareNotChangeable),
+		errors.Is(err, services.ErrBookingNotHeld),
+		errors.Is(err, services.ErrHoldExpired),
+		errors.Is(err, services.ErrInvalidPaymentTransition),
+		errors.Is(err, services.ErrPaymentNotCollected):
+		writeError(ctx, fasthttp.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrPaymentDeclined), errors.Is(err, services.ErrBookingNotPaid):
+		writeError(ctx, fasthttp.StatusPaymentRequired, err.Error())
+	case errors.Is(err, services.ErrPaymentGatewayTimeout):
+		// 網關結果未知，客戶端以同一個冪等鍵重試不會重複扣款
+		writeError(ctx, fasthttp.StatusGatewayTimeout, err.Error())
+	case errors.Is(err, services.ErrSearchQueueFull):
+		// 隊列已滿屬於暫時性過載，提示客戶端稍後重試
+		ctx.Response.Header.Set("Retry-After", "1")
+		writeError(ctx, fasthttp.StatusServiceUnavailable, err.Error())
+	default:
+		// 內部錯誤不把細節暴露給客戶端
+		logger.Error("Request failed",
+			zap.Error(err),
+			zap.String("method", string(ctx.Method())),
+			zap.String("path", string(ctx.Path())))
+		writeError(ctx, fasthttp.StatusInternalServerError, "internal server error")
+	}
+}
+
+// pathID 解析路徑參數中的正整數 ID，失敗時直接寫入 400 回應
+func pathID(ctx *fasthttp.RequestCtx, name string) (int, bool) {
+	raw, _ := ctx.UserValue(name).(string)
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		writeError(ctx, fasthttp.StatusBadRequest, "invalid "+name)
+		return 0, false
+	}
+	return id, true
+}
+
+// queryInt 解析可選的整數查詢參數，缺省時返回 0，格式錯誤時直接寫入 400 回應
+func queryInt(ctx *fasthttp.RequestCtx, name string) (int, bool) {
+	raw := ctx.QueryArgs().Peek(name)
+	if len(raw) == 0 {
+		return 0, true
+	}
+	v, err := strconv.Atoi(string(raw))
+	if err != nil {
+		writeError(ctx, fasthttp.StatusBadRequest, "invalid "+name)
+		return 0, false
+	}
+	return v, true
+}
+
+// decodeBody 解析 JSON 請求體，失敗時直接寫入 400 回應
+func decodeBody(ctx *fasthttp.RequestCtx, v interface{}) bool {
+	if err := json.Unmarshal(ctx.PostBody(), v); err != nil {
+		writeError(ctx, fasthttp.StatusBadRequest, "invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
@@ -3,8 +3,18 @@ module airline-booking
 go 1.22.3
 
 require (
+	github.com/fasthttp/router v1.5.2
 	github.com/go-redis/redis/v8 v8.11.5
+	github.com/go-redis/redismock/v8 v8.11.5
+	github.com/golang/mock v1.6.0
 	github.com/lib/pq v1.10.9
+	github.com/opentracing/opentracing-go v1.2.0
+	github.com/stretchr/testify v1.9.0
+	github.com/uber/jaeger-client-go v2.30.0+incompatible
+	github.com/valyala/fasthttp v1.55.0
+	go.uber.org/zap v1.27.0
+	golang.org/x/sync v0.10.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -12,21 +22,12 @@ require (
 	github.com/cespare/xxhash/v2 v2.1.2 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
-	github.com/fasthttp/router v1.5.2 // indirect
-	github.com/go-redis/redismock/v8 v8.11.5 // indirect
-	github.com/golang/mock v1.6.0 // indirect
 	github.com/klauspost/compress v1.17.9 // indirect
-	github.com/opentracing/opentracing-go v1.2.0 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/savsgio/gotils v0.0.0-20240704082632-aef3928b8a38 // indirect
-	github.com/stretchr/testify v1.9.0 // indirect
-	github.com/uber/jaeger-client-go v2.30.0+incompatible // indirect
 	github.com/uber/jaeger-lib v2.4.1+incompatible // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
-	github.com/valyala/fasthttp v1.55.0 // indirect
 	go.uber.org/atomic v1.11.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
-	go.uber.org/zap v1.27.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"context"
+	"io"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/uber/jaeger-client-go"
@@ -28,26 +29,30 @@ func Init() error {
 	return nil
 }
 
-func InitTracer(serviceName string) error {
+// InitTracer 初始化 Jaeger 並設為全局 tracer。
+// 返回的 Closer 在退出前必須關閉，否則緩衝中尚未上報的 span 會丟失。
+// samplerType 與 samplerParam 對應 Jaeger 的採樣器設置，例如 "probabilistic" 與 0.1。
+func InitTracer(serviceName, samplerType string, samplerParam float64) (io.Closer, error) {
 	cfg := jaegercfg.Configuration{
 		ServiceName: serviceName,
 		Sampler: &jaegercfg.SamplerConfig{
-			Type:  jaeger.SamplerTypeConst,
-			Param: 1,
+			Type:  samplerType,
+			Param: samplerParam,
 		},
 		Reporter: &jaegercfg.ReporterConfig{
 			LogSpans: true,
 		},
 	}
 
+	var closer io.Closer
 	var err error
-	tracer, _, err = cfg.NewTracer()
+	tracer, closer, err = cfg.NewTracer()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	opentracing.SetGlobalTracer(tracer)
-	return nil
+	return closer, nil
 }
 
 func GetLogger() *zap.Logger {
@@ -90,4 +95,5 @@ func LogWithTracing(ctx context.Context, msg string, fields ...zap.Field) {
 
 func SetLoggerForTest(l *zap.Logger) {
 	globalLogger = l
+	log = l
 }
@@ -1,9 +1,20 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	// 運行鏡像不含時區數據庫，按機場當地時間過濾航班需要內嵌的時區數據
+	_ "time/tzdata"
+
+	"airline-booking/app"
 	"airline-booking/config"
 	"airline-booking/controllers"
 	"airline-booking/logger"
+	"airline-booking/migrations"
+	"airline-booking/models"
 	"airline-booking/repositories"
 	"airline-booking/routes"
 	"airline-booking/services"
@@ -19,32 +30,102 @@ func main() {
 		panic(err)
 	}
 
-	if err := logger.InitTracer("airline-booking"); err != nil {
-		logger.Fatal("Failed to initialize tracer", zap.Error(err))
+	// 配置文件路徑可由 -config 參數或 CONFIG_FILE 環境變量指定，都沒有時只使用默認值與環境變量
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "path to the YAML config file")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		logger.Fatal("Failed to load configuration", zap.Error(err))
 	}
 
-	cfg := config.NewConfig()
+	tracerCloser, err := logger.InitTracer("airline-booking", cfg.TracingSamplerType, cfg.TracingSamplerParam)
+	if err != nil {
+		logger.Fatal("Failed to initialize tracer", zap.Error(err))
+	}
 
 	db, err := config.InitDB(cfg)
 	if err != nil {
 		logger.Fatal("Failed to initialize database", zap.Error(err))
 	}
-	defer db.Close()
+
+	// migrate 子命令只操作數據庫，執行完即退出
+	if flag.Arg(0) == "migrate" {
+		err := runMigrate(context.Background(), db, flag.Args()[1:])
+		db.Close()
+		tracerCloser.Close()
+		if err != nil {
+			logger.Error("Migration failed", zap.Error(err))
+			os.Exit(1)
+		}
+		return
+	}
+
+	if cfg.DBAutoMigrate {
+		migrator, err := migrations.New(db)
+		if err != nil {
+			logger.Fatal("Failed to load migrations", zap.Error(err))
+		}
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			logger.Fatal("Failed to migrate database", zap.Error(err))
+		}
+		logger.Info("Database schema is up to date", zap.Int64s("applied", applied))
+	}
 
 	redisClient, err := config.InitRedis(cfg)
 	if err != nil {
 		logger.Fatal("Failed to initialize Redis", zap.Error(err))
 	}
-	defer redisClient.Close()
 
+	txManager := repositories.NewTxManager(db)
 	flightRepo := repositories.NewFlightRepository(db)
-	flightService := services.NewFlightService(flightRepo, redisClient)
-	flightController := controllers.NewFlightController(flightService)
+	bookingRepo := repositories.NewBookingRepository(db)
+	passengerRepo := repositories.NewPassengerRepository(db)
+	seatRepo := repositories.NewSeatRepository(db)
+	holdRepo := repositories.NewHoldRepository(db)
+	paymentRepo := repositories.NewPaymentRepository(db)
+
+	notifyService := services.NewNotificationService()
+	pricingService := services.NewPricingService(flightRepo, cfg.FareBuckets)
+	seatService := services.NewSeatService(flightRepo, seatRepo)
+	// 沒有座位配置的機型無法選座，啟動時補齊常見機型的默認配置
+	if err := seatService.LoadSeatLayouts(context.Background(), models.StandardSeatLayouts); err != nil {
+		logger.Fatal("Failed to load seat layouts", zap.Error(err))
+	}
+	// 配置校驗保證 PaymentGateway 目前只能是 fake
+	paymentService := services.NewPaymentService(paymentRepo, services.NewFakePaymentGateway())
+	refundPolicy := services.NewRefundPolicy(flightRepo, passengerRepo, paymentService)
+	searchCache := services.NewSearchCache(redisClient, flightRepo, cfg.SearchCacheTTL, services.LRUOptions{
+		MaxEntries: cfg.LocalCacheMaxEntries,
+		MaxBytes:   int64(cfg.LocalCacheMaxMB) << 20,
+		TTL:        cfg.LocalCacheTTL,
+	})
+	overbookingService := services.NewOverbookingService(txManager, flightRepo, bookingRepo, notifyService, searchCache)
+	var searchQueue services.SearchQueue
+	if cfg.SearchQueueBackend == "memory" {
+		searchQueue = services.NewMemorySearchQueue(cfg.SearchQueueSize, services.LRUOptions{
+			MaxEntries: cfg.SearchJobMaxEntries,
+			MaxBytes:   int64(cfg.SearchJobMaxMB) << 20,
+			TTL:        cfg.SearchJobTTL,
+		})
+	} else {
+		searchQueue = services.NewRedisSearchQueue(redisClient, cfg.SearchQueueSize, cfg.SearchJobTTL, cfg.SearchJobTimeout)
+	}
+	flightService := services.NewFlightService(flightRepo, searchCache, searchQueue, pricingService)
+	bookingService := services.NewBookingService(txManager, bookingRepo, flightRepo, passengerRepo, holdRepo,
+		overbookingService, notifyService, searchCache, pricingService, seatService, paymentService, refundPolicy, cfg.BookingHoldTTL)
+	passengerService := services.NewPassengerService(passengerRepo, bookingRepo)
+
+	searchPool := services.NewSearchWorkerPool(flightService, cfg.SearchWorkers, cfg.SearchJobTimeout)
+	holdReaper := services.NewHoldReaper(bookingService, cfg.HoldReapInterval)
 
-	go flightService.ProcessSearchRequests()
+	flightController := controllers.NewFlightController(flightService, seatService, searchPool, searchCache)
+	bookingController := controllers.NewBookingController(bookingService, paymentService)
+	passengerController := controllers.NewPassengerController(passengerService)
 
 	r := router.New()
-	routes.SetupRoutes(r, flightController)
+	routes.SetupRoutes(r, flightController, bookingController, passengerController)
 
 	handler := func(ctx *fasthttp.RequestCtx) {
 		span, traceCtx := opentracing.StartSpanFromContext(ctx, "http_handler")
@@ -58,8 +139,25 @@ func main() {
 			zap.Int("status", ctx.Response.StatusCode()))
 	}
 
-	logger.Info("Server is running", zap.String("port", cfg.ServerPort))
-	if err := fasthttp.ListenAndServe(":"+cfg.ServerPort, handler); err != nil {
-		logger.Fatal("Server stopped", zap.Error(err))
+	application := app.New(app.Options{
+		Addr:            ":" + cfg.ServerPort,
+		Handler:         handler,
+		SearchPool:      searchPool,
+		HoldReaper:      holdReaper,
+		TracerCloser:    tracerCloser,
+		DB:              db,
+		Redis:           redisClient,
+		ShutdownTimeout: cfg.ShutdownTimeout,
+	})
+
+	// SIGINT/SIGTERM 觸發優雅關閉：停止接收連接、排空搜索任務並釋放資源
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := application.Run(ctx); err != nil {
+		logger.Error("Application exited with error", zap.Error(err))
+		logger.GetLogger().Sync()
+		os.Exit(1)
 	}
+	logger.GetLogger().Sync()
 }
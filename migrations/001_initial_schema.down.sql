@@ -0,0 +1,6 @@
+-- 按依賴的相反順序刪除 001 創建的表，索引隨表一併刪除
+DROP TABLE IF EXISTS historical_data;
+DROP TABLE IF EXISTS bookings;
+DROP TABLE IF EXISTS flights;
+DROP TABLE IF EXISTS airports;
+DROP TABLE IF EXISTS passengers;
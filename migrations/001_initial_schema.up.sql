@@ -28,24 +28,48 @@ CREATE TABLE passengers (
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- 創建 airports 表
+-- timezone 使用 IANA 時區名稱（如 Asia/Taipei），用於按出發地當地日期搜索航班
+CREATE TABLE airports (
+    code CHAR(3) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    city VARCHAR(100) NOT NULL,
+    country VARCHAR(100) NOT NULL,
+    timezone VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
 -- 創建 flights 表
 CREATE TABLE flights (
     id SERIAL PRIMARY KEY,
-    origin VARCHAR(50) NOT NULL,
-    destination VARCHAR(50) NOT NULL,
+    flight_number VARCHAR(10) NOT NULL,
+    carrier CHAR(2) NOT NULL,
+    aircraft_type VARCHAR(20),
+    origin CHAR(3) NOT NULL REFERENCES airports(code),
+    destination CHAR(3) NOT NULL REFERENCES airports(code),
     departure_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    arrival_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled',
     price DECIMAL(10, 2) NOT NULL,
     economy_seats_total INTEGER NOT NULL,
-    economy_seats_booked INTEGER DEFAULT 0,
-    economy_seats_overbooking_ratio DECIMAL(3, 2) DEFAULT 0,
+    economy_seats_booked INTEGER NOT NULL DEFAULT 0,
+    economy_seats_overbooking_ratio DECIMAL(3, 2) NOT NULL DEFAULT 0,
     business_seats_total INTEGER NOT NULL,
-    business_seats_booked INTEGER DEFAULT 0,
-    business_seats_overbooking_ratio DECIMAL(3, 2) DEFAULT 0,
+    business_seats_booked INTEGER NOT NULL DEFAULT 0,
+    business_seats_overbooking_ratio DECIMAL(3, 2) NOT NULL DEFAULT 0,
     first_class_seats_total INTEGER NOT NULL,
-    first_class_seats_booked INTEGER DEFAULT 0,
-    first_class_seats_overbooking_ratio DECIMAL(3, 2) DEFAULT 0,
+    first_class_seats_booked INTEGER NOT NULL DEFAULT 0,
+    first_class_seats_overbooking_ratio DECIMAL(3, 2) NOT NULL DEFAULT 0,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT flights_route_check CHECK (origin <> destination),
+    CONSTRAINT flights_schedule_check CHECK (arrival_time > departure_time),
+    CONSTRAINT flights_status_check CHECK (status IN ('scheduled', 'delayed', 'boarding', 'departed', 'arrived', 'cancelled')),
+    CONSTRAINT flights_seats_check CHECK (
+        economy_seats_booked >= 0 AND business_seats_booked >= 0 AND first_class_seats_booked >= 0
+    ),
+    -- 同一航班號在同一出發時間只能有一個航班
+    CONSTRAINT flights_number_departure_key UNIQUE (carrier, flight_number, departure_time)
 );
 
 -- 創建 bookings 表
@@ -100,7 +124,7 @@ CREATE INDEX idx_passengers_email ON passengers(email);
 CREATE INDEX idx_passengers_frequent_flyer_number ON passengers(frequent_flyer_number);
 CREATE INDEX idx_passengers_last_name ON passengers(last_name);
 
-CREATE INDEX idx_flights_origin_destination ON flights(origin, destination);
+CREATE INDEX idx_flights_route_departure ON flights(origin, destination, departure_time);
 CREATE INDEX idx_flights_departure_time ON flights(departure_time);
 
 CREATE INDEX idx_bookings_passenger_id ON bookings(passenger_id);
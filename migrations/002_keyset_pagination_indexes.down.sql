@@ -0,0 +1,6 @@
+CREATE INDEX IF NOT EXISTS idx_bookings_booking_time ON bookings(booking_time);
+DROP INDEX IF EXISTS idx_bookings_booking_time_id;
+DROP INDEX IF EXISTS idx_bookings_passenger_booking_time;
+
+CREATE INDEX IF NOT EXISTS idx_passengers_last_name ON passengers(last_name);
+DROP INDEX IF EXISTS idx_passengers_name_id;
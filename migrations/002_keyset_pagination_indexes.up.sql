@@ -0,0 +1,7 @@
+-- 鍵集分頁的索引：與列表查詢的 ORDER BY 一致，翻頁時可以直接從游標位置開始掃描
+CREATE INDEX idx_passengers_name_id ON passengers(last_name, first_name, id);
+DROP INDEX IF EXISTS idx_passengers_last_name;
+
+CREATE INDEX idx_bookings_passenger_booking_time ON bookings(passenger_id, booking_time DESC, id DESC);
+CREATE INDEX idx_bookings_booking_time_id ON bookings(booking_time DESC, id DESC);
+DROP INDEX IF EXISTS idx_bookings_booking_time;
@@ -0,0 +1,2 @@
+-- 預訂記錄售出時的票價等級，舊預訂沒有票價等級
+ALTER TABLE bookings ADD COLUMN fare_bucket VARCHAR(10);
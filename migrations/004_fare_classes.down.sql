@@ -0,0 +1,2 @@
+ALTER TABLE bookings RENAME COLUMN fare_class TO fare_bucket;
+DROP TABLE IF EXISTS fare_classes;
@@ -0,0 +1,21 @@
+-- 票價艙等（RBD）：每個艙位下的訂位代碼，各自有座位配額、票價與退改規則。
+-- 配額之和可以超過艙位的座位數，實際座位仍由 flights 的艙位庫存約束
+CREATE TABLE fare_classes (
+    id SERIAL PRIMARY KEY,
+    flight_id INTEGER NOT NULL REFERENCES flights(id) ON DELETE CASCADE,
+    cabin VARCHAR(20) NOT NULL CHECK (cabin IN ('economy', 'business', 'first')),
+    code VARCHAR(2) NOT NULL,
+    price DECIMAL(10, 2) NOT NULL CHECK (price > 0),
+    seats_total INTEGER NOT NULL CHECK (seats_total >= 0),
+    seats_booked INTEGER NOT NULL DEFAULT 0 CHECK (seats_booked >= 0 AND seats_booked <= seats_total),
+    refundable BOOLEAN NOT NULL DEFAULT FALSE,
+    refund_fee DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    changeable BOOLEAN NOT NULL DEFAULT FALSE,
+    change_fee DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    UNIQUE (flight_id, code)
+);
+
+CREATE INDEX idx_fare_classes_flight_cabin_price ON fare_classes(flight_id, cabin, price);
+
+-- 票價等級與票價艙等合併為一個欄位：沒有定義票價艙等的航班記錄按載客率選出的等級代碼
+ALTER TABLE bookings RENAME COLUMN fare_bucket TO fare_class;
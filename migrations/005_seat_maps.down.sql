@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_bookings_flight_seat;
+DROP TABLE IF EXISTS aircraft_seats;
@@ -0,0 +1,30 @@
+-- 機型的座位配置：每個座位的排號、字母、艙位與屬性
+CREATE TABLE aircraft_seats (
+    id SERIAL PRIMARY KEY,
+    aircraft_type VARCHAR(20) NOT NULL,
+    seat_number VARCHAR(4) NOT NULL,
+    seat_row INTEGER NOT NULL CHECK (seat_row > 0),
+    seat_letter CHAR(1) NOT NULL,
+    cabin VARCHAR(20) NOT NULL CHECK (cabin IN ('economy', 'business', 'first')),
+    is_window BOOLEAN NOT NULL DEFAULT FALSE,
+    is_aisle BOOLEAN NOT NULL DEFAULT FALSE,
+    extra_legroom BOOLEAN NOT NULL DEFAULT FALSE,
+    UNIQUE (aircraft_type, seat_number)
+);
+
+-- 之前的座位號是自由文本，同一航班可能有多筆有效預訂佔用同一座位：保留最早的預訂，其餘清空待重新選座
+UPDATE bookings b
+SET seat_number = NULL
+WHERE b.seat_number IS NOT NULL
+  AND b.status <> 'cancelled'
+  AND EXISTS (
+      SELECT 1 FROM bookings e
+      WHERE e.flight_id = b.flight_id
+        AND e.seat_number = b.seat_number
+        AND e.status <> 'cancelled'
+        AND (e.booking_time, e.id) < (b.booking_time, b.id)
+  );
+
+-- 同一航班的一個座位只能屬於一筆未取消的預訂
+CREATE UNIQUE INDEX idx_bookings_flight_seat ON bookings(flight_id, seat_number)
+    WHERE seat_number IS NOT NULL AND status <> 'cancelled';
@@ -0,0 +1,8 @@
+-- 舊版本不認識 'expired'，把過期的保留當作取消，否則重建的座位索引可能衝突
+UPDATE bookings SET status = 'cancelled' WHERE status = 'expired';
+
+DROP INDEX IF EXISTS idx_bookings_flight_seat;
+CREATE UNIQUE INDEX idx_bookings_flight_seat ON bookings(flight_id, seat_number)
+    WHERE seat_number IS NOT NULL AND status <> 'cancelled';
+
+DROP TABLE IF EXISTS booking_holds;
@@ -0,0 +1,13 @@
+-- 待支付的預訂（status = 'held'）在保留到期前佔用座位與艙等配額，到期後由後台任務釋放並標記為 'expired'
+CREATE TABLE booking_holds (
+    booking_id INTEGER PRIMARY KEY REFERENCES bookings(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_booking_holds_expires_at ON booking_holds(expires_at);
+
+-- 過期的保留與取消一樣不再佔用座位
+DROP INDEX idx_bookings_flight_seat;
+CREATE UNIQUE INDEX idx_bookings_flight_seat ON bookings(flight_id, seat_number)
+    WHERE seat_number IS NOT NULL AND status NOT IN ('cancelled', 'expired');
@@ -0,0 +1,21 @@
+-- 預訂的付款記錄。idempotency_key 由客戶端生成，同一個鍵的重試對應同一筆付款；
+-- 被拒或撤銷的付款可以換卡重試，其餘狀態的付款每筆預訂最多一筆
+CREATE TABLE payments (
+    id SERIAL PRIMARY KEY,
+    booking_id INTEGER NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    idempotency_key VARCHAR(255) NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL CHECK (status IN (
+        'pending', 'authorized', 'captured', 'partially_refunded', 'refunded', 'voided', 'declined'
+    )),
+    amount DECIMAL(10, 2) NOT NULL CHECK (amount >= 0),
+    currency VARCHAR(3) NOT NULL,
+    refunded_amount DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (refunded_amount >= 0 AND refunded_amount <= amount),
+    gateway_reference VARCHAR(100),
+    failure_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_payments_booking_active ON payments(booking_id)
+    WHERE status NOT IN ('declined', 'voided');
+CREATE INDEX idx_payments_booking_id ON payments(booking_id, created_at);
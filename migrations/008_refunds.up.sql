@@ -0,0 +1,15 @@
+-- 付款的退款記錄。退款先在導致退款的事務中記錄為 pending，提交後再以 idempotency_key 請求網關，
+-- 成功後變為 completed；事務或網關請求的重試都對應同一筆退款，不會重複退款
+CREATE TABLE refunds (
+    id SERIAL PRIMARY KEY,
+    payment_id INTEGER NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+    booking_id INTEGER NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    idempotency_key VARCHAR(255) NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'completed')),
+    amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_refunds_booking_id ON refunds(booking_id, created_at);
@@ -0,0 +1,70 @@
+package migrations_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"airline-booking/migrations"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad_SortsAndPairsFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"010_add_index.up.sql":        {Data: []byte("CREATE INDEX a ON t(x);")},
+		"002_add_column.up.sql":       {Data: []byte("ALTER TABLE t ADD COLUMN x INT;")},
+		"002_add_column.down.sql":     {Data: []byte("ALTER TABLE t DROP COLUMN x;")},
+		"001_initial_schema.up.sql":   {Data: []byte("CREATE TABLE t ();")},
+		"001_initial_schema.down.sql": {Data: []byte("DROP TABLE t;")},
+	}
+
+	loaded, err := migrations.Load(fsys)
+	assert.NoError(t, err)
+	assert.Len(t, loaded, 3)
+
+	assert.Equal(t, []int64{1, 2, 10}, []int64{loaded[0].Version, loaded[1].Version, loaded[2].Version})
+	assert.Equal(t, "add_column", loaded[1].Name)
+	assert.Equal(t, "ALTER TABLE t DROP COLUMN x;", loaded[1].Down)
+	// 沒有 down 文件的遷移可以執行但不能回滾
+	assert.Empty(t, loaded[2].Down)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		wantErr string
+	}{
+		{
+			name:    "bad file name",
+			fsys:    fstest.MapFS{"initial.sql": {Data: []byte("")}},
+			wantErr: "file name must look like",
+		},
+		{
+			name:    "down without up",
+			fsys:    fstest.MapFS{"003_orphan.down.sql": {Data: []byte("")}},
+			wantErr: "missing up file",
+		},
+		{
+			name: "conflicting names",
+			fsys: fstest.MapFS{
+				"004_one.up.sql": {Data: []byte("SELECT 1;")},
+				"004_two.up.sql": {Data: []byte("SELECT 2;")},
+			},
+			wantErr: "conflicting names",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := migrations.Load(tt.fsys)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestNew_EmbeddedMigrations(t *testing.T) {
+	// 內嵌的遷移文件必須能被解析，否則應用在啟動時才會失敗
+	_, err := migrations.New(nil)
+	assert.NoError(t, err)
+}
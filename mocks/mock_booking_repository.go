@@ -0,0 +1,183 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repositories/booking_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "airline-booking/models"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockBookingRepository is a mock of BookingRepository interface.
+type MockBookingRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockBookingRepositoryMockRecorder
+}
+
+// MockBookingRepositoryMockRecorder is the mock recorder for MockBookingRepository.
+type MockBookingRepositoryMockRecorder struct {
+	mock *MockBookingRepository
+}
+
+// NewMockBookingRepository creates a new mock instance.
+func NewMockBookingRepository(ctrl *gomock.Controller) *MockBookingRepository {
+	mock := &MockBookingRepository{ctrl: ctrl}
+	mock.recorder = &MockBookingRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBookingRepository) EXPECT() *MockBookingRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateBooking mocks base method.
+func (m *MockBookingRepository) CreateBooking(ctx context.Context, booking *models.Booking) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBooking", ctx, booking)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBooking indicates an expected call of CreateBooking.
+func (mr *MockBookingRepositoryMockRecorder) CreateBooking(ctx, booking interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBooking", reflect.TypeOf((*MockBookingRepository)(nil).CreateBooking), ctx, booking)
+}
+
+// DeleteBooking mocks base method.
+func (m *MockBookingRepository) DeleteBooking(ctx context.Context, bookingID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBooking", ctx, bookingID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBooking indicates an expected call of DeleteBooking.
+func (mr *MockBookingRepositoryMockRecorder) DeleteBooking(ctx, bookingID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBooking", reflect.TypeOf((*MockBookingRepository)(nil).DeleteBooking), ctx, bookingID)
+}
+
+// GetBookingByID mocks base method.
+func (m *MockBookingRepository) GetBookingByID(ctx context.Context, bookingID int) (*models.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBookingByID", ctx, bookingID)
+	ret0, _ := ret[0].(*models.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBookingByID indicates an expected call of GetBookingByID.
+func (mr *MockBookingRepositoryMockRecorder) GetBookingByID(ctx, bookingID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBookingByID", reflect.TypeOf((*MockBookingRepository)(nil).GetBookingByID), ctx, bookingID)
+}
+
+// GetBookingByIDForUpdate mocks base method.
+func (m *MockBookingRepository) GetBookingByIDForUpdate(ctx context.Context, bookingID int) (*models.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBookingByIDForUpdate", ctx, bookingID)
+	ret0, _ := ret[0].(*models.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBookingByIDForUpdate indicates an expected call of GetBookingByIDForUpdate.
+func (mr *MockBookingRepositoryMockRecorder) GetBookingByIDForUpdate(ctx, bookingID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBookingByIDForUpdate", reflect.TypeOf((*MockBookingRepository)(nil).GetBookingByIDForUpdate), ctx, bookingID)
+}
+
+// GetBookingsByFlight mocks base method.
+func (m *MockBookingRepository) GetBookingsByFlight(ctx context.Context, flightID int) ([]*models.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBookingsByFlight", ctx, flightID)
+	ret0, _ := ret[0].([]*models.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBookingsByFlight indicates an expected call of GetBookingsByFlight.
+func (mr *MockBookingRepositoryMockRecorder) GetBookingsByFlight(ctx, flightID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBookingsByFlight", reflect.TypeOf((*MockBookingRepository)(nil).GetBookingsByFlight), ctx, flightID)
+}
+
+// GetBookingsByPassengerID mocks base method.
+func (m *MockBookingRepository) GetBookingsByPassengerID(ctx context.Context, passengerID int) ([]*models.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBookingsByPassengerID", ctx, passengerID)
+	ret0, _ := ret[0].([]*models.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBookingsByPassengerID indicates an expected call of GetBookingsByPassengerID.
+func (mr *MockBookingRepositoryMockRecorder) GetBookingsByPassengerID(ctx, passengerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBookingsByPassengerID", reflect.TypeOf((*MockBookingRepository)(nil).GetBookingsByPassengerID), ctx, passengerID)
+}
+
+// GetCurrentBookingTrend mocks base method.
+func (m *MockBookingRepository) GetCurrentBookingTrend(ctx context.Context, flightID int) (models.BookingTrend, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrentBookingTrend", ctx, flightID)
+	ret0, _ := ret[0].(models.BookingTrend)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurrentBookingTrend indicates an expected call of GetCurrentBookingTrend.
+func (mr *MockBookingRepositoryMockRecorder) GetCurrentBookingTrend(ctx, flightID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentBookingTrend", reflect.TypeOf((*MockBookingRepository)(nil).GetCurrentBookingTrend), ctx, flightID)
+}
+
+// GetPassengerHistory mocks base method.
+func (m *MockBookingRepository) GetPassengerHistory(ctx context.Context, passengerID int) (*models.PassengerHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPassengerHistory", ctx, passengerID)
+	ret0, _ := ret[0].(*models.PassengerHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPassengerHistory indicates an expected call of GetPassengerHistory.
+func (mr *MockBookingRepositoryMockRecorder) GetPassengerHistory(ctx, passengerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPassengerHistory", reflect.TypeOf((*MockBookingRepository)(nil).GetPassengerHistory), ctx, passengerID)
+}
+
+// ListBookings mocks base method.
+func (m *MockBookingRepository) ListBookings(ctx context.Context, filter models.BookingFilter) (*models.BookingPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBookings", ctx, filter)
+	ret0, _ := ret[0].(*models.BookingPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBookings indicates an expected call of ListBookings.
+func (mr *MockBookingRepositoryMockRecorder) ListBookings(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBookings", reflect.TypeOf((*MockBookingRepository)(nil).ListBookings), ctx, filter)
+}
+
+// UpdateBooking mocks base method.
+func (m *MockBookingRepository) UpdateBooking(ctx context.Context, booking *models.Booking) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBooking", ctx, booking)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBooking indicates an expected call of UpdateBooking.
+func (mr *MockBookingRepositoryMockRecorder) UpdateBooking(ctx, booking interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBooking", reflect.TypeOf((*MockBookingRepository)(nil).UpdateBooking), ctx, booking)
+}
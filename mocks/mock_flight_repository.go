@@ -36,6 +36,49 @@ func (m *MockFlightRepository) EXPECT() *MockFlightRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AdjustBookedSeats mocks base method.
+func (m *MockFlightRepository) AdjustBookedSeats(ctx context.Context, flightID int, class string, delta int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustBookedSeats", ctx, flightID, class, delta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdjustBookedSeats indicates an expected call of AdjustBookedSeats.
+func (mr *MockFlightRepositoryMockRecorder) AdjustBookedSeats(ctx, flightID, class, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustBookedSeats", reflect.TypeOf((*MockFlightRepository)(nil).AdjustBookedSeats), ctx, flightID, class, delta)
+}
+
+// AdjustFareClassSeats mocks base method.
+func (m *MockFlightRepository) AdjustFareClassSeats(ctx context.Context, flightID int, code string, delta int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustFareClassSeats", ctx, flightID, code, delta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdjustFareClassSeats indicates an expected call of AdjustFareClassSeats.
+func (mr *MockFlightRepositoryMockRecorder) AdjustFareClassSeats(ctx, flightID, code, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustFareClassSeats", reflect.TypeOf((*MockFlightRepository)(nil).AdjustFareClassSeats), ctx, flightID, code, delta)
+}
+
+// GetFareClasses mocks base method.
+func (m *MockFlightRepository) GetFareClasses(ctx context.Context, flightIDs []int) (map[int][]models.FareClass, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFareClasses", ctx, flightIDs)
+	ret0, _ := ret[0].(map[int][]models.FareClass)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFareClasses indicates an expected call of GetFareClasses.
+func (mr *MockFlightRepositoryMockRecorder) GetFareClasses(ctx, flightIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFareClasses", reflect.TypeOf((*MockFlightRepository)(nil).GetFareClasses), ctx, flightIDs)
+}
+
 // GetFlightByID mocks base method.
 func (m *MockFlightRepository) GetFlightByID(ctx context.Context, flightID int) (*models.Flight, error) {
 	m.ctrl.T.Helper()
@@ -51,6 +94,36 @@ func (mr *MockFlightRepositoryMockRecorder) GetFlightByID(ctx, flightID interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFlightByID", reflect.TypeOf((*MockFlightRepository)(nil).GetFlightByID), ctx, flightID)
 }
 
+// GetFlightByIDForUpdate mocks base method.
+func (m *MockFlightRepository) GetFlightByIDForUpdate(ctx context.Context, flightID int) (*models.Flight, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFlightByIDForUpdate", ctx, flightID)
+	ret0, _ := ret[0].(*models.Flight)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFlightByIDForUpdate indicates an expected call of GetFlightByIDForUpdate.
+func (mr *MockFlightRepositoryMockRecorder) GetFlightByIDForUpdate(ctx, flightID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFlightByIDForUpdate", reflect.TypeOf((*MockFlightRepository)(nil).GetFlightByIDForUpdate), ctx, flightID)
+}
+
+// GetFlightsByDateRange mocks base method.
+func (m *MockFlightRepository) GetFlightsByDateRange(ctx context.Context, origin, destination string, from, to time.Time) ([]models.Flight, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFlightsByDateRange", ctx, origin, destination, from, to)
+	ret0, _ := ret[0].([]models.Flight)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFlightsByDateRange indicates an expected call of GetFlightsByDateRange.
+func (mr *MockFlightRepositoryMockRecorder) GetFlightsByDateRange(ctx, origin, destination, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFlightsByDateRange", reflect.TypeOf((*MockFlightRepository)(nil).GetFlightsByDateRange), ctx, origin, destination, from, to)
+}
+
 // GetHistoricalNoShowRate mocks base method.
 func (m *MockFlightRepository) GetHistoricalNoShowRate(ctx context.Context, route string, dayOfWeek time.Weekday) (models.HistoricalData, error) {
 	m.ctrl.T.Helper()
@@ -67,10 +140,10 @@ func (mr *MockFlightRepositoryMockRecorder) GetHistoricalNoShowRate(ctx, route,
 }
 
 // SearchFlights mocks base method.
-func (m *MockFlightRepository) SearchFlights(ctx context.Context, req models.SearchRequest) ([]models.Flight, error) {
+func (m *MockFlightRepository) SearchFlights(ctx context.Context, req models.SearchRequest) (*models.FlightPage, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SearchFlights", ctx, req)
-	ret0, _ := ret[0].([]models.Flight)
+	ret0, _ := ret[0].(*models.FlightPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -81,6 +154,21 @@ func (mr *MockFlightRepositoryMockRecorder) SearchFlights(ctx, req interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchFlights", reflect.TypeOf((*MockFlightRepository)(nil).SearchFlights), ctx, req)
 }
 
+// SearchItineraries mocks base method.
+func (m *MockFlightRepository) SearchItineraries(ctx context.Context, req models.ItinerarySearchRequest) ([][]models.Flight, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchItineraries", ctx, req)
+	ret0, _ := ret[0].([][]models.Flight)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchItineraries indicates an expected call of SearchItineraries.
+func (mr *MockFlightRepositoryMockRecorder) SearchItineraries(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchItineraries", reflect.TypeOf((*MockFlightRepository)(nil).SearchItineraries), ctx, req)
+}
+
 // UpdateFlight mocks base method.
 func (m *MockFlightRepository) UpdateFlight(ctx context.Context, flight *models.Flight) error {
 	m.ctrl.T.Helper()
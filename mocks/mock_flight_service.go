@@ -0,0 +1,153 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/flight_service.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "airline-booking/models"
+	services "airline-booking/services"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockFlightService is a mock of FlightService interface.
+type MockFlightService struct {
+	ctrl     *gomock.Controller
+	recorder *MockFlightServiceMockRecorder
+}
+
+// MockFlightServiceMockRecorder is the mock recorder for MockFlightService.
+type MockFlightServiceMockRecorder struct {
+	mock *MockFlightService
+}
+
+// NewMockFlightService creates a new mock instance.
+func NewMockFlightService(ctrl *gomock.Controller) *MockFlightService {
+	mock := &MockFlightService{ctrl: ctrl}
+	mock.recorder = &MockFlightServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFlightService) EXPECT() *MockFlightServiceMockRecorder {
+	return m.recorder
+}
+
+// GetFareCalendar mocks base method.
+func (m *MockFlightService) GetFareCalendar(ctx context.Context, req models.FareCalendarRequest) (*models.FareCalendar, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFareCalendar", ctx, req)
+	ret0, _ := ret[0].(*models.FareCalendar)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFareCalendar indicates an expected call of GetFareCalendar.
+func (mr *MockFlightServiceMockRecorder) GetFareCalendar(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFareCalendar", reflect.TypeOf((*MockFlightService)(nil).GetFareCalendar), ctx, req)
+}
+
+// GetFareClasses mocks base method.
+func (m *MockFlightService) GetFareClasses(ctx context.Context, flightID int) ([]models.FareClass, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFareClasses", ctx, flightID)
+	ret0, _ := ret[0].([]models.FareClass)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFareClasses indicates an expected call of GetFareClasses.
+func (mr *MockFlightServiceMockRecorder) GetFareClasses(ctx, flightID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFareClasses", reflect.TypeOf((*MockFlightService)(nil).GetFareClasses), ctx, flightID)
+}
+
+// GetSearchJob mocks base method.
+func (m *MockFlightService) GetSearchJob(ctx context.Context, requestID string) (*models.SearchJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSearchJob", ctx, requestID)
+	ret0, _ := ret[0].(*models.SearchJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSearchJob indicates an expected call of GetSearchJob.
+func (mr *MockFlightServiceMockRecorder) GetSearchJob(ctx, requestID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSearchJob", reflect.TypeOf((*MockFlightService)(nil).GetSearchJob), ctx, requestID)
+}
+
+// GetSearchQueue mocks base method.
+func (m *MockFlightService) GetSearchQueue() services.SearchQueue {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSearchQueue")
+	ret0, _ := ret[0].(services.SearchQueue)
+	return ret0
+}
+
+// GetSearchQueue indicates an expected call of GetSearchQueue.
+func (mr *MockFlightServiceMockRecorder) GetSearchQueue() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSearchQueue", reflect.TypeOf((*MockFlightService)(nil).GetSearchQueue))
+}
+
+// ProcessSearchJob mocks base method.
+func (m *MockFlightService) ProcessSearchJob(ctx context.Context, job *models.SearchJob) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ProcessSearchJob", ctx, job)
+}
+
+// ProcessSearchJob indicates an expected call of ProcessSearchJob.
+func (mr *MockFlightServiceMockRecorder) ProcessSearchJob(ctx, job interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessSearchJob", reflect.TypeOf((*MockFlightService)(nil).ProcessSearchJob), ctx, job)
+}
+
+// SearchFlights mocks base method.
+func (m *MockFlightService) SearchFlights(ctx context.Context, req models.SearchRequest) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchFlights", ctx, req)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchFlights indicates an expected call of SearchFlights.
+func (mr *MockFlightServiceMockRecorder) SearchFlights(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchFlights", reflect.TypeOf((*MockFlightService)(nil).SearchFlights), ctx, req)
+}
+
+// SearchItineraries mocks base method.
+func (m *MockFlightService) SearchItineraries(ctx context.Context, req models.ItinerarySearchRequest) ([]models.Itinerary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchItineraries", ctx, req)
+	ret0, _ := ret[0].([]models.Itinerary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchItineraries indicates an expected call of SearchItineraries.
+func (mr *MockFlightServiceMockRecorder) SearchItineraries(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchItineraries", reflect.TypeOf((*MockFlightService)(nil).SearchItineraries), ctx, req)
+}
+
+// WaitForSearchJob mocks base method.
+func (m *MockFlightService) WaitForSearchJob(ctx context.Context, requestID string) (*models.SearchJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForSearchJob", ctx, requestID)
+	ret0, _ := ret[0].(*models.SearchJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WaitForSearchJob indicates an expected call of WaitForSearchJob.
+func (mr *MockFlightServiceMockRecorder) WaitForSearchJob(ctx, requestID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForSearchJob", reflect.TypeOf((*MockFlightService)(nil).WaitForSearchJob), ctx, requestID)
+}
@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repositories/hold_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockHoldRepository is a mock of HoldRepository interface.
+type MockHoldRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHoldRepositoryMockRecorder
+}
+
+// MockHoldRepositoryMockRecorder is the mock recorder for MockHoldRepository.
+type MockHoldRepositoryMockRecorder struct {
+	mock *MockHoldRepository
+}
+
+// NewMockHoldRepository creates a new mock instance.
+func NewMockHoldRepository(ctrl *gomock.Controller) *MockHoldRepository {
+	mock := &MockHoldRepository{ctrl: ctrl}
+	mock.recorder = &MockHoldRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHoldRepository) EXPECT() *MockHoldRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateHold mocks base method.
+func (m *MockHoldRepository) CreateHold(ctx context.Context, bookingID int, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHold", ctx, bookingID, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockHoldRepositoryMockRecorder) CreateHold(ctx, bookingID, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockHoldRepository)(nil).CreateHold), ctx, bookingID, expiresAt)
+}
+
+// DeleteHold mocks base method.
+func (m *MockHoldRepository) DeleteHold(ctx context.Context, bookingID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteHold", ctx, bookingID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteHold indicates an expected call of DeleteHold.
+func (mr *MockHoldRepositoryMockRecorder) DeleteHold(ctx, bookingID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHold", reflect.TypeOf((*MockHoldRepository)(nil).DeleteHold), ctx, bookingID)
+}
+
+// GetHold mocks base method.
+func (m *MockHoldRepository) GetHold(ctx context.Context, bookingID int) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHold", ctx, bookingID)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHold indicates an expected call of GetHold.
+func (mr *MockHoldRepositoryMockRecorder) GetHold(ctx, bookingID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHold", reflect.TypeOf((*MockHoldRepository)(nil).GetHold), ctx, bookingID)
+}
+
+// ListExpiredHolds mocks base method.
+func (m *MockHoldRepository) ListExpiredHolds(ctx context.Context, before time.Time, limit int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpiredHolds", ctx, before, limit)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpiredHolds indicates an expected call of ListExpiredHolds.
+func (mr *MockHoldRepositoryMockRecorder) ListExpiredHolds(ctx, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiredHolds", reflect.TypeOf((*MockHoldRepository)(nil).ListExpiredHolds), ctx, before, limit)
+}
@@ -0,0 +1,120 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/notification_service.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "airline-booking/models"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockNotificationService is a mock of NotificationService interface.
+type MockNotificationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationServiceMockRecorder
+}
+
+// MockNotificationServiceMockRecorder is the mock recorder for MockNotificationService.
+type MockNotificationServiceMockRecorder struct {
+	mock *MockNotificationService
+}
+
+// NewMockNotificationService creates a new mock instance.
+func NewMockNotificationService(ctrl *gomock.Controller) *MockNotificationService {
+	mock := &MockNotificationService{ctrl: ctrl}
+	mock.recorder = &MockNotificationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationService) EXPECT() *MockNotificationServiceMockRecorder {
+	return m.recorder
+}
+
+// NotifyPassenger mocks base method.
+func (m *MockNotificationService) NotifyPassenger(ctx context.Context, booking *models.Booking, message string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyPassenger", ctx, booking, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyPassenger indicates an expected call of NotifyPassenger.
+func (mr *MockNotificationServiceMockRecorder) NotifyPassenger(ctx, booking, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyPassenger", reflect.TypeOf((*MockNotificationService)(nil).NotifyPassenger), ctx, booking, message)
+}
+
+// SendBoardingPass mocks base method.
+func (m *MockNotificationService) SendBoardingPass(ctx context.Context, booking *models.Booking) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendBoardingPass", ctx, booking)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendBoardingPass indicates an expected call of SendBoardingPass.
+func (mr *MockNotificationServiceMockRecorder) SendBoardingPass(ctx, booking interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendBoardingPass", reflect.TypeOf((*MockNotificationService)(nil).SendBoardingPass), ctx, booking)
+}
+
+// SendBookingConfirmation mocks base method.
+func (m *MockNotificationService) SendBookingConfirmation(ctx context.Context, booking *models.Booking) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendBookingConfirmation", ctx, booking)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendBookingConfirmation indicates an expected call of SendBookingConfirmation.
+func (mr *MockNotificationServiceMockRecorder) SendBookingConfirmation(ctx, booking interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendBookingConfirmation", reflect.TypeOf((*MockNotificationService)(nil).SendBookingConfirmation), ctx, booking)
+}
+
+// SendCheckInReminder mocks base method.
+func (m *MockNotificationService) SendCheckInReminder(ctx context.Context, booking *models.Booking) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendCheckInReminder", ctx, booking)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendCheckInReminder indicates an expected call of SendCheckInReminder.
+func (mr *MockNotificationServiceMockRecorder) SendCheckInReminder(ctx, booking interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendCheckInReminder", reflect.TypeOf((*MockNotificationService)(nil).SendCheckInReminder), ctx, booking)
+}
+
+// SendFlightStatusUpdate mocks base method.
+func (m *MockNotificationService) SendFlightStatusUpdate(ctx context.Context, booking *models.Booking, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendFlightStatusUpdate", ctx, booking, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendFlightStatusUpdate indicates an expected call of SendFlightStatusUpdate.
+func (mr *MockNotificationServiceMockRecorder) SendFlightStatusUpdate(ctx, booking, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendFlightStatusUpdate", reflect.TypeOf((*MockNotificationService)(nil).SendFlightStatusUpdate), ctx, booking, status)
+}
+
+// SendPromotionalOffer mocks base method.
+func (m *MockNotificationService) SendPromotionalOffer(ctx context.Context, passenger *models.Passenger, offer string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendPromotionalOffer", ctx, passenger, offer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendPromotionalOffer indicates an expected call of SendPromotionalOffer.
+func (mr *MockNotificationServiceMockRecorder) SendPromotionalOffer(ctx, passenger, offer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPromotionalOffer", reflect.TypeOf((*MockNotificationService)(nil).SendPromotionalOffer), ctx, passenger, offer)
+}
@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/orverbooking_service.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "airline-booking/models"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOverbookingService is a mock of OverbookingService interface.
+type MockOverbookingService struct {
+	ctrl     *gomock.Controller
+	recorder *MockOverbookingServiceMockRecorder
+}
+
+// MockOverbookingServiceMockRecorder is the mock recorder for MockOverbookingService.
+type MockOverbookingServiceMockRecorder struct {
+	mock *MockOverbookingService
+}
+
+// NewMockOverbookingService creates a new mock instance.
+func NewMockOverbookingService(ctrl *gomock.Controller) *MockOverbookingService {
+	mock := &MockOverbookingService{ctrl: ctrl}
+	mock.recorder = &MockOverbookingServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOverbookingService) EXPECT() *MockOverbookingServiceMockRecorder {
+	return m.recorder
+}
+
+// AdjustOverbookingRatio mocks base method.
+func (m *MockOverbookingService) AdjustOverbookingRatio(ctx context.Context, flightID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustOverbookingRatio", ctx, flightID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdjustOverbookingRatio indicates an expected call of AdjustOverbookingRatio.
+func (mr *MockOverbookingServiceMockRecorder) AdjustOverbookingRatio(ctx, flightID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustOverbookingRatio", reflect.TypeOf((*MockOverbookingService)(nil).AdjustOverbookingRatio), ctx, flightID)
+}
+
+// AssessRisk mocks base method.
+func (m *MockOverbookingService) AssessRisk(ctx context.Context, booking *models.Booking) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssessRisk", ctx, booking)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AssessRisk indicates an expected call of AssessRisk.
+func (mr *MockOverbookingServiceMockRecorder) AssessRisk(ctx, booking interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssessRisk", reflect.TypeOf((*MockOverbookingService)(nil).AssessRisk), ctx, booking)
+}
+
+// HandleOverbooking mocks base method.
+func (m *MockOverbookingService) HandleOverbooking(ctx context.Context, flightID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandleOverbooking", ctx, flightID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HandleOverbooking indicates an expected call of HandleOverbooking.
+func (mr *MockOverbookingServiceMockRecorder) HandleOverbooking(ctx, flightID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleOverbooking", reflect.TypeOf((*MockOverbookingService)(nil).HandleOverbooking), ctx, flightID)
+}
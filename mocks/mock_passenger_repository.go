@@ -0,0 +1,137 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repositories/passenger_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "airline-booking/models"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPassengerRepository is a mock of PassengerRepository interface.
+type MockPassengerRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPassengerRepositoryMockRecorder
+}
+
+// MockPassengerRepositoryMockRecorder is the mock recorder for MockPassengerRepository.
+type MockPassengerRepositoryMockRecorder struct {
+	mock *MockPassengerRepository
+}
+
+// NewMockPassengerRepository creates a new mock instance.
+func NewMockPassengerRepository(ctrl *gomock.Controller) *MockPassengerRepository {
+	mock := &MockPassengerRepository{ctrl: ctrl}
+	mock.recorder = &MockPassengerRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPassengerRepository) EXPECT() *MockPassengerRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreatePassenger mocks base method.
+func (m *MockPassengerRepository) CreatePassenger(ctx context.Context, passenger *models.Passenger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePassenger", ctx, passenger)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePassenger indicates an expected call of CreatePassenger.
+func (mr *MockPassengerRepositoryMockRecorder) CreatePassenger(ctx, passenger interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePassenger", reflect.TypeOf((*MockPassengerRepository)(nil).CreatePassenger), ctx, passenger)
+}
+
+// DeletePassenger mocks base method.
+func (m *MockPassengerRepository) DeletePassenger(ctx context.Context, passengerID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePassenger", ctx, passengerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePassenger indicates an expected call of DeletePassenger.
+func (mr *MockPassengerRepositoryMockRecorder) DeletePassenger(ctx, passengerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePassenger", reflect.TypeOf((*MockPassengerRepository)(nil).DeletePassenger), ctx, passengerID)
+}
+
+// GetPassengerByID mocks base method.
+func (m *MockPassengerRepository) GetPassengerByID(ctx context.Context, passengerID int) (*models.Passenger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPassengerByID", ctx, passengerID)
+	ret0, _ := ret[0].(*models.Passenger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPassengerByID indicates an expected call of GetPassengerByID.
+func (mr *MockPassengerRepositoryMockRecorder) GetPassengerByID(ctx, passengerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPassengerByID", reflect.TypeOf((*MockPassengerRepository)(nil).GetPassengerByID), ctx, passengerID)
+}
+
+// GetPassengerHistory mocks base method.
+func (m *MockPassengerRepository) GetPassengerHistory(ctx context.Context, passengerID int) (*models.PassengerHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPassengerHistory", ctx, passengerID)
+	ret0, _ := ret[0].(*models.PassengerHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPassengerHistory indicates an expected call of GetPassengerHistory.
+func (mr *MockPassengerRepositoryMockRecorder) GetPassengerHistory(ctx, passengerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPassengerHistory", reflect.TypeOf((*MockPassengerRepository)(nil).GetPassengerHistory), ctx, passengerID)
+}
+
+// ListPassengers mocks base method.
+func (m *MockPassengerRepository) ListPassengers(ctx context.Context, filter models.PassengerFilter) (*models.PassengerPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPassengers", ctx, filter)
+	ret0, _ := ret[0].(*models.PassengerPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPassengers indicates an expected call of ListPassengers.
+func (mr *MockPassengerRepositoryMockRecorder) ListPassengers(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPassengers", reflect.TypeOf((*MockPassengerRepository)(nil).ListPassengers), ctx, filter)
+}
+
+// UpdateFrequentFlyerPoints mocks base method.
+func (m *MockPassengerRepository) UpdateFrequentFlyerPoints(ctx context.Context, passengerID, pointsToAdd int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFrequentFlyerPoints", ctx, passengerID, pointsToAdd)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFrequentFlyerPoints indicates an expected call of UpdateFrequentFlyerPoints.
+func (mr *MockPassengerRepositoryMockRecorder) UpdateFrequentFlyerPoints(ctx, passengerID, pointsToAdd interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFrequentFlyerPoints", reflect.TypeOf((*MockPassengerRepository)(nil).UpdateFrequentFlyerPoints), ctx, passengerID, pointsToAdd)
+}
+
+// UpdatePassenger mocks base method.
+func (m *MockPassengerRepository) UpdatePassenger(ctx context.Context, passenger *models.Passenger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassenger", ctx, passenger)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassenger indicates an expected call of UpdatePassenger.
+func (mr *MockPassengerRepositoryMockRecorder) UpdatePassenger(ctx, passenger interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassenger", reflect.TypeOf((*MockPassengerRepository)(nil).UpdatePassenger), ctx, passenger)
+}
@@ -0,0 +1,152 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repositories/payment_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "airline-booking/models"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPaymentRepository is a mock of PaymentRepository interface.
+type MockPaymentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentRepositoryMockRecorder
+}
+
+// MockPaymentRepositoryMockRecorder is the mock recorder for MockPaymentRepository.
+type MockPaymentRepositoryMockRecorder struct {
+	mock *MockPaymentRepository
+}
+
+// NewMockPaymentRepository creates a new mock instance.
+func NewMockPaymentRepository(ctrl *gomock.Controller) *MockPaymentRepository {
+	mock := &MockPaymentRepository{ctrl: ctrl}
+	mock.recorder = &MockPaymentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPaymentRepository) EXPECT() *MockPaymentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreatePayment mocks base method.
+func (m *MockPaymentRepository) CreatePayment(ctx context.Context, payment *models.Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePayment", ctx, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePayment indicates an expected call of CreatePayment.
+func (mr *MockPaymentRepositoryMockRecorder) CreatePayment(ctx, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePayment", reflect.TypeOf((*MockPaymentRepository)(nil).CreatePayment), ctx, payment)
+}
+
+// CreateRefund mocks base method.
+func (m *MockPaymentRepository) CreateRefund(ctx context.Context, refund *models.Refund) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefund", ctx, refund)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRefund indicates an expected call of CreateRefund.
+func (mr *MockPaymentRepositoryMockRecorder) CreateRefund(ctx, refund interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefund", reflect.TypeOf((*MockPaymentRepository)(nil).CreateRefund), ctx, refund)
+}
+
+// GetPaymentByIdempotencyKey mocks base method.
+func (m *MockPaymentRepository) GetPaymentByIdempotencyKey(ctx context.Context, key string) (*models.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentByIdempotencyKey", ctx, key)
+	ret0, _ := ret[0].(*models.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentByIdempotencyKey indicates an expected call of GetPaymentByIdempotencyKey.
+func (mr *MockPaymentRepositoryMockRecorder) GetPaymentByIdempotencyKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentByIdempotencyKey", reflect.TypeOf((*MockPaymentRepository)(nil).GetPaymentByIdempotencyKey), ctx, key)
+}
+
+// GetPaymentsByBooking mocks base method.
+func (m *MockPaymentRepository) GetPaymentsByBooking(ctx context.Context, bookingID int) ([]*models.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentsByBooking", ctx, bookingID)
+	ret0, _ := ret[0].([]*models.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentsByBooking indicates an expected call of GetPaymentsByBooking.
+func (mr *MockPaymentRepositoryMockRecorder) GetPaymentsByBooking(ctx, bookingID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentsByBooking", reflect.TypeOf((*MockPaymentRepository)(nil).GetPaymentsByBooking), ctx, bookingID)
+}
+
+// GetRefundByIdempotencyKey mocks base method.
+func (m *MockPaymentRepository) GetRefundByIdempotencyKey(ctx context.Context, key string) (*models.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefundByIdempotencyKey", ctx, key)
+	ret0, _ := ret[0].(*models.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefundByIdempotencyKey indicates an expected call of GetRefundByIdempotencyKey.
+func (mr *MockPaymentRepositoryMockRecorder) GetRefundByIdempotencyKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefundByIdempotencyKey", reflect.TypeOf((*MockPaymentRepository)(nil).GetRefundByIdempotencyKey), ctx, key)
+}
+
+// GetRefundsByBooking mocks base method.
+func (m *MockPaymentRepository) GetRefundsByBooking(ctx context.Context, bookingID int) ([]*models.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefundsByBooking", ctx, bookingID)
+	ret0, _ := ret[0].([]*models.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefundsByBooking indicates an expected call of GetRefundsByBooking.
+func (mr *MockPaymentRepositoryMockRecorder) GetRefundsByBooking(ctx, bookingID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefundsByBooking", reflect.TypeOf((*MockPaymentRepository)(nil).GetRefundsByBooking), ctx, bookingID)
+}
+
+// UpdatePayment mocks base method.
+func (m *MockPaymentRepository) UpdatePayment(ctx context.Context, payment *models.Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePayment", ctx, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePayment indicates an expected call of UpdatePayment.
+func (mr *MockPaymentRepositoryMockRecorder) UpdatePayment(ctx, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePayment", reflect.TypeOf((*MockPaymentRepository)(nil).UpdatePayment), ctx, payment)
+}
+
+// UpdateRefundStatus mocks base method.
+func (m *MockPaymentRepository) UpdateRefundStatus(ctx context.Context, refund *models.Refund) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRefundStatus", ctx, refund)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRefundStatus indicates an expected call of UpdateRefundStatus.
+func (mr *MockPaymentRepositoryMockRecorder) UpdateRefundStatus(ctx, refund interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRefundStatus", reflect.TypeOf((*MockPaymentRepository)(nil).UpdateRefundStatus), ctx, refund)
+}
@@ -0,0 +1,124 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/payment_service.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "airline-booking/models"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPaymentService is a mock of PaymentService interface.
+type MockPaymentService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentServiceMockRecorder
+}
+
+// MockPaymentServiceMockRecorder is the mock recorder for MockPaymentService.
+type MockPaymentServiceMockRecorder struct {
+	mock *MockPaymentService
+}
+
+// NewMockPaymentService creates a new mock instance.
+func NewMockPaymentService(ctrl *gomock.Controller) *MockPaymentService {
+	mock := &MockPaymentService{ctrl: ctrl}
+	mock.recorder = &MockPaymentServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPaymentService) EXPECT() *MockPaymentServiceMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method.
+func (m *MockPaymentService) Authorize(ctx context.Context, booking *models.Booking, req models.PaymentRequest) (*models.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, booking, req)
+	ret0, _ := ret[0].(*models.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockPaymentServiceMockRecorder) Authorize(ctx, booking, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockPaymentService)(nil).Authorize), ctx, booking, req)
+}
+
+// Capture mocks base method.
+func (m *MockPaymentService) Capture(ctx context.Context, payment *models.Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capture", ctx, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Capture indicates an expected call of Capture.
+func (mr *MockPaymentServiceMockRecorder) Capture(ctx, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*MockPaymentService)(nil).Capture), ctx, payment)
+}
+
+// ListPayments mocks base method.
+func (m *MockPaymentService) ListPayments(ctx context.Context, bookingID int) ([]*models.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPayments", ctx, bookingID)
+	ret0, _ := ret[0].([]*models.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPayments indicates an expected call of ListPayments.
+func (mr *MockPaymentServiceMockRecorder) ListPayments(ctx, bookingID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPayments", reflect.TypeOf((*MockPaymentService)(nil).ListPayments), ctx, bookingID)
+}
+
+// RequestRefund mocks base method.
+func (m *MockPaymentService) RequestRefund(ctx context.Context, bookingID int, amount float64, purpose string) (*models.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestRefund", ctx, bookingID, amount, purpose)
+	ret0, _ := ret[0].(*models.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestRefund indicates an expected call of RequestRefund.
+func (mr *MockPaymentServiceMockRecorder) RequestRefund(ctx, bookingID, amount, purpose interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestRefund", reflect.TypeOf((*MockPaymentService)(nil).RequestRefund), ctx, bookingID, amount, purpose)
+}
+
+// SubmitRefunds mocks base method.
+func (m *MockPaymentService) SubmitRefunds(ctx context.Context, bookingID int) ([]*models.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitRefunds", ctx, bookingID)
+	ret0, _ := ret[0].([]*models.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubmitRefunds indicates an expected call of SubmitRefunds.
+func (mr *MockPaymentServiceMockRecorder) SubmitRefunds(ctx, bookingID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitRefunds", reflect.TypeOf((*MockPaymentService)(nil).SubmitRefunds), ctx, bookingID)
+}
+
+// Void mocks base method.
+func (m *MockPaymentService) Void(ctx context.Context, payment *models.Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Void", ctx, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Void indicates an expected call of Void.
+func (mr *MockPaymentServiceMockRecorder) Void(ctx, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Void", reflect.TypeOf((*MockPaymentService)(nil).Void), ctx, payment)
+}
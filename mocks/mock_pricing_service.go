@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/pricing_service.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "airline-booking/models"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPricingService is a mock of PricingService interface.
+type MockPricingService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPricingServiceMockRecorder
+}
+
+// MockPricingServiceMockRecorder is the mock recorder for MockPricingService.
+type MockPricingServiceMockRecorder struct {
+	mock *MockPricingService
+}
+
+// NewMockPricingService creates a new mock instance.
+func NewMockPricingService(ctrl *gomock.Controller) *MockPricingService {
+	mock := &MockPricingService{ctrl: ctrl}
+	mock.recorder = &MockPricingServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPricingService) EXPECT() *MockPricingServiceMockRecorder {
+	return m.recorder
+}
+
+// PriceFlights mocks base method.
+func (m *MockPricingService) PriceFlights(ctx context.Context, flights []models.Flight) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PriceFlights", ctx, flights)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PriceFlights indicates an expected call of PriceFlights.
+func (mr *MockPricingServiceMockRecorder) PriceFlights(ctx, flights interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PriceFlights", reflect.TypeOf((*MockPricingService)(nil).PriceFlights), ctx, flights)
+}
+
+// Quote mocks base method.
+func (m *MockPricingService) Quote(ctx context.Context, flight *models.Flight, cabin, fareClass string) (models.Fare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Quote", ctx, flight, cabin, fareClass)
+	ret0, _ := ret[0].(models.Fare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Quote indicates an expected call of Quote.
+func (mr *MockPricingServiceMockRecorder) Quote(ctx, flight, cabin, fareClass interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Quote", reflect.TypeOf((*MockPricingService)(nil).Quote), ctx, flight, cabin, fareClass)
+}
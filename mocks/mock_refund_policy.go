@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/refund_policy.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "airline-booking/models"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRefundPolicy is a mock of RefundPolicy interface.
+type MockRefundPolicy struct {
+	ctrl     *gomock.Controller
+	recorder *MockRefundPolicyMockRecorder
+}
+
+// MockRefundPolicyMockRecorder is the mock recorder for MockRefundPolicy.
+type MockRefundPolicyMockRecorder struct {
+	mock *MockRefundPolicy
+}
+
+// NewMockRefundPolicy creates a new mock instance.
+func NewMockRefundPolicy(ctrl *gomock.Controller) *MockRefundPolicy {
+	mock := &MockRefundPolicy{ctrl: ctrl}
+	mock.recorder = &MockRefundPolicyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRefundPolicy) EXPECT() *MockRefundPolicyMockRecorder {
+	return m.recorder
+}
+
+// Quote mocks base method.
+func (m *MockRefundPolicy) Quote(ctx context.Context, booking *models.Booking) (*models.CancellationQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Quote", ctx, booking)
+	ret0, _ := ret[0].(*models.CancellationQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Quote indicates an expected call of Quote.
+func (mr *MockRefundPolicyMockRecorder) Quote(ctx, booking interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Quote", reflect.TypeOf((*MockRefundPolicy)(nil).Quote), ctx, booking)
+}
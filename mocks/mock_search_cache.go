@@ -0,0 +1,76 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/search_cache.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	services "airline-booking/services"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSearchCache is a mock of SearchCache interface.
+type MockSearchCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockSearchCacheMockRecorder
+}
+
+// MockSearchCacheMockRecorder is the mock recorder for MockSearchCache.
+type MockSearchCacheMockRecorder struct {
+	mock *MockSearchCache
+}
+
+// NewMockSearchCache creates a new mock instance.
+func NewMockSearchCache(ctrl *gomock.Controller) *MockSearchCache {
+	mock := &MockSearchCache{ctrl: ctrl}
+	mock.recorder = &MockSearchCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSearchCache) EXPECT() *MockSearchCacheMockRecorder {
+	return m.recorder
+}
+
+// Fetch mocks base method.
+func (m *MockSearchCache) Fetch(ctx context.Context, key string, scopes []string, dst interface{}, load func(context.Context) (interface{}, error)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fetch", ctx, key, scopes, dst, load)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Fetch indicates an expected call of Fetch.
+func (mr *MockSearchCacheMockRecorder) Fetch(ctx, key, scopes, dst, load interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fetch", reflect.TypeOf((*MockSearchCache)(nil).Fetch), ctx, key, scopes, dst, load)
+}
+
+// InvalidateFlight mocks base method.
+func (m *MockSearchCache) InvalidateFlight(ctx context.Context, flightID int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InvalidateFlight", ctx, flightID)
+}
+
+// InvalidateFlight indicates an expected call of InvalidateFlight.
+func (mr *MockSearchCacheMockRecorder) InvalidateFlight(ctx, flightID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateFlight", reflect.TypeOf((*MockSearchCache)(nil).InvalidateFlight), ctx, flightID)
+}
+
+// Stats mocks base method.
+func (m *MockSearchCache) Stats() services.SearchCacheStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(services.SearchCacheStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockSearchCacheMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockSearchCache)(nil).Stats))
+}
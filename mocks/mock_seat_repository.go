@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repositories/seat_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "airline-booking/models"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSeatRepository is a mock of SeatRepository interface.
+type MockSeatRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSeatRepositoryMockRecorder
+}
+
+// MockSeatRepositoryMockRecorder is the mock recorder for MockSeatRepository.
+type MockSeatRepositoryMockRecorder struct {
+	mock *MockSeatRepository
+}
+
+// NewMockSeatRepository creates a new mock instance.
+func NewMockSeatRepository(ctrl *gomock.Controller) *MockSeatRepository {
+	mock := &MockSeatRepository{ctrl: ctrl}
+	mock.recorder = &MockSeatRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSeatRepository) EXPECT() *MockSeatRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetOccupiedSeats mocks base method.
+func (m *MockSeatRepository) GetOccupiedSeats(ctx context.Context, flightID int) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOccupiedSeats", ctx, flightID)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOccupiedSeats indicates an expected call of GetOccupiedSeats.
+func (mr *MockSeatRepositoryMockRecorder) GetOccupiedSeats(ctx, flightID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOccupiedSeats", reflect.TypeOf((*MockSeatRepository)(nil).GetOccupiedSeats), ctx, flightID)
+}
+
+// GetSeatMap mocks base method.
+func (m *MockSeatRepository) GetSeatMap(ctx context.Context, aircraftType string) ([]models.Seat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSeatMap", ctx, aircraftType)
+	ret0, _ := ret[0].([]models.Seat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSeatMap indicates an expected call of GetSeatMap.
+func (mr *MockSeatRepositoryMockRecorder) GetSeatMap(ctx, aircraftType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSeatMap", reflect.TypeOf((*MockSeatRepository)(nil).GetSeatMap), ctx, aircraftType)
+}
+
+// SaveSeatMap mocks base method.
+func (m *MockSeatRepository) SaveSeatMap(ctx context.Context, aircraftType string, seats []models.Seat) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSeatMap", ctx, aircraftType, seats)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSeatMap indicates an expected call of SaveSeatMap.
+func (mr *MockSeatRepositoryMockRecorder) SaveSeatMap(ctx, aircraftType, seats interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSeatMap", reflect.TypeOf((*MockSeatRepository)(nil).SaveSeatMap), ctx, aircraftType, seats)
+}
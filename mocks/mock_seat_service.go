@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/seat_service.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "airline-booking/models"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSeatService is a mock of SeatService interface.
+type MockSeatService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSeatServiceMockRecorder
+}
+
+// MockSeatServiceMockRecorder is the mock recorder for MockSeatService.
+type MockSeatServiceMockRecorder struct {
+	mock *MockSeatService
+}
+
+// NewMockSeatService creates a new mock instance.
+func NewMockSeatService(ctrl *gomock.Controller) *MockSeatService {
+	mock := &MockSeatService{ctrl: ctrl}
+	mock.recorder = &MockSeatServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSeatService) EXPECT() *MockSeatServiceMockRecorder {
+	return m.recorder
+}
+
+// AssignSeat mocks base method.
+func (m *MockSeatService) AssignSeat(ctx context.Context, flight *models.Flight, booking *models.Booking, preference string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignSeat", ctx, flight, booking, preference)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssignSeat indicates an expected call of AssignSeat.
+func (mr *MockSeatServiceMockRecorder) AssignSeat(ctx, flight, booking, preference interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignSeat", reflect.TypeOf((*MockSeatService)(nil).AssignSeat), ctx, flight, booking, preference)
+}
+
+// GetSeatMap mocks base method.
+func (m *MockSeatService) GetSeatMap(ctx context.Context, flightID int) (*models.FlightSeatMap, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSeatMap", ctx, flightID)
+	ret0, _ := ret[0].(*models.FlightSeatMap)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSeatMap indicates an expected call of GetSeatMap.
+func (mr *MockSeatServiceMockRecorder) GetSeatMap(ctx, flightID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSeatMap", reflect.TypeOf((*MockSeatService)(nil).GetSeatMap), ctx, flightID)
+}
+
+// LoadSeatLayouts mocks base method.
+func (m *MockSeatService) LoadSeatLayouts(ctx context.Context, layouts []models.SeatLayout) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadSeatLayouts", ctx, layouts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LoadSeatLayouts indicates an expected call of LoadSeatLayouts.
+func (mr *MockSeatServiceMockRecorder) LoadSeatLayouts(ctx, layouts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadSeatLayouts", reflect.TypeOf((*MockSeatService)(nil).LoadSeatLayouts), ctx, layouts)
+}
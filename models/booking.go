@@ -10,14 +10,16 @@ type Booking struct {
 	FlightID       int       `json:"flight_id"`
 	Class          string    `json:"class"` // "economy", "business", "first"
 	SeatNumber     string    `json:"seat_number"`
-	Status         string    `json:"status"` // "confirmed", "cancelled", "checked-in"
+	Status         string    `json:"status"` // "held", "confirmed", "cancelled", "expired"
 	BookingTime    time.Time `json:"booking_time"`
 	CheckInTime    time.Time `json:"check_in_time,omitempty"`
 	HasCheckedIn   bool      `json:"has_checked_in"`
 	Price          Money     `json:"price"`
+	FareClass      string    `json:"fare_class,omitempty"` // 票價艙等代碼，不指定時售出該艙位最便宜的可售艙等
 	Compensation   Money     `json:"compensation,omitempty"`
 	RiskScore      float64   `json:"risk_score"`
-	IsCheapestFare bool      `json:"is_cheapest_fare"`
+	IsCheapestFare bool      `json:"is_cheapest_fare"`          // 由售出的票價艙等得出，客戶端提交的值會被忽略
+	HoldExpiresAt  time.Time `json:"hold_expires_at,omitempty"` // 保留中的預訂需在此時間前完成支付，保存在 booking_holds 表
 
 	// 關聯
 	Passenger *Passenger `json:"passenger,omitempty"`
@@ -40,6 +42,25 @@ type Booking struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// HoldsInventory 返回預訂是否仍佔用座位與艙等配額：已取消與保留過期的預訂的座位已經釋放
+func (b *Booking) HoldsInventory() bool {
+	return b.Status != "cancelled" && b.Status != "expired"
+}
+
+// CancellationQuote 是按退票規則計算的取消結果，試算與實際取消使用同一套規則
+type CancellationQuote struct {
+	BookingID int `json:"booking_id"`
+	// Paid 是已扣款且尚未退回的金額，未付款的預訂為 0
+	Paid Money `json:"paid"`
+	// RefundRate 是按距離起飛時間與會員等級可退回 Paid 的比例
+	RefundRate      float64 `json:"refund_rate"`
+	CancellationFee Money   `json:"cancellation_fee"`
+	Refund          Money   `json:"refund"`
+	// Reason 說明不退款的原因，例如票價艙等不可退
+	Reason   string    `json:"reason,omitempty"`
+	QuotedAt time.Time `json:"quoted_at"`
+}
+
 type Money struct {
 	Amount   float64 `json:"amount"`
 	Currency string  `json:"currency"`
@@ -67,6 +88,10 @@ type BookingFilter struct {
 	DateFrom     time.Time `json:"date_from,omitempty"`
 	DateTo       time.Time `json:"date_to,omitempty"`
 	IsOverbooked *bool     `json:"is_overbooked,omitempty"`
+
+	// 分頁：Cursor 是上一頁回應的 next_cursor
+	Cursor   string `json:"cursor,omitempty"`
+	PageSize int    `json:"page_size,omitempty"`
 }
 
 // 用於更新操作的結構
@@ -0,0 +1,45 @@
+package models
+
+// FareBucket 是按艙位載客率劃分的默認票價等級，用於沒有定義票價艙等的航班：
+// 載客率不超過 MaxLoadFactor 時使用該等級，票價為艙位基礎票價乘以 Multiplier。
+// 各等級按 MaxLoadFactor 由低到高排列
+type FareBucket struct {
+	Name          string  `json:"name" yaml:"name"`
+	MaxLoadFactor float64 `json:"max_load_factor" yaml:"max_load_factor"`
+	Multiplier    float64 `json:"multiplier" yaml:"multiplier"`
+}
+
+// FareClass 是艙位下的票價艙等（訂位代碼，如 Y/B/M/Q），有獨立的座位配額、票價與退改規則
+type FareClass struct {
+	ID          int       `json:"id"`
+	FlightID    int       `json:"flight_id"`
+	Cabin       string    `json:"cabin"`
+	Code        string    `json:"code"`
+	Price       float64   `json:"price"`
+	SeatsTotal  int       `json:"seats_total"`
+	SeatsBooked int       `json:"seats_booked"`
+	Rules       FareRules `json:"rules"`
+}
+
+// Available 返回艙等配額中尚未售出的座位數
+func (c FareClass) Available() int {
+	return max(c.SeatsTotal-c.SeatsBooked, 0)
+}
+
+// FareRules 是票價艙等的退改規則，費用與票價同幣別
+type FareRules struct {
+	Refundable bool    `json:"refundable"`
+	RefundFee  float64 `json:"refund_fee"`
+	Changeable bool    `json:"changeable"`
+	ChangeFee  float64 `json:"change_fee"`
+}
+
+// Fare 是某艙位按當前需求計算出的票價。
+// FareClass 是售出的票價艙等代碼；航班沒有定義票價艙等時為按載客率選出的默認等級，此時沒有 Rules
+type Fare struct {
+	FareClass string     `json:"fare_class"`
+	Price     Money      `json:"price"`
+	Rules     *FareRules `json:"rules,omitempty"`
+	// Lowest 表示售出的是該艙位最便宜的艙等或等級
+	Lowest bool `json:"lowest"`
+}
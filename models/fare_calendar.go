@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// FareCalendarRequest 是低價日曆的查詢條件。
+// 指定 Month（YYYY-MM）查詢整個月；否則查詢 Date 前後 FlexDays 天。
+type FareCalendarRequest struct {
+	Origin      string          `json:"origin"`
+	Destination string          `json:"destination"`
+	Date        time.Time       `json:"date"`
+	FlexDays    int             `json:"flex_days"`
+	Month       string          `json:"month,omitempty"`
+	Cabin       string          `json:"cabin"`
+	Passengers  PassengerCounts `json:"passengers"`
+}
+
+// FareCalendarDay 是某天（出發機場當地日期）的最低價與可售情況。
+// MinPrice 是所選艙位的當前售價，與搜索結果的 fares 一致；只計算能容納整個團體且該艙位未售完的航班，
+// 沒有這樣的航班時為 nil。
+type FareCalendarDay struct {
+	Date     string   `json:"date"`
+	MinPrice *float64 `json:"min_price"`
+	// Flights 是當天的航班數，BookableFlights 是其中座位足夠且所選艙位有票價的航班數
+	Flights         int `json:"flights"`
+	BookableFlights int `json:"bookable_flights"`
+	// MaxAvailableSeats 是當天單個航班在所選艙位的最多可售座位數
+	MaxAvailableSeats int `json:"max_available_seats"`
+}
+
+// FareCalendar 是一段日期內逐日的低價日曆，沒有航班的日期也會列出
+type FareCalendar struct {
+	Origin      string            `json:"origin"`
+	Destination string            `json:"destination"`
+	Cabin       string            `json:"cabin"`
+	From        string            `json:"from"`
+	To          string            `json:"to"`
+	Days        []FareCalendarDay `json:"days"`
+	// CheapestDate 是最低價的日期，同價時取較早的一天；沒有可訂航班時為空
+	CheapestDate string `json:"cheapest_date,omitempty"`
+}
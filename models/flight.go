@@ -2,40 +2,175 @@ package models
 
 import (
 	"fmt"
+	"math"
 	"time"
 )
 
+// 航班狀態
+const (
+	FlightScheduled = "scheduled"
+	FlightDelayed   = "delayed"
+	FlightBoarding  = "boarding"
+	FlightDeparted  = "departed"
+	FlightArrived   = "arrived"
+	FlightCancelled = "cancelled"
+)
+
 type Flight struct {
-	ID             int       `json:"id"`
-	Origin         string    `json:"origin"`
-	Destination    string    `json:"destination"`
-	DepartureTime  time.Time `json:"departure_time"`
-	Price          float64   `json:"price"`
-	AvailableSeats int       `json:"available_seats"`
-	TotalSeats     int       `json:"total_seats"`
-	EconomySeats   struct {
-		Total            int
-		Booked           int
-		OverbookingRatio float64
-	}
-	BusinessSeats struct {
-		Total            int
-		Booked           int
-		OverbookingRatio float64
+	ID           int    `json:"id"`
+	FlightNumber string `json:"flight_number"`
+	Carrier      string `json:"carrier"`
+	AircraftType string `json:"aircraft_type,omitempty"`
+	// Origin 與 Destination 是機場三字碼，時區為機場所在地的 IANA 時區名稱
+	Origin              string    `json:"origin"`
+	OriginTimezone      string    `json:"origin_timezone,omitempty"`
+	Destination         string    `json:"destination"`
+	DestinationTimezone string    `json:"destination_timezone,omitempty"`
+	DepartureTime       time.Time `json:"departure_time"`
+	ArrivalTime         time.Time `json:"arrival_time"`
+	Status              string    `json:"status"`
+	// Price 是經濟艙的基礎票價，實際售價見 Fares
+	Price float64 `json:"price"`
+	// Fares 是按艙位計算的當前票價，鍵為艙位；只在搜索結果中填充，沒有座位的艙位不列出
+	Fares map[string]Fare `json:"fares,omitempty"`
+	// Availability 是按艙位計算的可售座位數，由 SeatInventory 得出
+	Availability CabinAvailability `json:"availability"`
+
+	// 座位庫存與超賣比例屬於內部數據，不對外輸出
+	EconomySeats    SeatInventory `json:"-"`
+	BusinessSeats   SeatInventory `json:"-"`
+	FirstClassSeats SeatInventory `json:"-"`
+}
+
+// SeatInventory 是單個艙位的座位庫存
+type SeatInventory struct {
+	Total            int
+	Booked           int
+	OverbookingRatio float64
+}
+
+// Available 返回含超賣比例的剩餘可售座位數，與 AdjustBookedSeats 的上限計算一致。
+// 超賣比例在數據庫中是 DECIMAL，加上一個極小值抵消浮點誤差（如 100*1.15 得到 114.999...）
+func (s SeatInventory) Available() int {
+	capacity := int(math.Floor(float64(s.Total)*(1+s.OverbookingRatio) + 1e-9))
+	return max(capacity-s.Booked, 0)
+}
+
+// CabinAvailability 是各艙位的可售座位數
+type CabinAvailability struct {
+	Economy  int `json:"economy"`
+	Business int `json:"business"`
+	First    int `json:"first"`
+}
+
+// Seats 返回指定艙位的座位庫存，艙位無效時返回 false
+func (f *Flight) Seats(class string) (SeatInventory, bool) {
+	switch class {
+	case "economy":
+		return f.EconomySeats, true
+	case "business":
+		return f.BusinessSeats, true
+	case "first":
+		return f.FirstClassSeats, true
+	default:
+		return SeatInventory{}, false
 	}
-	FirstClassSeats struct {
-		Total            int
-		Booked           int
-		OverbookingRatio float64
+}
+
+// UpdateAvailability 根據座位庫存重新計算 Availability
+func (f *Flight) UpdateAvailability() {
+	f.Availability = CabinAvailability{
+		Economy:  f.EconomySeats.Available(),
+		Business: f.BusinessSeats.Available(),
+		First:    f.FirstClassSeats.Available(),
 	}
 }
 
+// SearchRequest 是一次異步航班搜索。
+// 單程只需 Origin、Destination、Date；設置 ReturnDate 即為往返；
+// 多城市行程使用 Legs，此時不應設置 Origin、Destination、Date 與 ReturnDate。
 type SearchRequest struct {
+	Origin      string      `json:"origin,omitempty"`
+	Destination string      `json:"destination,omitempty"`
+	Date        time.Time   `json:"date"`
+	ReturnDate  time.Time   `json:"return_date"`
+	Legs        []SearchLeg `json:"legs,omitempty"`
+	// Passengers 是各類乘客人數，Cabin 是要檢查可售座位的艙位
+	Passengers PassengerCounts `json:"passengers"`
+	Cabin      string          `json:"cabin,omitempty"`
+	Filters    SearchFilters   `json:"filters"`
+	// SortBy 是排序方式，見 FlightSort* 常量，默認按出發時間
+	SortBy string `json:"sort_by,omitempty"`
+	// Cursor 是上一頁回應的 next_cursor，只能用於單段搜索且排序方式不變；為空時從第一頁開始
+	Cursor   string `json:"cursor,omitempty"`
+	PageSize int    `json:"page_size"`
+}
+
+// 航班搜索排序方式
+const (
+	FlightSortDeparture    = "departure"
+	FlightSortPrice        = "price"
+	FlightSortDuration     = "duration"
+	FlightSortAvailability = "availability"
+)
+
+// SearchFilters 是航班搜索的過濾條件，零值表示不過濾。
+// 時間窗口為 HH:MM 格式，出發按出發機場、到達按到達機場的當地時間，包含端點。
+type SearchFilters struct {
+	DepartureAfter  string   `json:"departure_after,omitempty"`
+	DepartureBefore string   `json:"departure_before,omitempty"`
+	ArrivalAfter    string   `json:"arrival_after,omitempty"`
+	ArrivalBefore   string   `json:"arrival_before,omitempty"`
+	MaxPrice        float64  `json:"max_price,omitempty"`
+	Carriers        []string `json:"carriers,omitempty"`
+	// MaxStops 大於 0 時，每段航程除直飛航班外還返回轉機次數不超過它的行程
+	MaxStops int `json:"max_stops,omitempty"`
+}
+
+// SearchLeg 是行程中的一段航程
+type SearchLeg struct {
 	Origin      string    `json:"origin"`
 	Destination string    `json:"destination"`
 	Date        time.Time `json:"date"`
-	Page        int       `json:"page"`
-	PageSize    int       `json:"page_size"`
+}
+
+// ResolveLegs 將單程、往返與多城市三種寫法統一為航程列表
+func (r *SearchRequest) ResolveLegs() []SearchLeg {
+	if len(r.Legs) > 0 {
+		return r.Legs
+	}
+	legs := []SearchLeg{{Origin: r.Origin, Destination: r.Destination, Date: r.Date}}
+	if !r.ReturnDate.IsZero() {
+		legs = append(legs, SearchLeg{Origin: r.Destination, Destination: r.Origin, Date: r.ReturnDate})
+	}
+	return legs
+}
+
+// PassengerCounts 是按類型劃分的乘客人數
+type PassengerCounts struct {
+	Adults   int `json:"adults"`
+	Children int `json:"children"`
+	// Infants 是不佔座位、由成人懷抱的嬰兒
+	Infants int `json:"infants"`
+}
+
+// Seated 返回需要佔用座位的乘客數，嬰兒不佔座位
+func (p PassengerCounts) Seated() int {
+	return p.Adults + p.Children
+}
+
+// For 返回指定艙位的可售座位數，艙位無效時返回 0
+func (a CabinAvailability) For(class string) int {
+	switch class {
+	case "economy":
+		return a.Economy
+	case "business":
+		return a.Business
+	case "first":
+		return a.First
+	default:
+		return 0
+	}
 }
 
 // 在現有的 Flight 結構體之後添加：
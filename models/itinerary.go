@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// 行程排序方式
+const (
+	ItinerarySortDuration = "duration"
+	ItinerarySortPrice    = "price"
+	ItinerarySortStops    = "stops"
+)
+
+// ItinerarySearchRequest 是含轉機的行程搜索條件，時長以分鐘為單位
+type ItinerarySearchRequest struct {
+	Origin      string    `json:"origin"`
+	Destination string    `json:"destination"`
+	Date        time.Time `json:"date"`
+	// MaxStops 是最多轉機次數，0 表示只搜索直飛；未設置時由服務填充默認值
+	MaxStops             *int   `json:"max_stops,omitempty"`
+	MinConnectionMinutes int    `json:"min_connection_minutes"`
+	MaxConnectionMinutes int    `json:"max_connection_minutes"`
+	SortBy               string `json:"sort_by"`
+	Limit                int    `json:"limit"`
+}
+
+// Itinerary 是由一個或多個航段組成的行程
+type Itinerary struct {
+	Segments        []Flight  `json:"segments"`
+	Stops           int       `json:"stops"`
+	DepartureTime   time.Time `json:"departure_time"`
+	ArrivalTime     time.Time `json:"arrival_time"`
+	DurationMinutes int       `json:"duration_minutes"`
+	// TotalPrice 是所選艙位各航段當前票價的合計
+	TotalPrice float64 `json:"total_price"`
+	// Availability 是各艙位在所有航段上都能售出的座位數，即各航段的最小值
+	Availability CabinAvailability `json:"availability"`
+}
+
+// NewItinerary 由按時間順序排列的已定價航段組成行程，並計算 cabin 艙位的總價、總時長與可售座位。
+// 任何一段沒有該艙位的票價（已售完）時行程無法售出，返回 false
+func NewItinerary(segments []Flight, cabin string) (Itinerary, bool) {
+	first, last := segments[0], segments[len(segments)-1]
+	itinerary := Itinerary{
+		Segments:        segments,
+		Stops:           len(segments) - 1,
+		DepartureTime:   first.DepartureTime,
+		ArrivalTime:     last.ArrivalTime,
+		DurationMinutes: int(last.ArrivalTime.Sub(first.DepartureTime) / time.Minute),
+		Availability:    first.Availability,
+	}
+
+	for _, segment := range segments {
+		fare, ok := segment.Fares[cabin]
+		if !ok {
+			return Itinerary{}, false
+		}
+		itinerary.TotalPrice += fare.Price.Amount
+		itinerary.Availability = CabinAvailability{
+			Economy:  min(itinerary.Availability.Economy, segment.Availability.Economy),
+			Business: min(itinerary.Availability.Business, segment.Availability.Business),
+			First:    min(itinerary.Availability.First, segment.Availability.First),
+		}
+	}
+	return itinerary, true
+}
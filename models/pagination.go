@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrInvalidCursor 表示分頁游標無法解析，或不屬於當前的查詢
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Page 是列表與搜索接口共用的分頁回應。
+// NextCursor 為空表示已經是最後一頁；Total 是符合過濾條件的總筆數，與游標無關。
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      int    `json:"total"`
+}
+
+// 各資源的分頁類型
+type (
+	FlightPage    = Page[Flight]
+	BookingPage   = Page[*Booking]
+	PassengerPage = Page[*Passenger]
+)
+
+// EncodeCursor 將上一頁最後一筆記錄的排序鍵編碼成不透明的游標
+func EncodeCursor(key interface{}) string {
+	raw, err := json.Marshal(key)
+	if err != nil {
+		// 游標鍵都是固定的結構體，不會編碼失敗
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor 將游標解碼到 key，格式錯誤時返回 ErrInvalidCursor
+func DecodeCursor(cursor string, key interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(raw, key); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+// FlightCursor 是航班搜索的分頁位置。Key 是所選排序方式的主排序值
+// （價格、飛行秒數或可售座位數），按出發時間排序時不使用。
+type FlightCursor struct {
+	SortBy        string    `json:"s"`
+	Key           float64   `json:"k,omitempty"`
+	DepartureTime time.Time `json:"t"`
+	ID            int       `json:"id"`
+}
+
+// BookingCursor 是預訂列表的分頁位置，預訂按預訂時間倒序排列
+type BookingCursor struct {
+	BookingTime time.Time `json:"t"`
+	ID          int       `json:"id"`
+}
+
+// PassengerCursor 是乘客列表的分頁位置，乘客按姓、名排列
+type PassengerCursor struct {
+	LastName  string `json:"l"`
+	FirstName string `json:"f"`
+	ID        int    `json:"id"`
+}
@@ -56,6 +56,10 @@ type PassengerFilter struct {
 	MinTotalFlights     int       `json:"min_total_flights,omitempty"`
 	MinTotalSpent       float64   `json:"min_total_spent,omitempty"`
 	LastFlightAfter     time.Time `json:"last_flight_after,omitempty"`
+
+	// 分頁：Cursor 是上一頁回應的 next_cursor
+	Cursor   string `json:"cursor,omitempty"`
+	PageSize int    `json:"page_size,omitempty"`
 }
 
 // 用於更新操作的結構
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// 付款狀態。pending 表示尚未得到網關的明確結果，可以用同一冪等鍵重試
+const (
+	PaymentPending           = "pending"
+	PaymentAuthorized        = "authorized"
+	PaymentCaptured          = "captured"
+	PaymentPartiallyRefunded = "partially_refunded"
+	PaymentRefunded          = "refunded"
+	PaymentVoided            = "voided"
+	PaymentDeclined          = "declined"
+)
+
+// paymentTransitions 是每個狀態允許轉換到的狀態，未列出的狀態是終態
+var paymentTransitions = map[string][]string{
+	PaymentPending:           {PaymentAuthorized, PaymentDeclined},
+	PaymentAuthorized:        {PaymentCaptured, PaymentVoided},
+	PaymentCaptured:          {PaymentPartiallyRefunded, PaymentRefunded},
+	PaymentPartiallyRefunded: {PaymentPartiallyRefunded, PaymentRefunded},
+}
+
+// Payment 是預訂的一筆付款，同一預訂可以有多筆（例如被拒後換卡重試），但最多一筆被扣款
+type Payment struct {
+	ID        int `json:"id"`
+	BookingID int `json:"booking_id"`
+	// IdempotencyKey 由客戶端生成，同一個鍵的重試對應同一筆付款
+	IdempotencyKey string `json:"idempotency_key"`
+	Status         string `json:"status"`
+	Amount         Money  `json:"amount"`
+	Refunded       Money  `json:"refunded"`
+	// GatewayReference 是網關的授權 ID，後續的扣款、撤銷與退款都以它為準
+	GatewayReference string    `json:"gateway_reference,omitempty"`
+	FailureReason    string    `json:"failure_reason,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// 退款狀態。退款在事務中記錄為 pending，事務提交後請求網關，成功後變為 completed
+const (
+	RefundPending   = "pending"
+	RefundCompleted = "completed"
+)
+
+// Refund 是從一筆已扣款的付款中退回的金額
+type Refund struct {
+	ID        int `json:"id"`
+	PaymentID int `json:"payment_id"`
+	BookingID int `json:"booking_id"`
+	// IdempotencyKey 由退款用途、預訂與付款得出，請求網關時使用，重試不會重複退款
+	IdempotencyKey string    `json:"idempotency_key"`
+	Status         string    `json:"status"`
+	Amount         Money     `json:"amount"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// PaymentRequest 是為預訂付款的請求，PaymentMethod 是支付方式的令牌
+type PaymentRequest struct {
+	IdempotencyKey string `json:"idempotency_key"`
+	PaymentMethod  string `json:"payment_method"`
+}
+
+// CanTransition 返回付款能否從當前狀態轉換到 status
+func (p *Payment) CanTransition(status string) bool {
+	return slices.Contains(paymentTransitions[p.Status], status)
+}
+
+// Collected 返回付款是否已經扣款（包括之後部分或全部退款的）
+func (p *Payment) Collected() bool {
+	switch p.Status {
+	case PaymentCaptured, PaymentPartiallyRefunded, PaymentRefunded:
+		return true
+	default:
+		return false
+	}
+}
+
+// Refundable 返回扣款中還可以退回的金額
+func (p *Payment) Refundable() float64 {
+	if !p.Collected() {
+		return 0
+	}
+	return math.Round((p.Amount.Amount-p.Refunded.Amount)*100) / 100
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+)
+
+// 搜索任務狀態
+const (
+	SearchJobQueued  = "queued"
+	SearchJobRunning = "running"
+	SearchJobDone    = "done"
+	SearchJobFailed  = "failed"
+)
+
+// SearchJob 記錄一次異步航班搜索：請求 ID 隨任務一起經過隊列，
+// 結果與錯誤都寫回同一筆任務，客戶端以請求 ID 查詢
+type SearchJob struct {
+	ID      string        `json:"id"`
+	Request SearchRequest `json:"request"`
+	Status  string        `json:"status"`
+	Error   string        `json:"error,omitempty"`
+	// Legs 按航程順序列出各段可選的航班，往返時依次為去程與回程，可任意組合
+	Legs []SearchLegResult `json:"legs,omitempty"`
+	// Results 與第一段航程的航班相同，保留給只支持單程的客戶端
+	Results   []Flight  `json:"results,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IsFinished 表示任務已經結束（成功或失敗），不會再改變狀態
+func (j *SearchJob) IsFinished() bool {
+	return j.Status == SearchJobDone || j.Status == SearchJobFailed
+}
+
+// SearchLegResult 是單段航程的搜索結果，只包含符合過濾條件且可容納整個乘客團體的航班
+type SearchLegResult struct {
+	SearchLeg
+	Flights []Flight `json:"flights"`
+	// Itineraries 是轉機行程，只在 Filters.MaxStops 大於 0 時返回
+	Itineraries []Itinerary `json:"itineraries,omitempty"`
+	// NextCursor 用於取得本段航班的下一頁，Total 是本段符合條件的航班總數
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      int    `json:"total"`
+	// Truncated 表示按價格過濾或排序時符合其他條件的航班超過上限，結果只涵蓋出發最早的一部分航班，
+	// Total 與游標也只描述這部分；縮小出發時間窗口或指定承運人可以取得完整的結果
+	Truncated bool `json:"truncated,omitempty"`
+}
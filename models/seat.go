@@ -0,0 +1,133 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// 乘客的座位偏好，對應 Passenger.SeatPreference
+const (
+	SeatPreferenceWindow       = "window"
+	SeatPreferenceAisle        = "aisle"
+	SeatPreferenceExtraLegroom = "extra_legroom"
+)
+
+// Seat 是機型座位配置中的一個座位
+type Seat struct {
+	Number       string `json:"number"` // 例如：12A
+	Row          int    `json:"row"`
+	Letter       string `json:"letter"`
+	Cabin        string `json:"cabin"`
+	Window       bool   `json:"window"`
+	Aisle        bool   `json:"aisle"`
+	ExtraLegroom bool   `json:"extra_legroom"`
+}
+
+// Matches 返回座位是否符合 NormalizeSeatPreference 處理後的偏好，沒有偏好時總是符合
+func (s Seat) Matches(preference string) bool {
+	switch preference {
+	case SeatPreferenceWindow:
+		return s.Window
+	case SeatPreferenceAisle:
+		return s.Aisle
+	case SeatPreferenceExtraLegroom:
+		return s.ExtraLegroom
+	default:
+		return true
+	}
+}
+
+// CabinLayout 是機型中一個艙位的連續座位排，每排的座位字母相同；
+// Windows 與 Aisles 是靠窗、靠走道座位的字母
+type CabinLayout struct {
+	Cabin            string
+	FirstRow         int
+	LastRow          int
+	Letters          string
+	Windows          string
+	Aisles           string
+	ExtraLegroomRows []int
+}
+
+// SeatLayout 是一個機型的座位配置，艙位按排號由前到後排列
+type SeatLayout struct {
+	AircraftType string
+	Cabins       []CabinLayout
+}
+
+// Seats 展開座位配置，座位按排號與字母排列，與座位圖的順序一致
+func (l SeatLayout) Seats() []Seat {
+	var seats []Seat
+	for _, cabin := range l.Cabins {
+		for row := cabin.FirstRow; row <= cabin.LastRow; row++ {
+			for _, letter := range cabin.Letters {
+				seats = append(seats, Seat{
+					Number:       fmt.Sprintf("%d%c", row, letter),
+					Row:          row,
+					Letter:       string(letter),
+					Cabin:        cabin.Cabin,
+					Window:       strings.ContainsRune(cabin.Windows, letter),
+					Aisle:        strings.ContainsRune(cabin.Aisles, letter),
+					ExtraLegroom: slices.Contains(cabin.ExtraLegroomRows, row),
+				})
+			}
+		}
+	}
+	return seats
+}
+
+// StandardSeatLayouts 是常見機型的默認座位配置，應用啟動時寫入 aircraft_seats
+var StandardSeatLayouts = []SeatLayout{
+	{
+		AircraftType: "A320",
+		Cabins: []CabinLayout{
+			{Cabin: "business", FirstRow: 1, LastRow: 3, Letters: "ACDF", Windows: "AF", Aisles: "CD"},
+			// 第 12、13 排是緊急出口
+			{Cabin: "economy", FirstRow: 4, LastRow: 30, Letters: "ABCDEF", Windows: "AF", Aisles: "CD", ExtraLegroomRows: []int{12, 13}},
+		},
+	},
+	{
+		AircraftType: "B738",
+		Cabins: []CabinLayout{
+			{Cabin: "business", FirstRow: 1, LastRow: 4, Letters: "ACDF", Windows: "AF", Aisles: "CD"},
+			{Cabin: "economy", FirstRow: 5, LastRow: 32, Letters: "ABCDEF", Windows: "AF", Aisles: "CD", ExtraLegroomRows: []int{15, 16}},
+		},
+	},
+	{
+		AircraftType: "B789",
+		Cabins: []CabinLayout{
+			{Cabin: "business", FirstRow: 1, LastRow: 8, Letters: "ADGK", Windows: "AK", Aisles: "DG"},
+			// 第 20 排是隔板後的第一排，第 31 排是緊急出口
+			{Cabin: "economy", FirstRow: 20, LastRow: 45, Letters: "ABCDEFGHK", Windows: "AK", Aisles: "CDFG", ExtraLegroomRows: []int{20, 31}},
+		},
+	},
+}
+
+// FlightSeat 是航班座位圖中的座位與其當前是否可選
+type FlightSeat struct {
+	Seat
+	Available bool `json:"available"`
+}
+
+// FlightSeatMap 是航班的即時座位圖，座位按排號與字母排列
+type FlightSeatMap struct {
+	FlightID     int          `json:"flight_id"`
+	AircraftType string       `json:"aircraft_type"`
+	Seats        []FlightSeat `json:"seats"`
+}
+
+// NormalizeSeatPreference 將乘客資料中的偏好（例如 "Window"、"Extra Legroom"）轉為 SeatPreference* 常量，
+// 無法識別時返回空字串
+func NormalizeSeatPreference(preference string) string {
+	switch strings.ReplaceAll(strings.ToLower(strings.TrimSpace(preference)), " ", "_") {
+	case SeatPreferenceWindow:
+		return SeatPreferenceWindow
+	case SeatPreferenceAisle:
+		return SeatPreferenceAisle
+	case SeatPreferenceExtraLegroom:
+		return SeatPreferenceExtraLegroom
+	default:
+		return ""
+	}
+}
@@ -3,6 +3,10 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 
 	"airline-booking/models"
 )
@@ -10,12 +14,15 @@ import (
 type BookingRepository interface {
 	CreateBooking(ctx context.Context, booking *models.Booking) error
 	GetBookingByID(ctx context.Context, bookingID int) (*models.Booking, error)
+	// GetBookingByIDForUpdate 以 SELECT ... FOR UPDATE 鎖住預訂，需在事務中使用
+	GetBookingByIDForUpdate(ctx context.Context, bookingID int) (*models.Booking, error)
 	UpdateBooking(ctx context.Context, booking *models.Booking) error
 	DeleteBooking(ctx context.Context, bookingID int) error
 	GetBookingsByPassengerID(ctx context.Context, passengerID int) ([]*models.Booking, error)
 	GetBookingsByFlight(ctx context.Context, flightID int) ([]*models.Booking, error)
 	GetPassengerHistory(ctx context.Context, passengerID int) (*models.PassengerHistory, error)
-	ListBookings(ctx context.Context, filter models.BookingFilter) ([]*models.Booking, error)
+	// ListBookings 返回一頁符合條件的預訂，filter.PageSize 需已由服務填充
+	ListBookings(ctx context.Context, filter models.BookingFilter) (*models.BookingPage, error)
 	GetCurrentBookingTrend(ctx context.Context, flightID int) (models.BookingTrend, error)
 }
 
@@ -27,47 +34,429 @@ func NewBookingRepository(db *sql.DB) BookingRepository {
 	return &bookingRepository{db: db}
 }
 
+// bookingColumns 與 scanBooking 的掃描順序一一對應
+const bookingColumns = `
+	id, passenger_id, flight_id, class, seat_number, status,
+	booking_time, check_in_time, has_checked_in,
+	price_amount, price_currency, compensation_amount, compensation_currency,
+	risk_score, is_cheapest_fare, special_requests,
+	baggage_checked_bags, baggage_carry_on_bags, baggage_total_weight, baggage_excess_weight,
+	baggage_excess_charge_amount, baggage_excess_charge_currency,
+	cancellation_time, refund_amount, refund_currency,
+	is_overbooked, upgraded_from, fare_class, created_at, updated_at`
+
 func (r *bookingRepository) CreateBooking(ctx context.Context, booking *models.Booking) error {
-	// TODO: Implement CreateBooking logic
+	query := `
+		INSERT INTO bookings (
+			passenger_id, flight_id, class, seat_number, status,
+			booking_time, check_in_time, has_checked_in,
+			price_amount, price_currency, compensation_amount, compensation_currency,
+			risk_score, is_cheapest_fare, special_requests,
+			baggage_checked_bags, baggage_carry_on_bags, baggage_total_weight, baggage_excess_weight,
+			baggage_excess_charge_amount, baggage_excess_charge_currency,
+			cancellation_time, refund_amount, refund_currency,
+			is_overbooked, upgraded_from, fare_class, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14,
+			$15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29
+		) RETURNING id`
+
+	args, err := bookingArgs(booking)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	args = append(args, now, now)
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&booking.ID); err != nil {
+		return mapSeatViolation(err)
+	}
+
+	booking.CreatedAt = now
+	booking.UpdatedAt = now
 	return nil
 }
 
 func (r *bookingRepository) GetBookingByID(ctx context.Context, bookingID int) (*models.Booking, error) {
-	// TODO: Implement GetBookingByID logic
-	return nil, nil
+	return r.getBooking(ctx, bookingID, "")
+}
+
+func (r *bookingRepository) GetBookingByIDForUpdate(ctx context.Context, bookingID int) (*models.Booking, error) {
+	return r.getBooking(ctx, bookingID, " FOR UPDATE")
+}
+
+func (r *bookingRepository) getBooking(ctx context.Context, bookingID int, lock string) (*models.Booking, error) {
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE id = $1` + lock
+
+	booking, err := scanBooking(conn(ctx, r.db).QueryRowContext(ctx, query, bookingID))
+	if err == sql.ErrNoRows {
+		return nil, &NotFoundError{Resource: "booking", ID: bookingID}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return booking, nil
 }
 
 func (r *bookingRepository) UpdateBooking(ctx context.Context, booking *models.Booking) error {
-	// TODO: Implement UpdateBooking logic
+	query := `
+		UPDATE bookings SET
+			passenger_id = $2, flight_id = $3, class = $4, seat_number = $5, status = $6,
+			booking_time = $7, check_in_time = $8, has_checked_in = $9,
+			price_amount = $10, price_currency = $11, compensation_amount = $12, compensation_currency = $13,
+			risk_score = $14, is_cheapest_fare = $15, special_requests = $16,
+			baggage_checked_bags = $17, baggage_carry_on_bags = $18, baggage_total_weight = $19,
+			baggage_excess_weight = $20, baggage_excess_charge_amount = $21, baggage_excess_charge_currency = $22,
+			cancellation_time = $23, refund_amount = $24, refund_currency = $25,
+			is_overbooked = $26, upgraded_from = $27, fare_class = $28, updated_at = $29
+		WHERE id = $1`
+
+	args, err := bookingArgs(booking)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	args = append([]interface{}{booking.ID}, args...)
+	args = append(args, now)
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return mapSeatViolation(err)
+	}
+	if err := expectAffected(result, "booking", booking.ID); err != nil {
+		return err
+	}
+
+	booking.UpdatedAt = now
 	return nil
 }
 
 func (r *bookingRepository) DeleteBooking(ctx context.Context, bookingID int) error {
-	// TODO: Implement DeleteBooking logic
-	return nil
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM bookings WHERE id = $1`, bookingID)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result, "booking", bookingID)
 }
 
 func (r *bookingRepository) GetBookingsByPassengerID(ctx context.Context, passengerID int) ([]*models.Booking, error) {
-	// TODO: Implement GetBookingsByPassengerID logic
-	return nil, nil
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE passenger_id = $1 ORDER BY booking_time DESC, id DESC`
+	return r.queryBookings(ctx, query, passengerID)
 }
 
+// GetBookingsByFlight 按預訂時間先後返回航班的所有預訂，超賣處理依賴這個順序
 func (r *bookingRepository) GetBookingsByFlight(ctx context.Context, flightID int) ([]*models.Booking, error) {
-	// TODO: Implement GetBookingsByFlight logic
-	return nil, nil
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE flight_id = $1 ORDER BY booking_time, id`
+	return r.queryBookings(ctx, query, flightID)
 }
 
 func (r *bookingRepository) GetPassengerHistory(ctx context.Context, passengerID int) (*models.PassengerHistory, error) {
-	// TODO: Implement GetPassengerHistory logic
-	return nil, nil
+	history := models.PassengerHistory{PassengerID: passengerID, LastUpdated: time.Now()}
+
+	var tier sql.NullString
+	var lastFlightDate sql.NullTime
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT frequent_flyer_tier, total_flights, total_spent, last_flight_date
+		FROM passengers
+		WHERE id = $1`, passengerID).Scan(&tier, &history.TotalFlights, &history.TotalSpent, &lastFlightDate)
+	if err == sql.ErrNoRows {
+		return nil, &NotFoundError{Resource: "passenger", ID: passengerID}
+	}
+	if err != nil {
+		return nil, err
+	}
+	history.IsFrequentFlyer = tier.String != ""
+	history.LastFlightDate = lastFlightDate.Time
+
+	// 從預訂記錄統計取消率、報到率和飛行頻率
+	var total, cancelled, checkedIn int
+	var firstBooking sql.NullTime
+	err = conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE status = 'cancelled'),
+		       COUNT(*) FILTER (WHERE has_checked_in),
+		       MIN(booking_time)
+		FROM bookings
+		WHERE passenger_id = $1 AND status <> 'expired'`, passengerID).Scan(&total, &cancelled, &checkedIn, &firstBooking)
+	if err != nil {
+		return nil, err
+	}
+	if total > 0 {
+		history.CancellationRate = float64(cancelled) / float64(total)
+	}
+	if active := total - cancelled; active > 0 {
+		history.OnTimeCheckInRate = float64(checkedIn) / float64(active)
+		months := time.Since(firstBooking.Time).Hours() / (24 * 30)
+		history.AverageFlightFrequency = float64(active) / max(months, 1)
+	}
+
+	var route sql.NullString
+	err = conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT f.origin || '-' || f.destination AS route
+		FROM bookings b
+		JOIN flights f ON f.id = b.flight_id
+		WHERE b.passenger_id = $1 AND b.status NOT IN ('cancelled', 'expired')
+		GROUP BY route
+		ORDER BY COUNT(*) DESC, route
+		LIMIT 1`, passengerID).Scan(&route)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	history.MostFrequentRoute = route.String
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT special_requests
+		FROM bookings
+		WHERE passenger_id = $1 AND special_requests IS NOT NULL AND special_requests != ''`, passengerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var raw sql.NullString
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		requests, err := decodeSpecialRequests(raw)
+		if err != nil {
+			return nil, err
+		}
+		for _, req := range requests {
+			if !seen[req] {
+				seen[req] = true
+				history.SpecialServiceRequests = append(history.SpecialServiceRequests, req)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &history, nil
 }
 
-func (r *bookingRepository) ListBookings(ctx context.Context, filter models.BookingFilter) ([]*models.Booking, error) {
-	// TODO: Implement ListBookings logic
-	return nil, nil
+func (r *bookingRepository) ListBookings(ctx context.Context, filter models.BookingFilter) (*models.BookingPage, error) {
+	var conditions []string
+	var args []interface{}
+	where := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.PassengerID != 0 {
+		where("passenger_id = $%d", filter.PassengerID)
+	}
+	if filter.FlightID != 0 {
+		where("flight_id = $%d", filter.FlightID)
+	}
+	if filter.Status != "" {
+		where("status = $%d", filter.Status)
+	}
+	if filter.Class != "" {
+		where("class = $%d", filter.Class)
+	}
+	if !filter.DateFrom.IsZero() {
+		where("booking_time >= $%d", filter.DateFrom)
+	}
+	if !filter.DateTo.IsZero() {
+		where("booking_time < $%d", filter.DateTo)
+	}
+	if filter.IsOverbooked != nil {
+		where("is_overbooked = $%d", *filter.IsOverbooked)
+	}
+
+	whereClause := func() string {
+		if len(conditions) == 0 {
+			return ""
+		}
+		return ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+
+	page := &models.BookingPage{}
+	if err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT COUNT(*) FROM bookings`+whereClause(), args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	// 鍵集分頁：預訂按預訂時間倒序排列，從上一頁最後一筆之後開始
+	if filter.Cursor != "" {
+		var cursor models.BookingCursor
+		if err := models.DecodeCursor(filter.Cursor, &cursor); err != nil {
+			return nil, err
+		}
+		args = append(args, cursor.BookingTime, cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(booking_time, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+	args = append(args, filter.PageSize+1)
+
+	query := `SELECT ` + bookingColumns + ` FROM bookings` + whereClause() +
+		fmt.Sprintf(` ORDER BY booking_time DESC, id DESC LIMIT $%d`, len(args))
+
+	bookings, err := r.queryBookings(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	page.Items = bookings
+
+	// 查詢多取了一筆，用來判斷是否還有下一頁
+	if len(page.Items) > filter.PageSize {
+		page.Items = page.Items[:filter.PageSize]
+		last := page.Items[filter.PageSize-1]
+		page.NextCursor = models.EncodeCursor(models.BookingCursor{BookingTime: last.BookingTime, ID: last.ID})
+	}
+
+	return page, nil
 }
 
 func (r *bookingRepository) GetCurrentBookingTrend(ctx context.Context, flightID int) (models.BookingTrend, error) {
-	// TODO: Implement GetCurrentBookingTrend logic
-	return models.BookingTrend{}, nil
+	query := `
+		SELECT COUNT(b.id),
+		       COUNT(b.id) FILTER (WHERE b.status NOT IN ('cancelled', 'expired')),
+		       COALESCE(AVG(b.price_amount) FILTER (WHERE b.status NOT IN ('cancelled', 'expired')), 0),
+		       f.economy_seats_total + f.business_seats_total + f.first_class_seats_total
+		FROM flights f
+		LEFT JOIN bookings b ON b.flight_id = f.id
+		WHERE f.id = $1
+		GROUP BY f.id`
+
+	var trend models.BookingTrend
+	var capacity int
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, flightID).Scan(
+		&trend.TotalBookings, &trend.ConfirmedBookings, &trend.AveragePrice, &capacity,
+	)
+	if err == sql.ErrNoRows {
+		return trend, &NotFoundError{Resource: "flight", ID: flightID}
+	}
+	if err != nil {
+		return trend, err
+	}
+
+	if capacity > 0 {
+		trend.BookingRate = float64(trend.ConfirmedBookings) / float64(capacity)
+	}
+	return trend, nil
+}
+
+func (r *bookingRepository) queryBookings(ctx context.Context, query string, args ...interface{}) ([]*models.Booking, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []*models.Booking
+	for rows.Next() {
+		booking, err := scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, booking)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+// bookingArgs 將預訂攤平成 INSERT/UPDATE 共用的參數（不含 id 與審計時間）
+func bookingArgs(b *models.Booking) ([]interface{}, error) {
+	specialRequests, err := encodeSpecialRequests(b.SpecialRequests)
+	if err != nil {
+		return nil, err
+	}
+
+	compAmount, compCurrency := nullMoney(b.Compensation)
+	excessAmount, excessCurrency := nullMoney(b.BaggageInfo.ExcessCharge)
+	refundAmount, refundCurrency := nullMoney(b.RefundAmount)
+
+	return []interface{}{
+		b.PassengerID, b.FlightID, b.Class, nullString(b.SeatNumber), b.Status,
+		b.BookingTime, nullTime(b.CheckInTime), b.HasCheckedIn,
+		b.Price.Amount, b.Price.Currency, compAmount, compCurrency,
+		b.RiskScore, b.IsCheapestFare, specialRequests,
+		b.BaggageInfo.CheckedBags, b.BaggageInfo.CarryOnBags, b.BaggageInfo.TotalWeight, b.BaggageInfo.ExcessWeight,
+		excessAmount, excessCurrency,
+		nullTime(b.CancellationTime), refundAmount, refundCurrency,
+		b.IsOverbooked, nullString(b.UpgradedFrom), nullString(b.FareClass),
+	}, nil
+}
+
+func scanBooking(row rowScanner) (*models.Booking, error) {
+	var (
+		b                                            models.Booking
+		seatNumber, specialRequests, upgradedFrom    sql.NullString
+		fareClass                                    sql.NullString
+		compCurrency, excessCurrency, refundCurrency sql.NullString
+		checkInTime, cancellationTime                sql.NullTime
+		compAmount, excessAmount, refundAmount       sql.NullFloat64
+		riskScore, totalWeight, excessWeight         sql.NullFloat64
+		checkedBags, carryOnBags                     sql.NullInt64
+		hasCheckedIn, isCheapestFare, isOverbooked   sql.NullBool
+	)
+
+	err := row.Scan(
+		&b.ID, &b.PassengerID, &b.FlightID, &b.Class, &seatNumber, &b.Status,
+		&b.BookingTime, &checkInTime, &hasCheckedIn,
+		&b.Price.Amount, &b.Price.Currency, &compAmount, &compCurrency,
+		&riskScore, &isCheapestFare, &specialRequests,
+		&checkedBags, &carryOnBags, &totalWeight, &excessWeight,
+		&excessAmount, &excessCurrency,
+		&cancellationTime, &refundAmount, &refundCurrency,
+		&isOverbooked, &upgradedFrom, &fareClass, &b.CreatedAt, &b.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	b.SeatNumber = seatNumber.String
+	b.CheckInTime = checkInTime.Time
+	b.HasCheckedIn = hasCheckedIn.Bool
+	b.Compensation = toMoney(compAmount, compCurrency)
+	b.RiskScore = riskScore.Float64
+	b.IsCheapestFare = isCheapestFare.Bool
+	b.BaggageInfo = models.BaggageInfo{
+		CheckedBags:  int(checkedBags.Int64),
+		CarryOnBags:  int(carryOnBags.Int64),
+		TotalWeight:  totalWeight.Float64,
+		ExcessWeight: excessWeight.Float64,
+		ExcessCharge: toMoney(excessAmount, excessCurrency),
+	}
+	b.CancellationTime = cancellationTime.Time
+	b.RefundAmount = toMoney(refundAmount, refundCurrency)
+	b.IsOverbooked = isOverbooked.Bool
+	b.UpgradedFrom = upgradedFrom.String
+	b.FareClass = fareClass.String
+
+	b.SpecialRequests, err = decodeSpecialRequests(specialRequests)
+	if err != nil {
+		return nil, err
+	}
+
+	return &b, nil
+}
+
+// special_requests 欄位以 JSON 陣列的形式存放在 TEXT 欄位中
+func encodeSpecialRequests(requests []string) (sql.NullString, error) {
+	if len(requests) == 0 {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(requests)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func decodeSpecialRequests(raw sql.NullString) ([]string, error) {
+	if !raw.Valid || raw.String == "" {
+		return nil, nil
+	}
+	var requests []string
+	if err := json.Unmarshal([]byte(raw.String), &requests); err != nil {
+		return nil, fmt.Errorf("decode special_requests: %w", err)
+	}
+	return requests, nil
 }
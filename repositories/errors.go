@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrNotFound 是所有「資源不存在」錯誤的哨兵值，呼叫方可以用 errors.Is 判斷
+	ErrNotFound = errors.New("not found")
+	// ErrNoSeatsAvailable 表示艙位已售完（含超賣額度）
+	ErrNoSeatsAvailable = errors.New("no available seats")
+	// ErrInvalidClass 表示艙位類型不是 economy、business 或 first
+	ErrInvalidClass = errors.New("invalid class")
+	// ErrAlreadyExists 表示寫入違反了唯一約束，例如重複的 email
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrSeatTaken 表示座位已被同一航班的其他預訂佔用
+	ErrSeatTaken = errors.New("seat is already taken")
+)
+
+// NotFoundError 描述具體是哪一筆資源不存在
+type NotFoundError struct {
+	Resource string
+	ID       int
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s %d not found", e.Resource, e.ID)
+}
+
+// Is 讓 errors.Is(err, ErrNotFound) 對所有 NotFoundError 成立
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// uniqueViolation 是 PostgreSQL 唯一約束衝突的錯誤碼
+const uniqueViolation = "23505"
+
+// mapUniqueViolation 將唯一約束衝突轉換為 ErrAlreadyExists，其他錯誤原樣返回
+func mapUniqueViolation(err error, resource string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return fmt.Errorf("%s %w (%s)", resource, ErrAlreadyExists, pqErr.Constraint)
+	}
+	return err
+}
+
+// seatIndex 是保證同一航班的座位只屬於一筆未取消預訂的部分唯一索引
+const seatIndex = "idx_bookings_flight_seat"
+
+// mapSeatViolation 將座位唯一索引的衝突轉換為 ErrSeatTaken，其他錯誤原樣返回
+func mapSeatViolation(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == seatIndex {
+		return ErrSeatTaken
+	}
+	return err
+}
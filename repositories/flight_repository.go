@@ -3,19 +3,41 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
 	"airline-booking/logger"
 	"airline-booking/models"
 
+	"github.com/lib/pq"
 	"github.com/opentracing/opentracing-go"
 	"go.uber.org/zap"
 )
 
 type FlightRepository interface {
-	SearchFlights(ctx context.Context, req models.SearchRequest) ([]models.Flight, error)
+	// SearchFlights 返回一頁符合條件的航班，req.PageSize 需已由服務填充。
+	// 售價在定價後才知道，req.Filters.MaxPrice 被忽略，也不支持按價格排序，兩者由服務處理
+	SearchFlights(ctx context.Context, req models.SearchRequest) (*models.FlightPage, error)
+	// SearchItineraries 返回從出發地到目的地、轉機時間與次數符合條件的航段組合，
+	// 每個組合內的航段按時間順序排列；req 需已由服務填充默認值
+	SearchItineraries(ctx context.Context, req models.ItinerarySearchRequest) ([][]models.Flight, error)
+	// GetFlightsByDateRange 返回出發機場當地日期在 from 至 to（含）之間的未取消航班，按出發時間排列。
+	// 低價日曆由服務定價後按天彙總，與搜索返回的票價一致
+	GetFlightsByDateRange(ctx context.Context, origin, destination string, from, to time.Time) ([]models.Flight, error)
 	GetFlightByID(ctx context.Context, flightID int) (*models.Flight, error)
+	// GetFlightByIDForUpdate 以 SELECT ... FOR UPDATE 鎖住航班，需在事務中使用
+	GetFlightByIDForUpdate(ctx context.Context, flightID int) (*models.Flight, error)
 	UpdateFlight(ctx context.Context, flight *models.Flight) error
+	// AdjustBookedSeats 以條件式 UPDATE 原子地增減艙位的已訂座位數，
+	// 增加後超過可售座位（含超賣比例）時返回 ErrNoSeatsAvailable
+	AdjustBookedSeats(ctx context.Context, flightID int, class string, delta int) error
+	// GetFareClasses 返回多個航班的票價艙等，以航班 ID 為鍵，每個航班內按艙位、票價由低到高排列；
+	// 沒有定義票價艙等的航班不在結果中
+	GetFareClasses(ctx context.Context, flightIDs []int) (map[int][]models.FareClass, error)
+	// AdjustFareClassSeats 原子地增減票價艙等的已售座位數，增加後超過配額時返回 ErrNoSeatsAvailable。
+	// 航班沒有定義該艙等時（按默認票價等級售出的預訂）不做任何事
+	AdjustFareClassSeats(ctx context.Context, flightID int, code string, delta int) error
 	GetHistoricalNoShowRate(ctx context.Context, route string, dayOfWeek time.Weekday) (models.HistoricalData, error)
 }
 
@@ -27,7 +49,7 @@ func NewFlightRepository(db *sql.DB) FlightRepository {
 	return &flightRepository{db: db}
 }
 
-func (r *flightRepository) SearchFlights(ctx context.Context, req models.SearchRequest) ([]models.Flight, error) {
+func (r *flightRepository) SearchFlights(ctx context.Context, req models.SearchRequest) (*models.FlightPage, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "FlightRepository.SearchFlights")
 	defer span.Finish()
 
@@ -39,17 +61,22 @@ func (r *flightRepository) SearchFlights(ctx context.Context, req models.SearchR
 			zap.String("destination", req.Destination))
 	}()
 
-	offset := (req.Page - 1) * req.PageSize
-	query := `
-		SELECT id, origin, destination, departure_time, price, available_seats, total_seats
-		FROM flights
-		WHERE origin = $1 AND destination = $2 AND DATE(departure_time) = $3
-		ORDER BY departure_time
-		LIMIT $4 OFFSET $5
-	`
+	q, err := buildSearchQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.FlightPage{}
+	if err := conn(ctx, r.db).QueryRowContext(ctx, q.count, q.countArgs...).Scan(&page.Total); err != nil {
+		logger.LogWithTracing(ctx, "Failed to count flights",
+			zap.Error(err),
+			zap.String("origin", req.Origin),
+			zap.String("destination", req.Destination))
+		return nil, err
+	}
 
 	// 使用 context 來執行查詢
-	rows, err := r.db.QueryContext(ctx, query, req.Origin, req.Destination, req.Date, req.PageSize, offset)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q.query, q.args...)
 	if err != nil {
 		logger.LogWithTracing(ctx, "Failed to execute flight search query",
 			zap.Error(err),
@@ -59,72 +86,568 @@ func (r *flightRepository) SearchFlights(ctx context.Context, req models.SearchR
 	}
 	defer rows.Close()
 
-	var flights []models.Flight
 	for rows.Next() {
-		var f models.Flight
-		err := rows.Scan(&f.ID, &f.Origin, &f.Destination, &f.DepartureTime, &f.Price, &f.AvailableSeats, &f.TotalSeats)
+		f, err := scanFlight(rows)
 		if err != nil {
 			logger.LogWithTracing(ctx, "Failed to scan flight row", zap.Error(err))
 			return nil, err
 		}
-		flights = append(flights, f)
+		page.Items = append(page.Items, *f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	span.SetTag("flights.count", len(flights))
+	// 查詢多取了一筆，用來判斷是否還有下一頁
+	if len(page.Items) > req.PageSize {
+		page.Items = page.Items[:req.PageSize]
+		page.NextCursor = models.EncodeCursor(flightCursor(page.Items[req.PageSize-1], q.sortBy, q.cabin))
+	}
+
+	span.SetTag("flights.count", len(page.Items))
 
-	return flights, nil
+	return page, nil
 }
 
-func (r *flightRepository) GetFlightByID(ctx context.Context, flightID int) (*models.Flight, error) {
-	// 實現獲取單個航班的邏輯
+// availableSeatsJoin 返回以 a.seats 提供艙位可售座位數的 LATERAL 子查詢，
+// 計算方式與 AdjustBookedSeats 的上限一致；prefix 必須來自 seatColumnPrefixes
+func availableSeatsJoin(prefix string) string {
+	return fmt.Sprintf(`CROSS JOIN LATERAL (
+			SELECT GREATEST(FLOOR(f.%[1]s_total * (1 + f.%[1]s_overbooking_ratio)) - f.%[1]s_booked, 0)::int AS seats
+		) a`, prefix)
+}
+
+// flightSortKey 是航班搜索的主排序鍵。所有排序方式最後都以出發時間與 ID 排序，
+// 保證順序穩定，鍵集分頁才不會漏掉或重複航班。
+type flightSortKey struct {
+	// expr 是主排序的 SQL 表達式，按出發時間排序時為空
+	expr string
+	desc bool
+}
+
+// flightSortKeys 同時作為拼接 SQL 的白名單
+var flightSortKeys = map[string]flightSortKey{
+	models.FlightSortDeparture:    {},
+	models.FlightSortDuration:     {expr: "EXTRACT(EPOCH FROM f.arrival_time - f.departure_time)"},
+	models.FlightSortAvailability: {expr: "a.seats", desc: true},
+}
+
+// orderBy 返回完整的 ORDER BY 子句
+func (k flightSortKey) orderBy() string {
+	if k.expr == "" {
+		return "f.departure_time, f.id"
+	}
+	if k.desc {
+		return k.expr + " DESC, f.departure_time, f.id"
+	}
+	return k.expr + ", f.departure_time, f.id"
+}
+
+// after 返回只保留排在游標之後的航班的條件，key、departure、id 是對應參數的佔位符
+func (k flightSortKey) after(key, departure, id string) string {
+	tail := fmt.Sprintf("(f.departure_time, f.id) > (%s::timestamptz, %s::int)", departure, id)
+	if k.expr == "" {
+		return tail
+	}
+	op := ">"
+	if k.desc {
+		op = "<"
+	}
+	return fmt.Sprintf("(%[1]s %[2]s %[3]s::numeric OR (%[1]s = %[3]s::numeric AND %[4]s))", k.expr, op, key, tail)
+}
+
+// flightCursor 以航班的排序鍵生成游標。可售座位由 UpdateAvailability 計算，與 a.seats 一致
+func flightCursor(f models.Flight, sortBy, cabin string) models.FlightCursor {
+	cursor := models.FlightCursor{SortBy: sortBy, DepartureTime: f.DepartureTime, ID: f.ID}
+	switch sortBy {
+	case models.FlightSortDuration:
+		cursor.Key = f.ArrivalTime.Sub(f.DepartureTime).Seconds()
+	case models.FlightSortAvailability:
+		cursor.Key = float64(f.Availability.For(cabin))
+	}
+	return cursor
+}
+
+// flightSearchQuery 是拼接好的航班搜索 SQL。count 只帶過濾條件，
+// countArgs 是 args 的前綴；query 另外帶有游標條件與 LIMIT
+type flightSearchQuery struct {
+	query     string
+	args      []interface{}
+	count     string
+	countArgs []interface{}
+	sortBy    string
+	cabin     string
+}
+
+// buildSearchQuery 根據搜索條件拼接 SQL。價格以外的過濾、排序與分頁都在數據庫中完成，分頁結果才正確。
+func buildSearchQuery(req models.SearchRequest) (*flightSearchQuery, error) {
+	cabin := req.Cabin
+	if cabin == "" {
+		cabin = "economy"
+	}
+	prefix, ok := seatColumnPrefixes[cabin]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidClass, cabin)
+	}
+	sortBy := req.SortBy
+	if sortBy == "" {
+		sortBy = models.FlightSortDeparture
+	}
+	sortKey, ok := flightSortKeys[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort %q", sortBy)
+	}
+
+	args := []interface{}{req.Origin, req.Destination, req.Date.Format("2006-01-02")}
+	// 搜索日期按出發機場的當地日期解釋：將當地日期的起止時間換算成 timestamptz 區間，
+	// 既能處理跨時區，又能使用 (origin, destination, departure_time) 索引
+	conditions := []string{
+		"f.origin = $1",
+		"f.destination = $2",
+		"f.departure_time >= ($3::date)::timestamp AT TIME ZONE o.timezone",
+		"f.departure_time < ($3::date + 1)::timestamp AT TIME ZONE o.timezone",
+		"f.status <> 'cancelled'",
+	}
+	where := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+
+	if seats := req.Passengers.Seated(); seats > 0 {
+		where("a.seats >= $%d", seats)
+	}
+	filters := req.Filters
+	if filters.DepartureAfter != "" {
+		where("(f.departure_time AT TIME ZONE o.timezone)::time >= $%d::time", filters.DepartureAfter)
+	}
+	if filters.DepartureBefore != "" {
+		where("(f.departure_time AT TIME ZONE o.timezone)::time <= $%d::time", filters.DepartureBefore)
+	}
+	if filters.ArrivalAfter != "" {
+		where("(f.arrival_time AT TIME ZONE d.timezone)::time >= $%d::time", filters.ArrivalAfter)
+	}
+	if filters.ArrivalBefore != "" {
+		where("(f.arrival_time AT TIME ZONE d.timezone)::time <= $%d::time", filters.ArrivalBefore)
+	}
+	if len(filters.Carriers) > 0 {
+		where("f.carrier = ANY($%d)", pq.Array(filters.Carriers))
+	}
+
+	from := `
+		FROM flights f
+		JOIN airports o ON o.code = f.origin
+		JOIN airports d ON d.code = f.destination
+		` + availableSeatsJoin(prefix)
+	q := &flightSearchQuery{
+		count:     `SELECT COUNT(*)` + from + "\n\t\tWHERE " + strings.Join(conditions, "\n\t\t  AND "),
+		countArgs: args,
+		sortBy:    sortBy,
+		cabin:     cabin,
+	}
+
+	if req.Cursor != "" {
+		var cursor models.FlightCursor
+		if err := models.DecodeCursor(req.Cursor, &cursor); err != nil {
+			return nil, err
+		}
+		if cursor.SortBy != sortBy {
+			return nil, fmt.Errorf("%w: cursor was issued for sort %q", models.ErrInvalidCursor, cursor.SortBy)
+		}
+		args = append(args, cursor.Key, cursor.DepartureTime, cursor.ID)
+		n := len(args)
+		conditions = append(conditions, sortKey.after(fmt.Sprintf("$%d", n-2), fmt.Sprintf("$%d", n-1), fmt.Sprintf("$%d", n)))
+	}
+
+	args = append(args, req.PageSize+1)
+	q.args = args
+	q.query = fmt.Sprintf(`
+		SELECT %[1]s%[2]s
+		WHERE %[3]s
+		ORDER BY %[4]s
+		LIMIT $%[5]d
+	`, flightColumns, from, strings.Join(conditions, "\n\t\t  AND "), sortKey.orderBy(), len(args))
+
+	return q, nil
+}
+
+// maxItineraryCandidates 限制單次行程搜索從數據庫取出的航段組合數，避免樞紐機場的組合數爆炸
+const maxItineraryCandidates = 500
+
+// itineraryOrders 是候選行程截斷前的排序，與服務層的行程排序鍵一致，截斷時才不會丟掉排在前面的行程。
+// 價格按各航段的基礎票價合計，同時作為拼接 SQL 的白名單
+var itineraryOrders = map[string]string{
+	models.ItinerarySortDuration: "arrival_time - departure_time, price, stops",
+	models.ItinerarySortPrice:    "price, arrival_time - departure_time, stops",
+	models.ItinerarySortStops:    "stops, arrival_time - departure_time, price",
+}
+
+func (r *flightRepository) SearchItineraries(ctx context.Context, req models.ItinerarySearchRequest) ([][]models.Flight, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "FlightRepository.SearchItineraries")
+	defer span.Finish()
+
+	order, ok := itineraryOrders[req.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort %q", req.SortBy)
+	}
+
+	// 遞歸地從出發地當天的航班向外延伸：下一航段從上一航段的到達機場出發，
+	// 起飛時間落在最短與最長轉機時間之間，且不重複經過同一機場
+	query := fmt.Sprintf(`
+		WITH RECURSIVE paths AS (
+			SELECT ARRAY[f.id] AS ids, f.destination::text AS at, f.departure_time, f.arrival_time,
+				   ARRAY[f.origin::text, f.destination::text] AS visited, 0 AS stops, f.price
+			FROM flights f
+			JOIN airports o ON o.code = f.origin
+			WHERE f.origin = $1
+			  AND f.departure_time >= ($3::date)::timestamp AT TIME ZONE o.timezone
+			  AND f.departure_time < ($3::date + 1)::timestamp AT TIME ZONE o.timezone
+			  AND f.status <> 'cancelled'
+			UNION ALL
+			SELECT p.ids || n.id, n.destination::text, p.departure_time, n.arrival_time,
+				   p.visited || n.destination::text, p.stops + 1, p.price + n.price
+			FROM paths p
+			JOIN flights n ON n.origin = p.at
+			WHERE p.stops < $4
+			  AND p.at <> $2
+			  AND n.departure_time >= p.arrival_time + make_interval(mins => $5)
+			  AND n.departure_time <= p.arrival_time + make_interval(mins => $6)
+			  AND n.status <> 'cancelled'
+			  AND NOT (n.destination::text = ANY(p.visited))
+		)
+		SELECT ids FROM paths
+		WHERE at = $2
+		ORDER BY %s, departure_time
+		LIMIT $7
+	`, order)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query,
+		req.Origin, req.Destination, req.Date.Format("2006-01-02"),
+		*req.MaxStops, req.MinConnectionMinutes, req.MaxConnectionMinutes, maxItineraryCandidates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var paths [][]int64
+	seen := make(map[int64]bool)
+	var flightIDs []int64
+	for rows.Next() {
+		var ids pq.Int64Array
+		if err := rows.Scan(&ids); err != nil {
+			return nil, err
+		}
+		paths = append(paths, ids)
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				flightIDs = append(flightIDs, id)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	flights, err := r.getFlightsByIDs(ctx, flightIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	itineraries := make([][]models.Flight, 0, len(paths))
+	for _, ids := range paths {
+		segments := make([]models.Flight, len(ids))
+		for i, id := range ids {
+			segments[i] = flights[id]
+		}
+		itineraries = append(itineraries, segments)
+	}
+
+	span.SetTag("itineraries.count", len(itineraries))
+	return itineraries, nil
+}
+
+// getFlightsByIDs 一次查詢多個航班，返回以 ID 為鍵的映射
+func (r *flightRepository) getFlightsByIDs(ctx context.Context, ids []int64) (map[int64]models.Flight, error) {
 	query := `
-		SELECT id, origin, destination, departure_time, price, 
-			   economy_seats_total, economy_seats_booked, economy_seats_overbooking_ratio,
-			   business_seats_total, business_seats_booked, business_seats_overbooking_ratio,
-			   first_class_seats_total, first_class_seats_booked, first_class_seats_overbooking_ratio
-		FROM flights
-		WHERE id = $1
+		SELECT ` + flightColumns + `
+		FROM flights f
+		JOIN airports o ON o.code = f.origin
+		JOIN airports d ON d.code = f.destination
+		WHERE f.id = ANY($1)
 	`
-	var flight models.Flight
-	err := r.db.QueryRowContext(ctx, query, flightID).Scan(
-		&flight.ID, &flight.Origin, &flight.Destination, &flight.DepartureTime, &flight.Price,
-		&flight.EconomySeats.Total, &flight.EconomySeats.Booked, &flight.EconomySeats.OverbookingRatio,
-		&flight.BusinessSeats.Total, &flight.BusinessSeats.Booked, &flight.BusinessSeats.OverbookingRatio,
-		&flight.FirstClassSeats.Total, &flight.FirstClassSeats.Booked, &flight.FirstClassSeats.OverbookingRatio,
-	)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, pq.Array(ids))
 	if err != nil {
 		return nil, err
 	}
-	return &flight, nil
+	defer rows.Close()
+
+	flights := make(map[int64]models.Flight, len(ids))
+	for rows.Next() {
+		f, err := scanFlight(rows)
+		if err != nil {
+			return nil, err
+		}
+		flights[int64(f.ID)] = *f
+	}
+	return flights, rows.Err()
+}
+
+func (r *flightRepository) GetFlightsByDateRange(ctx context.Context, origin, destination string, from, to time.Time) ([]models.Flight, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "FlightRepository.GetFlightsByDateRange")
+	defer span.Finish()
+
+	query := `
+		SELECT ` + flightColumns + `
+		FROM flights f
+		JOIN airports o ON o.code = f.origin
+		JOIN airports d ON d.code = f.destination
+		WHERE f.origin = $1 AND f.destination = $2
+		  AND f.departure_time >= ($3::date)::timestamp AT TIME ZONE o.timezone
+		  AND f.departure_time < ($4::date + 1)::timestamp AT TIME ZONE o.timezone
+		  AND f.status <> 'cancelled'
+		ORDER BY f.departure_time, f.id
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query,
+		origin, destination, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var flights []models.Flight
+	for rows.Next() {
+		f, err := scanFlight(rows)
+		if err != nil {
+			return nil, err
+		}
+		flights = append(flights, *f)
+	}
+	span.SetTag("flights.count", len(flights))
+	return flights, rows.Err()
+}
+
+func (r *flightRepository) GetFlightByID(ctx context.Context, flightID int) (*models.Flight, error) {
+	return r.getFlight(ctx, flightID, "")
+}
+
+func (r *flightRepository) GetFlightByIDForUpdate(ctx context.Context, flightID int) (*models.Flight, error) {
+	return r.getFlight(ctx, flightID, "FOR UPDATE")
+}
+
+func (r *flightRepository) getFlight(ctx context.Context, flightID int, lock string) (*models.Flight, error) {
+	// FOR UPDATE 只鎖 flights 的資料列，機場資料不需要鎖
+	if lock != "" {
+		lock += " OF f"
+	}
+	query := `
+		SELECT ` + flightColumns + `
+		FROM flights f
+		JOIN airports o ON o.code = f.origin
+		JOIN airports d ON d.code = f.destination
+		WHERE f.id = $1
+	` + lock
+	flight, err := scanFlight(conn(ctx, r.db).QueryRowContext(ctx, query, flightID))
+	if err == sql.ErrNoRows {
+		return nil, &NotFoundError{Resource: "flight", ID: flightID}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return flight, nil
 }
 
 func (r *flightRepository) UpdateFlight(ctx context.Context, flight *models.Flight) error {
-	// 實現更新航班信息的邏輯
 	query := `
 		UPDATE flights
-		SET origin = $2, destination = $3, departure_time = $4, price = $5,
-			economy_seats_total = $6, economy_seats_booked = $7, economy_seats_overbooking_ratio = $8,
-			business_seats_total = $9, business_seats_booked = $10, business_seats_overbooking_ratio = $11,
-			first_class_seats_total = $12, first_class_seats_booked = $13, first_class_seats_overbooking_ratio = $14
+		SET flight_number = $2, carrier = $3, aircraft_type = $4,
+			origin = $5, destination = $6, departure_time = $7, arrival_time = $8, status = $9, price = $10,
+			economy_seats_total = $11, economy_seats_booked = $12, economy_seats_overbooking_ratio = $13,
+			business_seats_total = $14, business_seats_booked = $15, business_seats_overbooking_ratio = $16,
+			first_class_seats_total = $17, first_class_seats_booked = $18, first_class_seats_overbooking_ratio = $19,
+			updated_at = NOW()
 		WHERE id = $1
 	`
-	_, err := r.db.ExecContext(ctx, query,
-		flight.ID, flight.Origin, flight.Destination, flight.DepartureTime, flight.Price,
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		flight.ID, flight.FlightNumber, flight.Carrier, nullString(flight.AircraftType),
+		flight.Origin, flight.Destination, flight.DepartureTime, flight.ArrivalTime, flight.Status, flight.Price,
 		flight.EconomySeats.Total, flight.EconomySeats.Booked, flight.EconomySeats.OverbookingRatio,
 		flight.BusinessSeats.Total, flight.BusinessSeats.Booked, flight.BusinessSeats.OverbookingRatio,
 		flight.FirstClassSeats.Total, flight.FirstClassSeats.Booked, flight.FirstClassSeats.OverbookingRatio,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	return expectAffected(result, "flight", flight.ID)
+}
+
+// seatColumnPrefixes 將艙位類型對應到 flights 表的欄位前綴，同時作為拼接 SQL 的白名單
+var seatColumnPrefixes = map[string]string{
+	"economy":  "economy_seats",
+	"business": "business_seats",
+	"first":    "first_class_seats",
+}
+
+func (r *flightRepository) AdjustBookedSeats(ctx context.Context, flightID int, class string, delta int) error {
+	prefix, ok := seatColumnPrefixes[class]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrInvalidClass, class)
+	}
+
+	// 座位檢查與扣減在同一條 UPDATE 中完成，並發預訂不會丟失更新；
+	// 只有增加座位時才檢查上限，避免超賣比例調低後無法釋放座位
+	query := fmt.Sprintf(`
+		UPDATE flights
+		SET %[1]s_booked = %[1]s_booked + $2, updated_at = NOW()
+		WHERE id = $1
+		  AND %[1]s_booked + $2 >= 0
+		  AND ($2 <= 0 OR %[1]s_booked + $2 <= FLOOR(%[1]s_total * (1 + %[1]s_overbooking_ratio)))
+	`, prefix)
+
+	db := conn(ctx, r.db)
+	result, err := db.ExecContext(ctx, query, flightID, delta)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	// 沒有更新任何資料列：區分航班不存在與座位不足
+	var exists bool
+	if err := db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM flights WHERE id = $1)`, flightID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return &NotFoundError{Resource: "flight", ID: flightID}
+	}
+	if delta > 0 {
+		return ErrNoSeatsAvailable
+	}
+	return fmt.Errorf("flight %d: cannot release %d %s seats", flightID, -delta, class)
+}
+
+func (r *flightRepository) GetFareClasses(ctx context.Context, flightIDs []int) (map[int][]models.FareClass, error) {
+	query := `
+		SELECT id, flight_id, cabin, code, price, seats_total, seats_booked,
+			   refundable, refund_fee, changeable, change_fee
+		FROM fare_classes
+		WHERE flight_id = ANY($1)
+		ORDER BY flight_id, cabin, price, code
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, pq.Array(flightIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	classes := make(map[int][]models.FareClass)
+	for rows.Next() {
+		var c models.FareClass
+		err := rows.Scan(&c.ID, &c.FlightID, &c.Cabin, &c.Code, &c.Price, &c.SeatsTotal, &c.SeatsBooked,
+			&c.Rules.Refundable, &c.Rules.RefundFee, &c.Rules.Changeable, &c.Rules.ChangeFee)
+		if err != nil {
+			return nil, err
+		}
+		classes[c.FlightID] = append(classes[c.FlightID], c)
+	}
+	return classes, rows.Err()
+}
+
+func (r *flightRepository) AdjustFareClassSeats(ctx context.Context, flightID int, code string, delta int) error {
+	// 與 AdjustBookedSeats 一樣只在增加時檢查配額
+	query := `
+		UPDATE fare_classes
+		SET seats_booked = seats_booked + $3
+		WHERE flight_id = $1 AND code = $2
+		  AND seats_booked + $3 >= 0
+		  AND ($3 <= 0 OR seats_booked + $3 <= seats_total)
+	`
+	db := conn(ctx, r.db)
+	result, err := db.ExecContext(ctx, query, flightID, code, delta)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	var exists bool
+	err = db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM fare_classes WHERE flight_id = $1 AND code = $2)`,
+		flightID, code).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+	if delta > 0 {
+		return ErrNoSeatsAvailable
+	}
+	return fmt.Errorf("flight %d: cannot release %d seats of fare class %s", flightID, -delta, code)
 }
 
 func (r *flightRepository) GetHistoricalNoShowRate(ctx context.Context, route string, dayOfWeek time.Weekday) (models.HistoricalData, error) {
-	// 實現獲取歷史 no-show 率的邏輯
+	// 沒有歷史數據的航線返回全零的統計而不是錯誤，超賣比例按默認規則計算
 	query := `
-		SELECT AVG(no_show_rate), AVG(booking_rate)
-		FROM flight_statistics
+		SELECT COALESCE(AVG(average_no_show_rate), 0), COALESCE(AVG(average_booking_rate), 0),
+			   COALESCE(AVG(average_load_factor), 0), COALESCE(AVG(average_yield), 0),
+			   MIN(peak_season_start), MAX(peak_season_end), MAX(last_updated)
+		FROM historical_data
 		WHERE route = $1 AND day_of_week = $2
 	`
-	var data models.HistoricalData
-	err := r.db.QueryRowContext(ctx, query, route, dayOfWeek).Scan(&data.AverageNoShowRate, &data.AverageBookingRate)
-	return data, err
+	data := models.HistoricalData{Route: route, DayOfWeek: dayOfWeek}
+	var peakStart, peakEnd, lastUpdated sql.NullTime
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, route, int(dayOfWeek)).Scan(
+		&data.AverageNoShowRate, &data.AverageBookingRate,
+		&data.AverageLoadFactor, &data.AverageYield,
+		&peakStart, &peakEnd, &lastUpdated,
+	)
+	if err != nil {
+		return models.HistoricalData{}, err
+	}
+	data.PeakSeasonStart = peakStart.Time
+	data.PeakSeasonEnd = peakEnd.Time
+	data.LastUpdated = lastUpdated.Time
+	return data, nil
+}
+
+// flightColumns 是查詢航班時選取的欄位，需與 scanFlight 的順序一致；
+// 查詢需以 f、o、d 分別作為 flights、出發機場與到達機場的別名
+const flightColumns = `
+	f.id, f.flight_number, f.carrier, f.aircraft_type,
+	f.origin, o.timezone, f.destination, d.timezone,
+	f.departure_time, f.arrival_time, f.status, f.price,
+	f.economy_seats_total, f.economy_seats_booked, f.economy_seats_overbooking_ratio,
+	f.business_seats_total, f.business_seats_booked, f.business_seats_overbooking_ratio,
+	f.first_class_seats_total, f.first_class_seats_booked, f.first_class_seats_overbooking_ratio`
+
+// scanFlight 掃描 flightColumns 並計算各艙位的可售座位數
+func scanFlight(row rowScanner) (*models.Flight, error) {
+	var f models.Flight
+	var aircraftType sql.NullString
+	err := row.Scan(
+		&f.ID, &f.FlightNumber, &f.Carrier, &aircraftType,
+		&f.Origin, &f.OriginTimezone, &f.Destination, &f.DestinationTimezone,
+		&f.DepartureTime, &f.ArrivalTime, &f.Status, &f.Price,
+		&f.EconomySeats.Total, &f.EconomySeats.Booked, &f.EconomySeats.OverbookingRatio,
+		&f.BusinessSeats.Total, &f.BusinessSeats.Booked, &f.BusinessSeats.OverbookingRatio,
+		&f.FirstClassSeats.Total, &f.FirstClassSeats.Booked, &f.FirstClassSeats.OverbookingRatio,
+	)
+	if err != nil {
+		return nil, err
+	}
+	f.AircraftType = aircraftType.String
+	f.UpdateAvailability()
+	return &f, nil
 }
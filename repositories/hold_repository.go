@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// HoldRepository 記錄待支付預訂的保留到期時間
+type HoldRepository interface {
+	CreateHold(ctx context.Context, bookingID int, expiresAt time.Time) error
+	// GetHold 返回預訂的保留到期時間，沒有保留時返回 NotFoundError
+	GetHold(ctx context.Context, bookingID int) (time.Time, error)
+	// DeleteHold 刪除預訂的保留，沒有保留時不做任何事
+	DeleteHold(ctx context.Context, bookingID int) error
+	// ListExpiredHolds 返回最多 limit 個在 before 之前到期的保留的預訂 ID，最早到期的排在前面
+	ListExpiredHolds(ctx context.Context, before time.Time, limit int) ([]int, error)
+}
+
+type holdRepository struct {
+	db *sql.DB
+}
+
+func NewHoldRepository(db *sql.DB) HoldRepository {
+	return &holdRepository{db: db}
+}
+
+func (r *holdRepository) CreateHold(ctx context.Context, bookingID int, expiresAt time.Time) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO booking_holds (booking_id, expires_at) VALUES ($1, $2)`, bookingID, expiresAt)
+	return mapUniqueViolation(err, "booking hold")
+}
+
+func (r *holdRepository) GetHold(ctx context.Context, bookingID int) (time.Time, error) {
+	var expiresAt time.Time
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT expires_at FROM booking_holds WHERE booking_id = $1`, bookingID).Scan(&expiresAt)
+	if err == sql.ErrNoRows {
+		return time.Time{}, &NotFoundError{Resource: "booking hold", ID: bookingID}
+	}
+	return expiresAt, err
+}
+
+func (r *holdRepository) DeleteHold(ctx context.Context, bookingID int) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM booking_holds WHERE booking_id = $1`, bookingID)
+	return err
+}
+
+func (r *holdRepository) ListExpiredHolds(ctx context.Context, before time.Time, limit int) ([]int, error) {
+	query := `
+		SELECT booking_id
+		FROM booking_holds
+		WHERE expires_at < $1
+		ORDER BY expires_at
+		LIMIT $2
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookingIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		bookingIDs = append(bookingIDs, id)
+	}
+	return bookingIDs, rows.Err()
+}
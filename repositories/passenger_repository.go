@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 
 	"airline-booking/models"
@@ -13,7 +14,8 @@ type PassengerRepository interface {
 	GetPassengerByID(ctx context.Context, passengerID int) (*models.Passenger, error)
 	UpdatePassenger(ctx context.Context, passenger *models.Passenger) error
 	DeletePassenger(ctx context.Context, passengerID int) error
-	ListPassengers(ctx context.Context, filter models.PassengerFilter) ([]*models.Passenger, error)
+	// ListPassengers 返回一頁符合條件的乘客，filter.PageSize 需已由服務填充
+	ListPassengers(ctx context.Context, filter models.PassengerFilter) (*models.PassengerPage, error)
 	GetPassengerHistory(ctx context.Context, passengerID int) (*models.PassengerHistory, error)
 	UpdateFrequentFlyerPoints(ctx context.Context, passengerID int, pointsToAdd int) error
 }
@@ -40,18 +42,18 @@ func (r *passengerRepository) CreatePassenger(ctx context.Context, passenger *mo
             $16, $17, $18, $19, $20, $21, $22, $23, $24, $25
         ) RETURNING id`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
 		passenger.FirstName, passenger.LastName, passenger.Email, passenger.PhoneNumber,
 		passenger.DateOfBirth, passenger.Nationality, passenger.PassportNumber,
 		passenger.PassportExpiry, passenger.Address, passenger.City, passenger.Country,
-		passenger.PostalCode, passenger.FrequentFlyerNumber, passenger.FrequentFlyerTier,
+		passenger.PostalCode, nullString(passenger.FrequentFlyerNumber), passenger.FrequentFlyerTier,
 		passenger.FrequentFlyerPoints, passenger.SpecialMealPreference, passenger.SeatPreference,
 		passenger.SpecialAssistance, passenger.TotalFlights, passenger.TotalSpent,
-		passenger.LastFlightDate, passenger.MarketingConsent, passenger.PreferredLanguage,
+		nullTime(passenger.LastFlightDate), passenger.MarketingConsent, passenger.PreferredLanguage,
 		time.Now(), time.Now(),
 	).Scan(&passenger.ID)
 
-	return err
+	return mapUniqueViolation(err, "passenger")
 }
 
 func (r *passengerRepository) GetPassengerByID(ctx context.Context, passengerID int) (*models.Passenger, error) {
@@ -65,24 +67,15 @@ func (r *passengerRepository) GetPassengerByID(ctx context.Context, passengerID
         FROM passengers
         WHERE id = $1`
 
-	var passenger models.Passenger
-	err := r.db.QueryRowContext(ctx, query, passengerID).Scan(
-		&passenger.ID, &passenger.FirstName, &passenger.LastName, &passenger.Email,
-		&passenger.PhoneNumber, &passenger.DateOfBirth, &passenger.Nationality,
-		&passenger.PassportNumber, &passenger.PassportExpiry, &passenger.Address,
-		&passenger.City, &passenger.Country, &passenger.PostalCode,
-		&passenger.FrequentFlyerNumber, &passenger.FrequentFlyerTier, &passenger.FrequentFlyerPoints,
-		&passenger.SpecialMealPreference, &passenger.SeatPreference, &passenger.SpecialAssistance,
-		&passenger.TotalFlights, &passenger.TotalSpent, &passenger.LastFlightDate,
-		&passenger.MarketingConsent, &passenger.PreferredLanguage,
-		&passenger.CreatedAt, &passenger.UpdatedAt,
-	)
-
+	passenger, err := scanPassenger(conn(ctx, r.db).QueryRowContext(ctx, query, passengerID))
+	if err == sql.ErrNoRows {
+		return nil, &NotFoundError{Resource: "passenger", ID: passengerID}
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &passenger, nil
+	return passenger, nil
 }
 
 func (r *passengerRepository) UpdatePassenger(ctx context.Context, passenger *models.Passenger) error {
@@ -98,35 +91,34 @@ func (r *passengerRepository) UpdatePassenger(ctx context.Context, passenger *mo
             preferred_language = $24, updated_at = $25
         WHERE id = $1`
 
-	_, err := r.db.ExecContext(ctx, query,
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
 		passenger.ID, passenger.FirstName, passenger.LastName, passenger.Email,
 		passenger.PhoneNumber, passenger.DateOfBirth, passenger.Nationality,
 		passenger.PassportNumber, passenger.PassportExpiry, passenger.Address,
 		passenger.City, passenger.Country, passenger.PostalCode,
-		passenger.FrequentFlyerNumber, passenger.FrequentFlyerTier, passenger.FrequentFlyerPoints,
+		nullString(passenger.FrequentFlyerNumber), passenger.FrequentFlyerTier, passenger.FrequentFlyerPoints,
 		passenger.SpecialMealPreference, passenger.SeatPreference, passenger.SpecialAssistance,
-		passenger.TotalFlights, passenger.TotalSpent, passenger.LastFlightDate,
+		passenger.TotalFlights, passenger.TotalSpent, nullTime(passenger.LastFlightDate),
 		passenger.MarketingConsent, passenger.PreferredLanguage, time.Now(),
 	)
+	if err != nil {
+		return mapUniqueViolation(err, "passenger")
+	}
 
-	return err
+	return expectAffected(result, "passenger", passenger.ID)
 }
 
 func (r *passengerRepository) DeletePassenger(ctx context.Context, passengerID int) error {
 	query := `DELETE FROM passengers WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, passengerID)
-	return err
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, passengerID)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result, "passenger", passengerID)
 }
 
-func (r *passengerRepository) ListPassengers(ctx context.Context, filter models.PassengerFilter) ([]*models.Passenger, error) {
-	query := `
-        SELECT id, first_name, last_name, email, phone_number, date_of_birth, nationality,
-               passport_number, passport_expiry, address, city, country, postal_code,
-               frequent_flyer_number, frequent_flyer_tier, frequent_flyer_points,
-               special_meal_preference, seat_preference, special_assistance,
-               total_flights, total_spent, last_flight_date,
-               marketing_consent, preferred_language, created_at, updated_at
-        FROM passengers
+func (r *passengerRepository) ListPassengers(ctx context.Context, filter models.PassengerFilter) (*models.PassengerPage, error) {
+	conditions := `
         WHERE ($1 = '' OR first_name ILIKE $1 || '%')
           AND ($2 = '' OR last_name ILIKE $2 || '%')
           AND ($3 = '' OR email = $3)
@@ -135,38 +127,68 @@ func (r *passengerRepository) ListPassengers(ctx context.Context, filter models.
           AND ($6 = '' OR frequent_flyer_tier = $6)
           AND (total_flights >= $7)
           AND (total_spent >= $8)
-          AND (last_flight_date >= $9 OR $9 IS NULL)
-        ORDER BY last_name, first_name`
-
-	rows, err := r.db.QueryContext(ctx, query,
+          AND ($9::date IS NULL OR last_flight_date >= $9)`
+	args := []interface{}{
 		filter.FirstName, filter.LastName, filter.Email,
 		filter.FrequentFlyerNumber, filter.Nationality, filter.FrequentFlyerTier,
-		filter.MinTotalFlights, filter.MinTotalSpent, filter.LastFlightAfter,
-	)
+		filter.MinTotalFlights, filter.MinTotalSpent, nullTime(filter.LastFlightAfter),
+	}
+
+	page := &models.PassengerPage{}
+	if err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT COUNT(*) FROM passengers`+conditions, args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	// 鍵集分頁：從上一頁最後一位乘客之後開始，與 ORDER BY 的欄位一致
+	if filter.Cursor != "" {
+		var cursor models.PassengerCursor
+		if err := models.DecodeCursor(filter.Cursor, &cursor); err != nil {
+			return nil, err
+		}
+		args = append(args, cursor.LastName, cursor.FirstName, cursor.ID)
+		conditions += `
+          AND (last_name, first_name, id) > ($10, $11, $12)`
+	}
+	args = append(args, filter.PageSize+1)
+
+	query := `
+        SELECT id, first_name, last_name, email, phone_number, date_of_birth, nationality,
+               passport_number, passport_expiry, address, city, country, postal_code,
+               frequent_flyer_number, frequent_flyer_tier, frequent_flyer_points,
+               special_meal_preference, seat_preference, special_assistance,
+               total_flights, total_spent, last_flight_date,
+               marketing_consent, preferred_language, created_at, updated_at
+        FROM passengers` + conditions + fmt.Sprintf(`
+        ORDER BY last_name, first_name, id
+        LIMIT $%d`, len(args))
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var passengers []*models.Passenger
 	for rows.Next() {
-		var p models.Passenger
-		err := rows.Scan(
-			&p.ID, &p.FirstName, &p.LastName, &p.Email, &p.PhoneNumber,
-			&p.DateOfBirth, &p.Nationality, &p.PassportNumber, &p.PassportExpiry,
-			&p.Address, &p.City, &p.Country, &p.PostalCode,
-			&p.FrequentFlyerNumber, &p.FrequentFlyerTier, &p.FrequentFlyerPoints,
-			&p.SpecialMealPreference, &p.SeatPreference, &p.SpecialAssistance,
-			&p.TotalFlights, &p.TotalSpent, &p.LastFlightDate,
-			&p.MarketingConsent, &p.PreferredLanguage, &p.CreatedAt, &p.UpdatedAt,
-		)
+		p, err := scanPassenger(rows)
 		if err != nil {
 			return nil, err
 		}
-		passengers = append(passengers, &p)
+		page.Items = append(page.Items, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return passengers, nil
+	// 查詢多取了一筆，用來判斷是否還有下一頁
+	if len(page.Items) > filter.PageSize {
+		page.Items = page.Items[:filter.PageSize]
+		last := page.Items[filter.PageSize-1]
+		page.NextCursor = models.EncodeCursor(models.PassengerCursor{
+			LastName: last.LastName, FirstName: last.FirstName, ID: last.ID,
+		})
+	}
+
+	return page, nil
 }
 
 func (r *passengerRepository) GetPassengerHistory(ctx context.Context, passengerID int) (*models.PassengerHistory, error) {
@@ -180,7 +202,7 @@ func (r *passengerRepository) GetPassengerHistory(ctx context.Context, passenger
         WHERE id = $1`
 
 	var history models.PassengerHistory
-	err := r.db.QueryRowContext(ctx, query, passengerID).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, passengerID).Scan(
 		&history.IsFrequentFlyer,
 		&history.TotalFlights,
 		&history.TotalSpent,
@@ -200,6 +222,28 @@ func (r *passengerRepository) UpdateFrequentFlyerPoints(ctx context.Context, pas
         SET frequent_flyer_points = frequent_flyer_points + $2
         WHERE id = $1`
 
-	_, err := r.db.ExecContext(ctx, query, passengerID, pointsToAdd)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, passengerID, pointsToAdd)
 	return err
 }
+
+// scanPassenger 掃描一行乘客資料；常旅客卡號與最後飛行日期可以為 NULL
+func scanPassenger(row rowScanner) (*models.Passenger, error) {
+	var p models.Passenger
+	var frequentFlyerNumber sql.NullString
+	var lastFlightDate sql.NullTime
+	err := row.Scan(
+		&p.ID, &p.FirstName, &p.LastName, &p.Email, &p.PhoneNumber,
+		&p.DateOfBirth, &p.Nationality, &p.PassportNumber, &p.PassportExpiry,
+		&p.Address, &p.City, &p.Country, &p.PostalCode,
+		&frequentFlyerNumber, &p.FrequentFlyerTier, &p.FrequentFlyerPoints,
+		&p.SpecialMealPreference, &p.SeatPreference, &p.SpecialAssistance,
+		&p.TotalFlights, &p.TotalSpent, &lastFlightDate,
+		&p.MarketingConsent, &p.PreferredLanguage, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	p.FrequentFlyerNumber = frequentFlyerNumber.String
+	p.LastFlightDate = lastFlightDate.Time
+	return &p, nil
+}
@@ -0,0 +1,226 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"airline-booking/models"
+)
+
+type PaymentRepository interface {
+	// CreatePayment 寫入新的付款；冪等鍵重複或預訂已有進行中的付款時返回 ErrAlreadyExists
+	CreatePayment(ctx context.Context, payment *models.Payment) error
+	// GetPaymentByIdempotencyKey 返回冪等鍵對應的付款，不存在時返回 ErrNotFound
+	GetPaymentByIdempotencyKey(ctx context.Context, key string) (*models.Payment, error)
+	// GetPaymentsByBooking 按創建先後返回預訂的所有付款
+	GetPaymentsByBooking(ctx context.Context, bookingID int) ([]*models.Payment, error)
+	UpdatePayment(ctx context.Context, payment *models.Payment) error
+	// CreateRefund 寫入新的退款；冪等鍵重複時返回 ErrAlreadyExists
+	CreateRefund(ctx context.Context, refund *models.Refund) error
+	// GetRefundByIdempotencyKey 返回冪等鍵對應的退款，不存在時返回 ErrNotFound
+	GetRefundByIdempotencyKey(ctx context.Context, key string) (*models.Refund, error)
+	// GetRefundsByBooking 按創建先後返回預訂的所有退款
+	GetRefundsByBooking(ctx context.Context, bookingID int) ([]*models.Refund, error)
+	// UpdateRefundStatus 更新退款的狀態
+	UpdateRefundStatus(ctx context.Context, refund *models.Refund) error
+}
+
+type paymentRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentRepository(db *sql.DB) PaymentRepository {
+	return &paymentRepository{db: db}
+}
+
+// paymentColumns 與 scanPayment 的掃描順序一一對應
+const paymentColumns = `
+	id, booking_id, idempotency_key, status, amount, currency, refunded_amount,
+	gateway_reference, failure_reason, created_at, updated_at`
+
+func (r *paymentRepository) CreatePayment(ctx context.Context, payment *models.Payment) error {
+	query := `
+		INSERT INTO payments (
+			booking_id, idempotency_key, status, amount, currency, refunded_amount,
+			gateway_reference, failure_reason, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id`
+
+	now := time.Now()
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		payment.BookingID, payment.IdempotencyKey, payment.Status,
+		payment.Amount.Amount, payment.Amount.Currency, payment.Refunded.Amount,
+		nullString(payment.GatewayReference), nullString(payment.FailureReason), now, now,
+	).Scan(&payment.ID)
+	if err != nil {
+		return mapUniqueViolation(err, "payment")
+	}
+
+	payment.CreatedAt = now
+	payment.UpdatedAt = now
+	return nil
+}
+
+func (r *paymentRepository) GetPaymentByIdempotencyKey(ctx context.Context, key string) (*models.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE idempotency_key = $1`
+
+	payment, err := scanPayment(conn(ctx, r.db).QueryRowContext(ctx, query, key))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("payment with idempotency key %q %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+func (r *paymentRepository) GetPaymentsByBooking(ctx context.Context, bookingID int) ([]*models.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE booking_id = $1 ORDER BY created_at, id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []*models.Payment
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+	return payments, rows.Err()
+}
+
+func (r *paymentRepository) UpdatePayment(ctx context.Context, payment *models.Payment) error {
+	query := `
+		UPDATE payments SET
+			status = $2, refunded_amount = $3, gateway_reference = $4, failure_reason = $5, updated_at = $6
+		WHERE id = $1`
+
+	now := time.Now()
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		payment.ID, payment.Status, payment.Refunded.Amount,
+		nullString(payment.GatewayReference), nullString(payment.FailureReason), now,
+	)
+	if err != nil {
+		return mapUniqueViolation(err, "payment")
+	}
+	if err := expectAffected(result, "payment", payment.ID); err != nil {
+		return err
+	}
+
+	payment.UpdatedAt = now
+	return nil
+}
+
+func scanPayment(row rowScanner) (*models.Payment, error) {
+	var (
+		p                               models.Payment
+		gatewayReference, failureReason sql.NullString
+	)
+
+	err := row.Scan(
+		&p.ID, &p.BookingID, &p.IdempotencyKey, &p.Status, &p.Amount.Amount, &p.Amount.Currency, &p.Refunded.Amount,
+		&gatewayReference, &failureReason, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// 退款與付款同幣別
+	p.Refunded.Currency = p.Amount.Currency
+	p.GatewayReference = gatewayReference.String
+	p.FailureReason = failureReason.String
+	return &p, nil
+}
+
+// refundColumns 與 scanRefund 的掃描順序一一對應
+const refundColumns = `
+	id, payment_id, booking_id, idempotency_key, status, amount, currency, created_at, updated_at`
+
+func (r *paymentRepository) CreateRefund(ctx context.Context, refund *models.Refund) error {
+	query := `
+		INSERT INTO refunds (
+			payment_id, booking_id, idempotency_key, status, amount, currency, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`
+
+	now := time.Now()
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		refund.PaymentID, refund.BookingID, refund.IdempotencyKey, refund.Status,
+		refund.Amount.Amount, refund.Amount.Currency, now, now,
+	).Scan(&refund.ID)
+	if err != nil {
+		return mapUniqueViolation(err, "refund")
+	}
+
+	refund.CreatedAt = now
+	refund.UpdatedAt = now
+	return nil
+}
+
+func (r *paymentRepository) GetRefundByIdempotencyKey(ctx context.Context, key string) (*models.Refund, error) {
+	query := `SELECT ` + refundColumns + ` FROM refunds WHERE idempotency_key = $1`
+
+	refund, err := scanRefund(conn(ctx, r.db).QueryRowContext(ctx, query, key))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("refund with idempotency key %q %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+func (r *paymentRepository) GetRefundsByBooking(ctx context.Context, bookingID int) ([]*models.Refund, error) {
+	query := `SELECT ` + refundColumns + ` FROM refunds WHERE booking_id = $1 ORDER BY created_at, id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refunds []*models.Refund
+	for rows.Next() {
+		refund, err := scanRefund(rows)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, refund)
+	}
+	return refunds, rows.Err()
+}
+
+func (r *paymentRepository) UpdateRefundStatus(ctx context.Context, refund *models.Refund) error {
+	query := `UPDATE refunds SET status = $2, updated_at = $3 WHERE id = $1`
+
+	now := time.Now()
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, refund.ID, refund.Status, now)
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(result, "refund", refund.ID); err != nil {
+		return err
+	}
+
+	refund.UpdatedAt = now
+	return nil
+}
+
+func scanRefund(row rowScanner) (*models.Refund, error) {
+	var r models.Refund
+	err := row.Scan(
+		&r.ID, &r.PaymentID, &r.BookingID, &r.IdempotencyKey, &r.Status,
+		&r.Amount.Amount, &r.Amount.Currency, &r.CreatedAt, &r.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"airline-booking/models"
+
+	"github.com/lib/pq"
+)
+
+type SeatRepository interface {
+	// GetSeatMap 返回機型的全部座位，按排號與字母排列；沒有座位配置的機型返回空列表
+	GetSeatMap(ctx context.Context, aircraftType string) ([]models.Seat, error)
+	// GetOccupiedSeats 返回航班上被未取消預訂佔用的座位號與對應的預訂 ID
+	GetOccupiedSeats(ctx context.Context, flightID int) (map[string]int, error)
+	// SaveSeatMap 寫入機型的座位，已存在的座位號保持不變
+	SaveSeatMap(ctx context.Context, aircraftType string, seats []models.Seat) error
+}
+
+type seatRepository struct {
+	db *sql.DB
+}
+
+func NewSeatRepository(db *sql.DB) SeatRepository {
+	return &seatRepository{db: db}
+}
+
+func (r *seatRepository) GetSeatMap(ctx context.Context, aircraftType string) ([]models.Seat, error) {
+	query := `
+		SELECT seat_number, seat_row, seat_letter, cabin, is_window, is_aisle, extra_legroom
+		FROM aircraft_seats
+		WHERE aircraft_type = $1
+		ORDER BY seat_row, seat_letter
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, aircraftType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var seats []models.Seat
+	for rows.Next() {
+		var s models.Seat
+		if err := rows.Scan(&s.Number, &s.Row, &s.Letter, &s.Cabin, &s.Window, &s.Aisle, &s.ExtraLegroom); err != nil {
+			return nil, err
+		}
+		seats = append(seats, s)
+	}
+	return seats, rows.Err()
+}
+
+func (r *seatRepository) GetOccupiedSeats(ctx context.Context, flightID int) (map[string]int, error) {
+	// 條件與 idx_bookings_flight_seat 一致
+	query := `
+		SELECT seat_number, id
+		FROM bookings
+		WHERE flight_id = $1 AND seat_number IS NOT NULL AND status NOT IN ('cancelled', 'expired')
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, flightID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	occupied := make(map[string]int)
+	for rows.Next() {
+		var seat string
+		var bookingID int
+		if err := rows.Scan(&seat, &bookingID); err != nil {
+			return nil, err
+		}
+		occupied[seat] = bookingID
+	}
+	return occupied, rows.Err()
+}
+
+func (r *seatRepository) SaveSeatMap(ctx context.Context, aircraftType string, seats []models.Seat) error {
+	n := len(seats)
+	numbers, letters, cabins := make([]string, n), make([]string, n), make([]string, n)
+	rows := make([]int64, n)
+	windows, aisles, legroom := make([]bool, n), make([]bool, n), make([]bool, n)
+	for i, s := range seats {
+		numbers[i], rows[i], letters[i], cabins[i] = s.Number, int64(s.Row), s.Letter, s.Cabin
+		windows[i], aisles[i], legroom[i] = s.Window, s.Aisle, s.ExtraLegroom
+	}
+
+	// 一條語句寫入整個機型，運營方調整過的座位不會被覆蓋
+	query := `
+		INSERT INTO aircraft_seats (aircraft_type, seat_number, seat_row, seat_letter, cabin, is_window, is_aisle, extra_legroom)
+		SELECT $1, * FROM unnest($2::text[], $3::int[], $4::text[], $5::text[], $6::bool[], $7::bool[], $8::bool[])
+		ON CONFLICT (aircraft_type, seat_number) DO NOTHING
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, aircraftType,
+		pq.Array(numbers), pq.Array(rows), pq.Array(letters), pq.Array(cabins),
+		pq.Array(windows), pq.Array(aisles), pq.Array(legroom))
+	return err
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"airline-booking/models"
+)
+
+// rowScanner 抽象了 *sql.Row 與 *sql.Rows 的 Scan 方法，讓掃描邏輯可以共用
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// nullTime 將零值時間寫成 NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// nullString 將空字串寫成 NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullMoney 將未設置的金額（金額為 0 且沒有幣別）拆成兩個 NULL 欄位
+func nullMoney(m models.Money) (sql.NullFloat64, sql.NullString) {
+	if m.Amount == 0 && m.Currency == "" {
+		return sql.NullFloat64{}, sql.NullString{}
+	}
+	return sql.NullFloat64{Float64: m.Amount, Valid: true}, sql.NullString{String: m.Currency, Valid: true}
+}
+
+// toMoney 將兩個可能為 NULL 的欄位組合回 Money
+func toMoney(amount sql.NullFloat64, currency sql.NullString) models.Money {
+	return models.Money{Amount: amount.Float64, Currency: currency.String}
+}
+
+// expectAffected 在 UPDATE/DELETE 沒有命中任何資料列時返回 NotFoundError
+func expectAffected(result sql.Result, resource string, id int) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return &NotFoundError{Resource: resource, ID: id}
+	}
+	return nil
+}
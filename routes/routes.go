@@ -7,7 +7,7 @@ import (
 )
 
 // SetupRoutes 配置所有的路由
-func SetupRoutes(r *router.Router, fc *controllers.FlightController) {
+func SetupRoutes(r *router.Router, fc *controllers.FlightController, bc *controllers.BookingController, pc *controllers.PassengerController) {
 	// POST /flights/search: 發起航班搜索
 	// 設計要點：
 	// 1. 異步處理：立即返回請求ID，提高系統響應性和並發處理能力
@@ -22,4 +22,43 @@ func SetupRoutes(r *router.Router, fc *controllers.FlightController) {
 	// 3. 錯誤處理：提供更好的重試機制和錯誤恢復能力
 	r.GET("/flights/results", fc.GetSearchResults)
 
+	// GET /flights/results/stream: 以 server-sent events 推送任務狀態與結果
+	// 適合需要即時結果的客戶端，取代高頻輪詢
+	r.GET("/flights/results/stream", fc.StreamSearchResults)
+
+	// GET /flights/search/stats: 搜索隊列深度與工作池指標
+	r.GET("/flights/search/stats", fc.GetSearchStats)
+
+	// POST /flights/itineraries: 搜索含轉機的行程
+	// 轉機組合由數據庫遞歸查詢生成且受數量上限約束，因此同步返回並緩存結果
+	r.POST("/flights/itineraries", fc.SearchItineraries)
+
+	// GET /flights/calendar: 低價日曆，逐日返回最低價與可售情況
+	r.GET("/flights/calendar", fc.GetFareCalendar)
+
+	// GET /flights/{id}/fare-classes: 航班各艙位的票價艙等、剩餘配額與退改規則
+	r.GET("/flights/{id}/fare-classes", fc.GetFareClasses)
+
+	// GET /flights/{id}/seats: 航班的即時座位圖，選座在創建預訂、修改預訂或登機時進行
+	r.GET("/flights/{id}/seats", fc.GetSeatMap)
+
+	// 預訂：創建（保留座位）、分頁列出、查詢、修改、付款（扣款前確認）、試算取消、取消與辦理登機
+	// 取消與登機使用 POST 子資源而非 DELETE，因為預訂記錄會保留並變更狀態
+	r.POST("/bookings", bc.CreateBooking)
+	r.GET("/bookings", bc.ListBookings)
+	r.GET("/bookings/{id}", bc.GetBooking)
+	r.PATCH("/bookings/{id}", bc.UpdateBooking)
+	r.POST("/bookings/{id}/payments", bc.PayBooking)
+	r.GET("/bookings/{id}/payments", bc.ListPayments)
+	r.GET("/bookings/{id}/cancellation-quote", bc.QuoteCancellation)
+	r.POST("/bookings/{id}/cancel", bc.CancelBooking)
+	r.POST("/bookings/{id}/check-in", bc.CheckIn)
+	r.GET("/passengers/{id}/bookings", bc.ListBookingsByPassenger)
+
+	// 乘客資料：註冊、查詢、部分更新、分頁搜索與歷史統計
+	r.POST("/passengers", pc.RegisterPassenger)
+	r.GET("/passengers", pc.SearchPassengers)
+	r.GET("/passengers/{id}", pc.GetPassenger)
+	r.PATCH("/passengers/{id}", pc.UpdatePassenger)
+	r.GET("/passengers/{id}/history", pc.GetPassengerHistory)
 }
@@ -3,6 +3,8 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"time"
 
 	"airline-booking/logger"
@@ -13,82 +15,131 @@ import (
 )
 
 type BookingService interface {
+	// CreateBooking 創建保留中（held）的預訂並佔用座位，需在 booking.HoldExpiresAt 前呼叫 PayBooking
 	CreateBooking(ctx context.Context, booking *models.Booking) error
 	GetBooking(ctx context.Context, bookingID int) (*models.Booking, error)
-	UpdateBooking(ctx context.Context, booking *models.Booking) error
-	CancelBooking(ctx context.Context, bookingID int) error
-	ListBookingsByPassenger(ctx context.Context, passengerID int) ([]*models.Booking, error)
-	CheckIn(ctx context.Context, bookingID int) error
+	UpdateBooking(ctx context.Context, bookingID int, update models.BookingUpdate) (*models.Booking, error)
+	// CancelBooking 取消預訂並釋放座位，按 RefundPolicy 計算退款，記錄在預訂上並在取消提交後通過支付網關退回，
+	// 同時撤銷尚未扣款的預授權。取消已提交但撤銷或網關退款失敗時返回錯誤，重試取消會重新撤銷與提交退款
+	CancelBooking(ctx context.Context, bookingID int) (*models.Booking, error)
+	// QuoteCancellation 試算現在取消預訂的退款，不修改預訂
+	QuoteCancellation(ctx context.Context, bookingID int) (*models.CancellationQuote, error)
+	// PayBooking 為保留中的預訂付款：預授權票價、確認預訂後扣款。同一個冪等鍵的重試返回同一筆付款；
+	// 預授權期間保留到期或預訂被取消時撤銷預授權並返回對應的錯誤
+	PayBooking(ctx context.Context, bookingID int, req models.PaymentRequest) (*models.Payment, error)
+	// ReleaseExpiredHolds 釋放最多 limit 個已到期保留佔用的座位並撤銷這些預訂的預授權，返回釋放的數量
+	ReleaseExpiredHolds(ctx context.Context, limit int) (int, error)
+	// ListBookings 按過濾條件分頁列出預訂，最新的預訂排在前面
+	ListBookings(ctx context.Context, filter models.BookingFilter) (*models.BookingPage, error)
+	// CheckIn 辦理登機；seatNumber 不為空時換到該座位，預訂還沒有座位時按乘客偏好自動分配
+	CheckIn(ctx context.Context, bookingID int, seatNumber string) error
 }
 
 type bookingService struct {
+	txManager          repositories.TxManager
 	bookingRepo        repositories.BookingRepository
 	flightRepo         repositories.FlightRepository
 	passengerRepo      repositories.PassengerRepository
+	holdRepo           repositories.HoldRepository
 	overbookingService OverbookingService
 	notifyService      NotificationService
+	searchCache        SearchCache
+	pricing            PricingService
+	seats              SeatService
+	payments           PaymentService
+	refunds            RefundPolicy
+	holdTTL            time.Duration
 }
 
 func NewBookingService(
+	txManager repositories.TxManager,
 	bookingRepo repositories.BookingRepository,
 	flightRepo repositories.FlightRepository,
 	passengerRepo repositories.PassengerRepository,
+	holdRepo repositories.HoldRepository,
 	overbookingService OverbookingService,
 	notifyService NotificationService,
+	searchCache SearchCache,
+	pricing PricingService,
+	seats SeatService,
+	payments PaymentService,
+	refunds RefundPolicy,
+	holdTTL time.Duration,
 ) BookingService {
 	return &bookingService{
+		txManager:          txManager,
 		bookingRepo:        bookingRepo,
 		flightRepo:         flightRepo,
 		passengerRepo:      passengerRepo,
+		holdRepo:           holdRepo,
 		overbookingService: overbookingService,
 		notifyService:      notifyService,
+		searchCache:        searchCache,
+		pricing:            pricing,
+		seats:              seats,
+		payments:           payments,
+		refunds:            refunds,
+		holdTTL:            holdTTL,
 	}
 }
 
 func (s *bookingService) CreateBooking(ctx context.Context, booking *models.Booking) error {
-	// 檢查航班是否存在並有足夠的座位
+	if !isValidClass(booking.Class) {
+		return &ValidationError{Field: "class", Message: "must be one of economy, business, first"}
+	}
+
+	// 檢查航班是否存在
 	flight, err := s.flightRepo.GetFlightByID(ctx, booking.FlightID)
 	if err != nil {
 		return err
 	}
 
-	availableSeats := s.calculateAvailableSeats(flight, booking.Class)
-	if availableSeats <= 0 {
-		return errors.New("no available seats")
-	}
-
 	// 檢查乘客是否存在
-	_, err = s.passengerRepo.GetPassengerByID(ctx, booking.PassengerID)
+	passenger, err := s.passengerRepo.GetPassengerByID(ctx, booking.PassengerID)
 	if err != nil {
 		return err
 	}
 
-	// 創建預訂
-	booking.Status = "confirmed"
-	booking.BookingTime = time.Now()
-
-	err = s.bookingRepo.CreateBooking(ctx, booking)
+	// 按下單時的艙等配額與載客率定價；客戶端可以指定票價艙等，否則售出最便宜的可售艙等
+	fare, err := s.pricing.Quote(ctx, flight, booking.Class, booking.FareClass)
 	if err != nil {
 		return err
 	}
 
-	// 更新航班座位信息
-	switch booking.Class {
-	case "economy":
-		flight.EconomySeats.Booked++
-	case "business":
-		flight.BusinessSeats.Booked++
-	case "first":
-		flight.FirstClassSeats.Booked++
-	}
-	err = s.flightRepo.UpdateFlight(ctx, flight)
+	// 座位先保留 holdTTL，預授權成功後由 PayBooking 確認，逾期由 ReleaseExpiredHolds 釋放
+	booking.Status = "held"
+	booking.BookingTime = time.Now()
+	booking.HoldExpiresAt = booking.BookingTime.Add(s.holdTTL)
+	booking.Price = fare.Price
+	booking.FareClass = fare.FareClass
+	booking.IsCheapestFare = fare.Lowest
+
+	// 佔用座位與寫入預訂在同一個事務中完成：
+	// 座位由條件式 UPDATE 扣減，座位不足或寫入失敗時整個事務回滾。
+	// 條件式 UPDATE 同時鎖住航班，同一航班的選座因此依次進行
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.flightRepo.AdjustBookedSeats(ctx, booking.FlightID, booking.Class, 1); err != nil {
+			return err
+		}
+		// 報價之後艙等配額可能已被並發的預訂售完，由條件式 UPDATE 把關
+		if err := s.flightRepo.AdjustFareClassSeats(ctx, booking.FlightID, booking.FareClass, 1); err != nil {
+			return err
+		}
+		if err := s.seats.AssignSeat(ctx, flight, booking, passenger.SeatPreference); err != nil {
+			return err
+		}
+		if err := s.bookingRepo.CreateBooking(ctx, booking); err != nil {
+			return err
+		}
+		return s.holdRepo.CreateHold(ctx, booking.ID, booking.HoldExpiresAt)
+	})
 	if err != nil {
-		// 如果更新失敗，需要回滾預訂
-		s.bookingRepo.DeleteBooking(ctx, booking.ID)
 		return err
 	}
+	s.searchCache.InvalidateFlight(ctx, booking.FlightID)
 
 	// 評估風險並設置風險分數
+	booking.Flight = flight
 	riskScore, err := s.overbookingService.AssessRisk(ctx, booking)
 	if err != nil {
 		logger.Error("Failed to assess booking risk", zap.Error(err), zap.Int("bookingID", booking.ID))
@@ -97,63 +148,93 @@ func (s *bookingService) CreateBooking(ctx context.Context, booking *models.Book
 		s.bookingRepo.UpdateBooking(ctx, booking)
 	}
 
-	// 發送預訂確認通知
-	s.notifyService.NotifyPassenger(ctx, booking, "Your booking has been confirmed.")
+	// 確認通知在支付成功後發送
+	s.notifyService.NotifyPassenger(ctx, booking, fmt.Sprintf(
+		"Your seat is held until %s. Complete payment to confirm your booking.",
+		booking.HoldExpiresAt.UTC().Format(time.RFC1123)))
 
 	return nil
 }
 
 func (s *bookingService) GetBooking(ctx context.Context, bookingID int) (*models.Booking, error) {
-	return s.bookingRepo.GetBookingByID(ctx, bookingID)
+	booking, err := s.bookingRepo.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loadHold(ctx, booking); err != nil {
+		return nil, err
+	}
+	return booking, nil
 }
 
-func (s *bookingService) UpdateBooking(ctx context.Context, booking *models.Booking) error {
-	existingBooking, err := s.bookingRepo.GetBookingByID(ctx, booking.ID)
+// loadHold 為保留中的預訂填入保留到期時間
+func (s *bookingService) loadHold(ctx context.Context, booking *models.Booking) error {
+	if booking.Status != "held" {
+		return nil
+	}
+	expiresAt, err := s.holdRepo.GetHold(ctx, booking.ID)
 	if err != nil {
 		return err
 	}
+	booking.HoldExpiresAt = expiresAt
+	return nil
+}
+
+func (s *bookingService) UpdateBooking(ctx context.Context, bookingID int, update models.BookingUpdate) (*models.Booking, error) {
+	if update.Class != nil && !isValidClass(*update.Class) {
+		return nil, &ValidationError{Field: "class", Message: "must be one of economy, business, first"}
+	}
+	// 狀態變更會牽涉座位與通知，必須走取消、登機等專用流程
+	if update.Status != nil {
+		return nil, &ValidationError{Field: "status", Message: "cannot be changed directly; use cancel or check-in"}
+	}
 
-	// 檢查是否需要更改座位類型
-	if existingBooking.Class != booking.Class {
-		flight, err := s.flightRepo.GetFlightByID(ctx, booking.FlightID)
+	var booking *models.Booking
+	var seatsChanged bool
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		// 鎖住預訂，避免並發修改同一筆預訂時重複調整座位
+		existingBooking, err := s.bookingRepo.GetBookingByIDForUpdate(ctx, bookingID)
 		if err != nil {
 			return err
 		}
-
-		availableSeats := s.calculateAvailableSeats(flight, booking.Class)
-		if availableSeats <= 0 {
-			return errors.New("no available seats in the new class")
+		if existingBooking.Status == "cancelled" {
+			return ErrBookingAlreadyCancelled
 		}
-
-		// 更新航班座位信息
-		switch existingBooking.Class {
-		case "economy":
-			flight.EconomySeats.Booked--
-		case "business":
-			flight.BusinessSeats.Booked--
-		case "first":
-			flight.FirstClassSeats.Booked--
+		if existingBooking.Status == "expired" {
+			return ErrHoldExpired
 		}
+		if err := s.loadHold(ctx, existingBooking); err != nil {
+			return err
+		}
+
+		booking = applyBookingUpdate(existingBooking, update)
 
-		switch booking.Class {
-		case "economy":
-			flight.EconomySeats.Booked++
-		case "business":
-			flight.BusinessSeats.Booked++
-		case "first":
-			flight.FirstClassSeats.Booked++
+		// 檢查是否需要更改座位類型：先佔用新艙位，再釋放原艙位
+		if existingBooking.Class != booking.Class {
+			if err := s.changeCabin(ctx, existingBooking, booking); err != nil {
+				return err
+			}
+			seatsChanged = true
 		}
 
-		err = s.flightRepo.UpdateFlight(ctx, flight)
-		if err != nil {
-			return err
+		// 指定了新座位或換了艙位時重新選座；換艙位但沒有指定座位時在新艙位自動分配
+		if update.SeatNumber != nil || existingBooking.Class != booking.Class {
+			if update.SeatNumber == nil {
+				booking.SeatNumber = ""
+			}
+			if err := s.assignSeat(ctx, booking); err != nil {
+				return err
+			}
 		}
-	}
 
-	// 更新預訂
-	err = s.bookingRepo.UpdateBooking(ctx, booking)
+		// 更新預訂
+		return s.bookingRepo.UpdateBooking(ctx, booking)
+	})
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if seatsChanged {
+		s.searchCache.InvalidateFlight(ctx, booking.FlightID)
 	}
 
 	// 重新評估風險
@@ -168,66 +249,400 @@ func (s *bookingService) UpdateBooking(ctx context.Context, booking *models.Book
 	// 發送更新通知
 	s.notifyService.NotifyPassenger(ctx, booking, "Your booking has been updated.")
 
-	return nil
+	return booking, nil
 }
 
-func (s *bookingService) CancelBooking(ctx context.Context, bookingID int) error {
-	booking, err := s.bookingRepo.GetBookingByID(ctx, bookingID)
+// changeCabin 把預訂改到 updated.Class 艙位：按原艙等的改簽規則檢查並收取改簽費，
+// 以新艙位最便宜的可售艙等重新定價，並轉移艙位座位與艙等配額。需在事務中呼叫
+func (s *bookingService) changeCabin(ctx context.Context, existing, updated *models.Booking) error {
+	flight, err := s.flightRepo.GetFlightByID(ctx, existing.FlightID)
 	if err != nil {
 		return err
 	}
+	// 按默認票價等級售出的預訂沒有改簽限制
+	var rules models.FareRules
+	if classRules, err := fareRules(ctx, s.flightRepo, existing.FlightID, existing.FareClass); err != nil {
+		return err
+	} else if classRules != nil {
+		rules = *classRules
+		if !rules.Changeable {
+			return ErrFareNotChangeable
+		}
+	}
 
-	flight, err := s.flightRepo.GetFlightByID(ctx, booking.FlightID)
+	fare, err := s.pricing.Quote(ctx, flight, updated.Class, "")
 	if err != nil {
 		return err
 	}
+	updated.FareClass = fare.FareClass
+	updated.IsCheapestFare = fare.Lowest
+	updated.Price = fare.Price
+	updated.Price.Amount += rules.ChangeFee
 
-	// 更新航班座位信息
-	switch booking.Class {
-	case "economy":
-		flight.EconomySeats.Booked--
-	case "business":
-		flight.BusinessSeats.Booked--
-	case "first":
-		flight.FirstClassSeats.Booked--
+	if err := s.flightRepo.AdjustBookedSeats(ctx, existing.FlightID, updated.Class, 1); err != nil {
+		return err
+	}
+	if err := s.flightRepo.AdjustFareClassSeats(ctx, existing.FlightID, updated.FareClass, 1); err != nil {
+		return err
+	}
+	if err := s.flightRepo.AdjustBookedSeats(ctx, existing.FlightID, existing.Class, -1); err != nil {
+		return err
 	}
+	return s.flightRepo.AdjustFareClassSeats(ctx, existing.FlightID, existing.FareClass, -1)
+}
 
-	err = s.flightRepo.UpdateFlight(ctx, flight)
+// fareRules 返回航班上票價艙等 code 的退改規則；按默認票價等級售出時沒有對應的艙等，返回 nil
+func fareRules(ctx context.Context, flightRepo repositories.FlightRepository, flightID int, code string) (*models.FareRules, error) {
+	classes, err := flightRepo.GetFareClasses(ctx, []int{flightID})
 	if err != nil {
-		return err
+		return nil, err
+	}
+	i := slices.IndexFunc(classes[flightID], func(c models.FareClass) bool { return c.Code == code })
+	if i < 0 {
+		return nil, nil
 	}
+	return &classes[flightID][i].Rules, nil
+}
+
+func (s *bookingService) CancelBooking(ctx context.Context, bookingID int) (*models.Booking, error) {
+	var booking *models.Booking
+	var quote *models.CancellationQuote
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		booking, err = s.bookingRepo.GetBookingByIDForUpdate(ctx, bookingID)
+		if err != nil {
+			return err
+		}
+		if booking.Status == "cancelled" {
+			return ErrBookingAlreadyCancelled
+		}
+		// 過期的保留已經釋放了座位，不能再次釋放
+		if booking.Status == "expired" {
+			return ErrHoldExpired
+		}
+
+		quote, err = s.refunds.Quote(ctx, booking)
+		if err != nil {
+			return err
+		}
+
+		if err := s.releaseInventory(ctx, booking); err != nil {
+			return err
+		}
+
+		// 取消預訂；付過款的預訂記錄退款金額，不退款時記錄為 0
+		booking.Status = "cancelled"
+		booking.CancellationTime = quote.QuotedAt
+		if quote.Paid.Amount > 0 {
+			booking.RefundAmount = quote.Refund
+		}
+		if err := s.bookingRepo.UpdateBooking(ctx, booking); err != nil {
+			return err
+		}
 
-	// 取消預訂
-	booking.Status = "cancelled"
-	err = s.bookingRepo.UpdateBooking(ctx, booking)
+		// 事務中只記錄待退款，提交後才請求網關：事務失敗時不會已經退出款項，
+		// 退款的冪等鍵由預訂與付款得出，重試不會重複退款
+		if quote.Refund.Amount > 0 {
+			if _, err := s.payments.RequestRefund(ctx, booking.ID, quote.Refund.Amount, cancellationRefund); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, ErrBookingAlreadyCancelled) {
+		return s.resumeCancellation(ctx, booking, err)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
+	s.searchCache.InvalidateFlight(ctx, booking.FlightID)
 
-	// 發送取消通知
-	s.notifyService.NotifyPassenger(ctx, booking, "Your booking has been cancelled.")
+	// 取消已經生效；尚未扣款的預授權不計入退款，直接撤銷。
+	// 撤銷或網關退款失敗時返回錯誤，重試取消會重新撤銷與提交
+	if _, err := s.voidAuthorizations(ctx, booking.ID); err != nil {
+		return nil, err
+	}
+	if quote.Refund.Amount > 0 {
+		if _, err := s.payments.SubmitRefunds(ctx, booking.ID); err != nil {
+			return nil, err
+		}
+	}
+	s.notifyCancelled(ctx, booking)
+	return booking, nil
+}
 
-	return nil
+// cancellationRefund 是取消預訂的退款用途，用於得出退款的冪等鍵
+const cancellationRefund = "cancellation"
+
+// resumeCancellation 處理對已取消預訂的重試：之前的取消已經提交但預授權沒有撤銷或退款沒有完成時，
+// 重新撤銷與提交退款並返回預訂，否則返回 cancelled 錯誤
+func (s *bookingService) resumeCancellation(ctx context.Context, booking *models.Booking, cancelled error) (*models.Booking, error) {
+	voided, err := s.voidAuthorizations(ctx, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	submitted, err := s.payments.SubmitRefunds(ctx, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	if voided == 0 && len(submitted) == 0 {
+		return nil, cancelled
+	}
+	s.notifyCancelled(ctx, booking)
+	return booking, nil
 }
 
-func (s *bookingService) ListBookingsByPassenger(ctx context.Context, passengerID int) ([]*models.Booking, error) {
-	return s.bookingRepo.GetBookingsByPassengerID(ctx, passengerID)
+// notifyCancelled 通知乘客預訂已取消以及退款金額
+func (s *bookingService) notifyCancelled(ctx context.Context, booking *models.Booking) {
+	message := "Your booking has been cancelled."
+	if booking.RefundAmount.Amount > 0 {
+		message = fmt.Sprintf("Your booking has been cancelled. A refund of %.2f %s is on its way.",
+			booking.RefundAmount.Amount, booking.RefundAmount.Currency)
+	}
+	s.notifyService.NotifyPassenger(ctx, booking, message)
 }
 
-func (s *bookingService) CheckIn(ctx context.Context, bookingID int) error {
+func (s *bookingService) QuoteCancellation(ctx context.Context, bookingID int) (*models.CancellationQuote, error) {
 	booking, err := s.bookingRepo.GetBookingByID(ctx, bookingID)
 	if err != nil {
+		return nil, err
+	}
+	switch booking.Status {
+	case "cancelled":
+		return nil, ErrBookingAlreadyCancelled
+	case "expired":
+		return nil, ErrHoldExpired
+	}
+	return s.refunds.Quote(ctx, booking)
+}
+
+// confirmBooking 在預授權成功後確認保留中的預訂；已確認的預訂原樣返回，便於付款重試。
+// 預訂沒有已預授權或已扣款的付款時返回 ErrBookingNotPaid，付款狀態與預訂在同一個事務中檢查
+func (s *bookingService) confirmBooking(ctx context.Context, bookingID int) (*models.Booking, error) {
+	var booking *models.Booking
+	var confirmed bool
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		booking, err = s.bookingRepo.GetBookingByIDForUpdate(ctx, bookingID)
+		if err != nil {
+			return err
+		}
+		switch booking.Status {
+		case "confirmed":
+			return nil
+		case "expired":
+			return ErrHoldExpired
+		case "held":
+		default:
+			return ErrBookingNotHeld
+		}
+
+		// 保留到期但還沒被清理時座位仍在，但已經可能被承諾給其他乘客，不予確認
+		if err := s.loadHold(ctx, booking); err != nil {
+			return err
+		}
+		if time.Now().After(booking.HoldExpiresAt) {
+			return ErrHoldExpired
+		}
+		paid, err := s.hasCollectablePayment(ctx, booking.ID)
+		if err != nil {
+			return err
+		}
+		if !paid {
+			return ErrBookingNotPaid
+		}
+
+		if err := s.holdRepo.DeleteHold(ctx, booking.ID); err != nil {
+			return err
+		}
+		booking.Status = "confirmed"
+		booking.HoldExpiresAt = time.Time{}
+		confirmed = true
+		return s.bookingRepo.UpdateBooking(ctx, booking)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if confirmed {
+		s.notifyService.NotifyPassenger(ctx, booking, "Your booking has been confirmed.")
+	}
+	return booking, nil
+}
+
+// hasCollectablePayment 判斷預訂是否有已預授權或已扣款的付款
+func (s *bookingService) hasCollectablePayment(ctx context.Context, bookingID int) (bool, error) {
+	payments, err := s.payments.ListPayments(ctx, bookingID)
+	if err != nil {
+		return false, err
+	}
+	for _, payment := range payments {
+		if payment.Status == models.PaymentAuthorized || payment.Status == models.PaymentCaptured {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *bookingService) PayBooking(ctx context.Context, bookingID int, req models.PaymentRequest) (*models.Payment, error) {
+	booking, err := s.GetBooking(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	// 已確認的預訂可能是扣款前中斷後的重試，交給冪等鍵判斷；保留到期的預訂不再預授權
+	switch booking.Status {
+	case "held":
+		if time.Now().After(booking.HoldExpiresAt) {
+			return nil, ErrHoldExpired
+		}
+	case "confirmed":
+	case "expired":
+		return nil, ErrHoldExpired
+	default:
+		return nil, ErrBookingAlreadyCancelled
+	}
+
+	payment, err := s.payments.Authorize(ctx, booking, req)
+	if err != nil || payment.Status != models.PaymentAuthorized {
+		return payment, err
+	}
+
+	if _, err := s.confirmBooking(ctx, bookingID); err != nil {
+		// 預授權期間保留到期或預訂被取消，座位已不屬於這筆付款；其他錯誤保留預授權，等待以同一個鍵重試
+		if errors.Is(err, ErrHoldExpired) || errors.Is(err, ErrBookingNotHeld) {
+			if voidErr := s.payments.Void(ctx, payment); voidErr != nil {
+				logger.Error("Failed to void payment authorization", zap.Error(voidErr), zap.Int("paymentID", payment.ID))
+			}
+		}
+		return payment, err
+	}
+
+	if err := s.payments.Capture(ctx, payment); err != nil {
+		return payment, err
+	}
+	return payment, nil
+}
+
+func (s *bookingService) ReleaseExpiredHolds(ctx context.Context, limit int) (int, error) {
+	now := time.Now()
+	bookingIDs, err := s.holdRepo.ListExpiredHolds(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	// 每個保留在獨立的事務中釋放，單筆失敗不影響其餘的保留；
+	// 鎖住預訂後重新檢查狀態，與並發的確認、取消或其他實例的清理互斥
+	released := 0
+	var errs []error
+	for _, bookingID := range bookingIDs {
+		var booking *models.Booking
+		err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			var err error
+			booking, err = s.bookingRepo.GetBookingByIDForUpdate(ctx, bookingID)
+			if err != nil {
+				return err
+			}
+			if booking.Status != "held" {
+				booking = nil
+				return s.holdRepo.DeleteHold(ctx, bookingID)
+			}
+
+			if err := s.releaseInventory(ctx, booking); err != nil {
+				return err
+			}
+			booking.Status = "expired"
+			return s.bookingRepo.UpdateBooking(ctx, booking)
+		})
+		if err != nil {
+			logger.Error("Failed to release expired hold", zap.Error(err), zap.Int("bookingID", bookingID))
+			errs = append(errs, err)
+			continue
+		}
+		if booking == nil {
+			continue
+		}
+
+		released++
+		s.searchCache.InvalidateFlight(ctx, booking.FlightID)
+		if _, err := s.voidAuthorizations(ctx, booking.ID); err != nil {
+			logger.Error("Failed to void payment authorizations of expired booking", zap.Error(err), zap.Int("bookingID", bookingID))
+			errs = append(errs, err)
+		}
+		s.notifyService.NotifyPassenger(ctx, booking, "Your seat hold has expired and the seat has been released.")
+	}
+	return released, errors.Join(errs...)
+}
+
+// voidAuthorizations 撤銷預訂所有尚未扣款的預授權並返回撤銷的數量。過期或取消的預訂不會再扣款，
+// 預授權保留著只會佔用乘客的額度；需在預訂的新狀態提交後呼叫，避免撤銷即將扣款的預授權
+func (s *bookingService) voidAuthorizations(ctx context.Context, bookingID int) (int, error) {
+	payments, err := s.payments.ListPayments(ctx, bookingID)
+	if err != nil {
+		return 0, err
+	}
+	voided := 0
+	var errs []error
+	for _, payment := range payments {
+		if payment.Status != models.PaymentAuthorized {
+			continue
+		}
+		if err := s.payments.Void(ctx, payment); err != nil {
+			errs = append(errs, fmt.Errorf("void payment %d: %w", payment.ID, err))
+			continue
+		}
+		voided++
+	}
+	return voided, errors.Join(errs...)
+}
+
+// releaseInventory 釋放預訂佔用的艙位座位與艙等配額並刪除它的保留，需在事務中呼叫
+func (s *bookingService) releaseInventory(ctx context.Context, booking *models.Booking) error {
+	if err := s.flightRepo.AdjustBookedSeats(ctx, booking.FlightID, booking.Class, -1); err != nil {
 		return err
 	}
+	if err := s.flightRepo.AdjustFareClassSeats(ctx, booking.FlightID, booking.FareClass, -1); err != nil {
+		return err
+	}
+	return s.holdRepo.DeleteHold(ctx, booking.ID)
+}
 
-	if booking.Status != "confirmed" {
-		return errors.New("booking is not in a confirmed state")
+func (s *bookingService) ListBookings(ctx context.Context, filter models.BookingFilter) (*models.BookingPage, error) {
+	if filter.Class != "" && !isValidClass(filter.Class) {
+		return nil, &ValidationError{Field: "class", Message: "must be one of economy, business, first"}
+	}
+	if !filter.DateFrom.IsZero() && !filter.DateTo.IsZero() && !filter.DateFrom.Before(filter.DateTo) {
+		return nil, &ValidationError{Field: "date_to", Message: "must be after date_from"}
+	}
+	if err := normalizePage(filter.Cursor, &filter.PageSize, &models.BookingCursor{}); err != nil {
+		return nil, err
 	}
 
-	booking.HasCheckedIn = true
-	booking.CheckInTime = time.Now()
+	return s.bookingRepo.ListBookings(ctx, filter)
+}
 
-	err = s.bookingRepo.UpdateBooking(ctx, booking)
+func (s *bookingService) CheckIn(ctx context.Context, bookingID int, seatNumber string) error {
+	var booking *models.Booking
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		booking, err = s.bookingRepo.GetBookingByIDForUpdate(ctx, bookingID)
+		if err != nil {
+			return err
+		}
+		if booking.Status != "confirmed" {
+			return ErrBookingNotConfirmed
+		}
+
+		if seatNumber != "" || booking.SeatNumber == "" {
+			booking.SeatNumber = seatNumber
+			if err := s.assignSeat(ctx, booking); err != nil {
+				return err
+			}
+		}
+
+		booking.HasCheckedIn = true
+		booking.CheckInTime = time.Now()
+		return s.bookingRepo.UpdateBooking(ctx, booking)
+	})
 	if err != nil {
 		return err
 	}
@@ -247,15 +662,47 @@ func (s *bookingService) CheckIn(ctx context.Context, bookingID int) error {
 	return nil
 }
 
-func (s *bookingService) calculateAvailableSeats(flight *models.Flight, class string) int {
+// assignSeat 鎖住預訂的航班後為預訂選座，booking.SeatNumber 為空時按乘客的座位偏好自動分配。需在事務中呼叫
+func (s *bookingService) assignSeat(ctx context.Context, booking *models.Booking) error {
+	flight, err := s.flightRepo.GetFlightByIDForUpdate(ctx, booking.FlightID)
+	if err != nil {
+		return err
+	}
+
+	var preference string
+	if booking.SeatNumber == "" {
+		passenger, err := s.passengerRepo.GetPassengerByID(ctx, booking.PassengerID)
+		if err != nil {
+			return err
+		}
+		preference = passenger.SeatPreference
+	}
+	return s.seats.AssignSeat(ctx, flight, booking, preference)
+}
+
+// applyBookingUpdate 返回套用了部分更新後的預訂副本，不修改原預訂
+func applyBookingUpdate(existing *models.Booking, update models.BookingUpdate) *models.Booking {
+	booking := *existing
+	if update.Class != nil {
+		booking.Class = *update.Class
+	}
+	if update.SeatNumber != nil {
+		booking.SeatNumber = *update.SeatNumber
+	}
+	if update.SpecialRequests != nil {
+		booking.SpecialRequests = update.SpecialRequests
+	}
+	if update.BaggageInfo != nil {
+		booking.BaggageInfo = *update.BaggageInfo
+	}
+	return &booking
+}
+
+func isValidClass(class string) bool {
 	switch class {
-	case "economy":
-		return int(float64(flight.EconomySeats.Total)*(1+flight.EconomySeats.OverbookingRatio)) - flight.EconomySeats.Booked
-	case "business":
-		return int(float64(flight.BusinessSeats.Total)*(1+flight.BusinessSeats.OverbookingRatio)) - flight.BusinessSeats.Booked
-	case "first":
-		return int(float64(flight.FirstClassSeats.Total)*(1+flight.FirstClassSeats.OverbookingRatio)) - flight.FirstClassSeats.Booked
+	case "economy", "business", "first":
+		return true
 	default:
-		return 0
+		return false
 	}
 }
@@ -0,0 +1,610 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"airline-booking/mocks"
+	"airline-booking/models"
+	"airline-booking/repositories"
+	"airline-booking/services"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// bookingHoldTTL 是測試中新預訂保留座位的時間
+const bookingHoldTTL = 15 * time.Minute
+
+type bookingServiceDeps struct {
+	tx          *mocks.MockTxManager
+	bookings    *mocks.MockBookingRepository
+	flights     *mocks.MockFlightRepository
+	passengers  *mocks.MockPassengerRepository
+	holds       *mocks.MockHoldRepository
+	overbooking *mocks.MockOverbookingService
+	notify      *mocks.MockNotificationService
+	cache       *mocks.MockSearchCache
+	pricing     *mocks.MockPricingService
+	seats       *mocks.MockSeatService
+	payments    *mocks.MockPaymentService
+	refunds     *mocks.MockRefundPolicy
+}
+
+func newBookingService(t *testing.T) (services.BookingService, bookingServiceDeps) {
+	ctrl := gomock.NewController(t)
+	deps := bookingServiceDeps{
+		tx:          mocks.NewMockTxManager(ctrl),
+		bookings:    mocks.NewMockBookingRepository(ctrl),
+		flights:     mocks.NewMockFlightRepository(ctrl),
+		passengers:  mocks.NewMockPassengerRepository(ctrl),
+		holds:       mocks.NewMockHoldRepository(ctrl),
+		overbooking: mocks.NewMockOverbookingService(ctrl),
+		notify:      mocks.NewMockNotificationService(ctrl),
+		cache:       mocks.NewMockSearchCache(ctrl),
+		pricing:     mocks.NewMockPricingService(ctrl),
+		seats:       mocks.NewMockSeatService(ctrl),
+		payments:    mocks.NewMockPaymentService(ctrl),
+		refunds:     mocks.NewMockRefundPolicy(ctrl),
+	}
+
+	// 測試中的事務直接執行回呼，並透傳回呼的錯誤
+	deps.tx.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+
+	service := services.NewBookingService(deps.tx, deps.bookings, deps.flights, deps.passengers, deps.holds,
+		deps.overbooking, deps.notify, deps.cache, deps.pricing, deps.seats, deps.payments, deps.refunds, bookingHoldTTL)
+	return service, deps
+}
+
+func TestBookingService_CreateBooking_NoSeats(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+
+	deps.flights.EXPECT().GetFlightByID(gomock.Any(), 7).Return(&models.Flight{ID: 7}, nil)
+	deps.passengers.EXPECT().GetPassengerByID(gomock.Any(), 3).Return(&models.Passenger{ID: 3}, nil)
+	deps.pricing.EXPECT().Quote(gomock.Any(), gomock.Any(), "economy", "").Return(models.Fare{FareClass: "Q"}, nil)
+	deps.flights.EXPECT().AdjustBookedSeats(gomock.Any(), 7, "economy", 1).Return(repositories.ErrNoSeatsAvailable)
+	// 座位不足時不應寫入預訂
+	deps.bookings.EXPECT().CreateBooking(gomock.Any(), gomock.Any()).Times(0)
+
+	err := service.CreateBooking(ctx, &models.Booking{FlightID: 7, PassengerID: 3, Class: "economy"})
+
+	assert.ErrorIs(t, err, repositories.ErrNoSeatsAvailable)
+}
+
+func TestBookingService_CreateBooking(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+	flight := &models.Flight{ID: 7, DepartureTime: time.Now().Add(72 * time.Hour)}
+
+	deps.flights.EXPECT().GetFlightByID(gomock.Any(), 7).Return(flight, nil)
+	deps.passengers.EXPECT().GetPassengerByID(gomock.Any(), 3).Return(&models.Passenger{ID: 3, SeatPreference: "Window"}, nil)
+	deps.pricing.EXPECT().Quote(gomock.Any(), flight, "business", "").
+		Return(models.Fare{FareClass: "J", Price: models.Money{Amount: 412.5, Currency: "USD"}, Lowest: true}, nil)
+	gomock.InOrder(
+		deps.flights.EXPECT().AdjustBookedSeats(gomock.Any(), 7, "business", 1).Return(nil),
+		deps.flights.EXPECT().AdjustFareClassSeats(gomock.Any(), 7, "J", 1).Return(nil),
+		// 在扣減座位鎖住航班之後按乘客偏好選座
+		deps.seats.EXPECT().AssignSeat(gomock.Any(), flight, gomock.Any(), "Window").
+			DoAndReturn(func(_ context.Context, _ *models.Flight, b *models.Booking, _ string) error {
+				b.SeatNumber = "2A"
+				return nil
+			}),
+		deps.bookings.EXPECT().CreateBooking(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, b *models.Booking) error {
+				b.ID = 11
+				return nil
+			}),
+		deps.holds.EXPECT().CreateHold(gomock.Any(), 11, gomock.Any()).Return(nil),
+		// 事務提交後才讓搜索緩存失效
+		deps.cache.EXPECT().InvalidateFlight(gomock.Any(), 7),
+	)
+	deps.overbooking.EXPECT().AssessRisk(gomock.Any(), gomock.Any()).Return(0.3, nil)
+	deps.bookings.EXPECT().UpdateBooking(gomock.Any(), gomock.Any()).Return(nil)
+	deps.notify.EXPECT().NotifyPassenger(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	// 客戶端提交的 is_cheapest_fare 會被票價艙等覆蓋
+	booking := &models.Booking{FlightID: 7, PassengerID: 3, Class: "business", IsCheapestFare: false}
+	err := service.CreateBooking(ctx, booking)
+
+	assert.NoError(t, err)
+	// 支付前只保留座位
+	assert.Equal(t, "held", booking.Status)
+	assert.WithinDuration(t, time.Now().Add(bookingHoldTTL), booking.HoldExpiresAt, time.Minute)
+	assert.Equal(t, 0.3, booking.RiskScore)
+	// 票價與票價艙等以定價服務為準
+	assert.Equal(t, models.Money{Amount: 412.5, Currency: "USD"}, booking.Price)
+	assert.Equal(t, "J", booking.FareClass)
+	assert.True(t, booking.IsCheapestFare)
+	assert.Equal(t, "2A", booking.SeatNumber)
+}
+
+func TestBookingService_CreateBooking_SeatTaken(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+
+	deps.flights.EXPECT().GetFlightByID(gomock.Any(), 7).Return(&models.Flight{ID: 7}, nil)
+	deps.passengers.EXPECT().GetPassengerByID(gomock.Any(), 3).Return(&models.Passenger{ID: 3}, nil)
+	deps.pricing.EXPECT().Quote(gomock.Any(), gomock.Any(), "economy", "").Return(models.Fare{FareClass: "Q"}, nil)
+	deps.flights.EXPECT().AdjustBookedSeats(gomock.Any(), 7, "economy", 1).Return(nil)
+	deps.flights.EXPECT().AdjustFareClassSeats(gomock.Any(), 7, "Q", 1).Return(nil)
+	deps.seats.EXPECT().AssignSeat(gomock.Any(), gomock.Any(), gomock.Any(), "").Return(repositories.ErrSeatTaken)
+	// 選座失敗時整個事務回滾，不寫入預訂
+	deps.bookings.EXPECT().CreateBooking(gomock.Any(), gomock.Any()).Times(0)
+
+	err := service.CreateBooking(ctx, &models.Booking{FlightID: 7, PassengerID: 3, Class: "economy", SeatNumber: "12A"})
+
+	assert.ErrorIs(t, err, repositories.ErrSeatTaken)
+}
+
+func TestBookingService_CancelBooking_AlreadyCancelled(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+
+	deps.bookings.EXPECT().GetBookingByIDForUpdate(gomock.Any(), 11).
+		Return(&models.Booking{ID: 11, FlightID: 7, Class: "economy", Status: "cancelled"}, nil)
+	// 重複取消不能再次釋放座位
+	deps.flights.EXPECT().AdjustBookedSeats(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	// 沒有待撤銷的預授權或待提交的退款
+	deps.payments.EXPECT().ListPayments(gomock.Any(), 11).Return(nil, nil)
+	deps.payments.EXPECT().SubmitRefunds(gomock.Any(), 11).Return(nil, nil)
+
+	_, err := service.CancelBooking(ctx, 11)
+
+	assert.ErrorIs(t, err, services.ErrBookingAlreadyCancelled)
+}
+
+func TestBookingService_UpdateBooking_ChangeClass(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+
+	flight := &models.Flight{ID: 7}
+	deps.bookings.EXPECT().GetBookingByIDForUpdate(gomock.Any(), 11).
+		Return(&models.Booking{ID: 11, PassengerID: 3, FlightID: 7, Class: "economy", FareClass: "M", SeatNumber: "20C", Status: "confirmed"}, nil)
+	deps.flights.EXPECT().GetFlightByID(gomock.Any(), 7).Return(flight, nil)
+	deps.flights.EXPECT().GetFareClasses(gomock.Any(), []int{7}).Return(map[int][]models.FareClass{
+		7: {{FlightID: 7, Cabin: "economy", Code: "M", Rules: models.FareRules{Changeable: true, ChangeFee: 50}}},
+	}, nil)
+	deps.pricing.EXPECT().Quote(gomock.Any(), flight, "business", "").
+		Return(models.Fare{FareClass: "C", Price: models.Money{Amount: 600, Currency: "USD"}}, nil)
+	gomock.InOrder(
+		deps.flights.EXPECT().AdjustBookedSeats(gomock.Any(), 7, "business", 1).Return(nil),
+		deps.flights.EXPECT().AdjustFareClassSeats(gomock.Any(), 7, "C", 1).Return(nil),
+		deps.flights.EXPECT().AdjustBookedSeats(gomock.Any(), 7, "economy", -1).Return(nil),
+		deps.flights.EXPECT().AdjustFareClassSeats(gomock.Any(), 7, "M", -1).Return(nil),
+		deps.flights.EXPECT().GetFlightByIDForUpdate(gomock.Any(), 7).Return(flight, nil),
+		// 原座位在經濟艙，換艙位後按偏好在新艙位重新分配
+		deps.seats.EXPECT().AssignSeat(gomock.Any(), flight, gomock.Any(), "Aisle").
+			DoAndReturn(func(_ context.Context, _ *models.Flight, b *models.Booking, _ string) error {
+				assert.Empty(t, b.SeatNumber)
+				b.SeatNumber = "3D"
+				return nil
+			}),
+		deps.cache.EXPECT().InvalidateFlight(gomock.Any(), 7),
+	)
+	deps.passengers.EXPECT().GetPassengerByID(gomock.Any(), 3).Return(&models.Passenger{ID: 3, SeatPreference: "Aisle"}, nil)
+	deps.bookings.EXPECT().UpdateBooking(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	deps.overbooking.EXPECT().AssessRisk(gomock.Any(), gomock.Any()).Return(0.1, nil)
+	deps.notify.EXPECT().NotifyPassenger(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	class := "business"
+	booking, err := service.UpdateBooking(ctx, 11, models.BookingUpdate{Class: &class})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "business", booking.Class)
+	// 新艙位的票價加上原艙等的改簽費
+	assert.Equal(t, "C", booking.FareClass)
+	assert.Equal(t, 650.0, booking.Price.Amount)
+	assert.Equal(t, "3D", booking.SeatNumber)
+}
+
+func TestBookingService_UpdateBooking_FareNotChangeable(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+
+	deps.bookings.EXPECT().GetBookingByIDForUpdate(gomock.Any(), 11).
+		Return(&models.Booking{ID: 11, FlightID: 7, Class: "economy", FareClass: "Q", Status: "confirmed"}, nil)
+	deps.flights.EXPECT().GetFlightByID(gomock.Any(), 7).Return(&models.Flight{ID: 7}, nil)
+	deps.flights.EXPECT().GetFareClasses(gomock.Any(), []int{7}).Return(map[int][]models.FareClass{
+		7: {{FlightID: 7, Cabin: "economy", Code: "Q"}},
+	}, nil)
+	deps.flights.EXPECT().AdjustBookedSeats(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	class := "business"
+	_, err := service.UpdateBooking(ctx, 11, models.BookingUpdate{Class: &class})
+
+	assert.ErrorIs(t, err, services.ErrFareNotChangeable)
+}
+
+func TestBookingService_UpdateBooking_RejectsStatus(t *testing.T) {
+	service, _ := newBookingService(t)
+
+	status := "cancelled"
+	_, err := service.UpdateBooking(context.Background(), 11, models.BookingUpdate{Status: &status})
+
+	var validationErr *services.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func TestBookingService_ListBookings(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+
+	deps.bookings.EXPECT().ListBookings(gomock.Any(), models.BookingFilter{PassengerID: 3, PageSize: 20}).
+		Return(&models.BookingPage{Items: []*models.Booking{{ID: 9}}, Total: 1}, nil)
+
+	page, err := service.ListBookings(ctx, models.BookingFilter{PassengerID: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, page.Total)
+
+	// 參數錯誤時不查詢數據庫
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for _, filter := range []models.BookingFilter{
+		{Class: "premium"},
+		{DateFrom: day, DateTo: day},
+		{PageSize: 101},
+		{Cursor: "%%%"},
+	} {
+		_, err := service.ListBookings(ctx, filter)
+		var validationErr *services.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	}
+}
+
+func TestBookingService_CheckIn_AssignsSeat(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+
+	flight := &models.Flight{ID: 7}
+	deps.bookings.EXPECT().GetBookingByIDForUpdate(gomock.Any(), 11).
+		Return(&models.Booking{ID: 11, PassengerID: 3, FlightID: 7, Class: "economy", Status: "confirmed"}, nil)
+	deps.flights.EXPECT().GetFlightByIDForUpdate(gomock.Any(), 7).Return(flight, nil)
+	deps.passengers.EXPECT().GetPassengerByID(gomock.Any(), 3).Return(&models.Passenger{ID: 3, SeatPreference: "Extra Legroom"}, nil)
+	deps.seats.EXPECT().AssignSeat(gomock.Any(), flight, gomock.Any(), "Extra Legroom").
+		DoAndReturn(func(_ context.Context, _ *models.Flight, b *models.Booking, _ string) error {
+			b.SeatNumber = "14A"
+			return nil
+		})
+	var saved models.Booking
+	deps.bookings.EXPECT().UpdateBooking(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, b *models.Booking) { saved = *b }).Return(nil).Times(2)
+	deps.overbooking.EXPECT().AssessRisk(gomock.Any(), gomock.Any()).Return(0.1, nil)
+	deps.notify.EXPECT().NotifyPassenger(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	err := service.CheckIn(ctx, 11, "")
+
+	assert.NoError(t, err)
+	assert.True(t, saved.HasCheckedIn)
+	assert.Equal(t, "14A", saved.SeatNumber)
+}
+
+func TestBookingService_CheckIn_KeepsSeat(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+
+	deps.bookings.EXPECT().GetBookingByIDForUpdate(gomock.Any(), 11).
+		Return(&models.Booking{ID: 11, FlightID: 7, Class: "economy", SeatNumber: "20C", Status: "confirmed"}, nil)
+	// 已有座位且沒有要求換座時不重新選座
+	deps.seats.EXPECT().AssignSeat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	deps.bookings.EXPECT().UpdateBooking(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	deps.overbooking.EXPECT().AssessRisk(gomock.Any(), gomock.Any()).Return(0.1, nil)
+	deps.notify.EXPECT().NotifyPassenger(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	err := service.CheckIn(ctx, 11, "")
+
+	assert.NoError(t, err)
+}
+
+func TestBookingService_CancelBooking_Held(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+
+	deps.bookings.EXPECT().GetBookingByIDForUpdate(gomock.Any(), 11).
+		Return(&models.Booking{ID: 11, FlightID: 7, Class: "economy", FareClass: "M", Status: "held"}, nil)
+	// 只有預授權還沒扣款，沒有可退的金額
+	deps.refunds.EXPECT().Quote(gomock.Any(), gomock.Any()).
+		Return(&models.CancellationQuote{BookingID: 11, QuotedAt: time.Now(), Refund: models.Money{Currency: "USD"}}, nil)
+	authorized := &models.Payment{ID: 5, BookingID: 11, Status: models.PaymentAuthorized}
+	gomock.InOrder(
+		deps.flights.EXPECT().AdjustBookedSeats(gomock.Any(), 7, "economy", -1).Return(nil),
+		deps.flights.EXPECT().AdjustFareClassSeats(gomock.Any(), 7, "M", -1).Return(nil),
+		deps.holds.EXPECT().DeleteHold(gomock.Any(), 11).Return(nil),
+		deps.bookings.EXPECT().UpdateBooking(gomock.Any(), gomock.Any()).Return(nil),
+		deps.cache.EXPECT().InvalidateFlight(gomock.Any(), 7),
+		// 取消提交後撤銷預授權
+		deps.payments.EXPECT().ListPayments(gomock.Any(), 11).Return([]*models.Payment{authorized}, nil),
+		deps.payments.EXPECT().Void(gomock.Any(), authorized).Return(nil),
+	)
+	deps.payments.EXPECT().RequestRefund(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	deps.payments.EXPECT().SubmitRefunds(gomock.Any(), gomock.Any()).Times(0)
+	deps.notify.EXPECT().NotifyPassenger(gomock.Any(), gomock.Any(), "Your booking has been cancelled.").Return(nil)
+
+	booking, err := service.CancelBooking(ctx, 11)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "cancelled", booking.Status)
+	assert.False(t, booking.CancellationTime.IsZero())
+	assert.Equal(t, models.Money{}, booking.RefundAmount)
+}
+
+func TestBookingService_CancelBooking_Refund(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+
+	deps.bookings.EXPECT().GetBookingByIDForUpdate(gomock.Any(), 11).
+		Return(&models.Booking{ID: 11, FlightID: 7, Class: "economy", FareClass: "M", Status: "confirmed"}, nil)
+	quote := &models.CancellationQuote{
+		BookingID: 11, QuotedAt: time.Now(), RefundRate: 0.75,
+		Paid:   models.Money{Amount: 300, Currency: "USD"},
+		Refund: models.Money{Amount: 175, Currency: "USD"},
+	}
+	deps.refunds.EXPECT().Quote(gomock.Any(), gomock.Any()).Return(quote, nil)
+	deps.flights.EXPECT().AdjustBookedSeats(gomock.Any(), 7, "economy", -1).Return(nil)
+	deps.flights.EXPECT().AdjustFareClassSeats(gomock.Any(), 7, "M", -1).Return(nil)
+	deps.holds.EXPECT().DeleteHold(gomock.Any(), 11).Return(nil)
+	var saved models.Booking
+	gomock.InOrder(
+		deps.bookings.EXPECT().UpdateBooking(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, b *models.Booking) { saved = *b }).Return(nil),
+		// 事務中只記錄待退款，提交後才通過網關退款
+		deps.payments.EXPECT().RequestRefund(gomock.Any(), 11, 175.0, "cancellation").Return(&models.Refund{}, nil),
+		deps.cache.EXPECT().InvalidateFlight(gomock.Any(), 7),
+		deps.payments.EXPECT().ListPayments(gomock.Any(), 11).
+			Return([]*models.Payment{{ID: 5, BookingID: 11, Status: models.PaymentPartiallyRefunded}}, nil),
+		deps.payments.EXPECT().SubmitRefunds(gomock.Any(), 11).Return([]*models.Refund{{}}, nil),
+	)
+	deps.notify.EXPECT().NotifyPassenger(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	_, err := service.CancelBooking(ctx, 11)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "cancelled", saved.Status)
+	assert.Equal(t, quote.QuotedAt, saved.CancellationTime)
+	assert.Equal(t, models.Money{Amount: 175, Currency: "USD"}, saved.RefundAmount)
+}
+
+func TestBookingService_CancelBooking_RefundFails(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+
+	booking := &models.Booking{ID: 11, FlightID: 7, Class: "economy", FareClass: "M", Status: "confirmed"}
+	deps.bookings.EXPECT().GetBookingByIDForUpdate(gomock.Any(), 11).Return(booking, nil)
+	deps.refunds.EXPECT().Quote(gomock.Any(), gomock.Any()).Return(&models.CancellationQuote{
+		BookingID: 11, Paid: models.Money{Amount: 300, Currency: "USD"}, Refund: models.Money{Amount: 300, Currency: "USD"},
+	}, nil)
+	deps.flights.EXPECT().AdjustBookedSeats(gomock.Any(), 7, "economy", -1).Return(nil)
+	deps.flights.EXPECT().AdjustFareClassSeats(gomock.Any(), 7, "M", -1).Return(nil)
+	deps.holds.EXPECT().DeleteHold(gomock.Any(), 11).Return(nil)
+	deps.bookings.EXPECT().UpdateBooking(gomock.Any(), gomock.Any()).Return(nil)
+	deps.payments.EXPECT().RequestRefund(gomock.Any(), 11, 300.0, "cancellation").Return(&models.Refund{}, nil)
+	deps.cache.EXPECT().InvalidateFlight(gomock.Any(), 7)
+	deps.payments.EXPECT().ListPayments(gomock.Any(), 11).Return(nil, nil).Times(3)
+	// 取消已經提交，網關退款失敗時退款保持 pending，不發送取消通知
+	deps.payments.EXPECT().SubmitRefunds(gomock.Any(), 11).Return(nil, services.ErrPaymentGatewayTimeout)
+
+	_, err := service.CancelBooking(ctx, 11)
+	assert.ErrorIs(t, err, services.ErrPaymentGatewayTimeout)
+
+	// 重試取消只重新提交退款
+	cancelled := &models.Booking{ID: 11, FlightID: 7, Status: "cancelled", RefundAmount: models.Money{Amount: 300, Currency: "USD"}}
+	deps.bookings.EXPECT().GetBookingByIDForUpdate(gomock.Any(), 11).Return(cancelled, nil).Times(2)
+	deps.payments.EXPECT().SubmitRefunds(gomock.Any(), 11).Return([]*models.Refund{{}}, nil)
+	deps.notify.EXPECT().NotifyPassenger(gomock.Any(), gomock.Any(), "Your booking has been cancelled. A refund of 300.00 USD is on its way.").Return(nil)
+
+	resumed, err := service.CancelBooking(ctx, 11)
+	assert.NoError(t, err)
+	assert.Equal(t, "cancelled", resumed.Status)
+
+	// 退款已經完成後再取消返回已取消
+	deps.payments.EXPECT().SubmitRefunds(gomock.Any(), 11).Return(nil, nil)
+	_, err = service.CancelBooking(ctx, 11)
+	assert.ErrorIs(t, err, services.ErrBookingAlreadyCancelled)
+}
+
+func TestBookingService_QuoteCancellation(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+
+	booking := &models.Booking{ID: 11, Status: "confirmed"}
+	deps.bookings.EXPECT().GetBookingByID(gomock.Any(), 11).Return(booking, nil)
+	deps.refunds.EXPECT().Quote(gomock.Any(), booking).Return(&models.CancellationQuote{BookingID: 11}, nil)
+	// 試算不修改預訂
+	deps.bookings.EXPECT().UpdateBooking(gomock.Any(), gomock.Any()).Times(0)
+
+	quote, err := service.QuoteCancellation(ctx, 11)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 11, quote.BookingID)
+
+	deps.bookings.EXPECT().GetBookingByID(gomock.Any(), 12).Return(&models.Booking{ID: 12, Status: "cancelled"}, nil)
+	_, err = service.QuoteCancellation(ctx, 12)
+	assert.ErrorIs(t, err, services.ErrBookingAlreadyCancelled)
+}
+
+func TestBookingService_CancelBooking_Expired(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+
+	deps.bookings.EXPECT().GetBookingByIDForUpdate(gomock.Any(), 11).
+		Return(&models.Booking{ID: 11, FlightID: 7, Class: "economy", Status: "expired"}, nil)
+	// 過期保留的座位已經釋放過
+	deps.flights.EXPECT().AdjustBookedSeats(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := service.CancelBooking(ctx, 11)
+
+	assert.ErrorIs(t, err, services.ErrHoldExpired)
+}
+
+func TestBookingService_ReleaseExpiredHolds(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+
+	deps.holds.EXPECT().ListExpiredHolds(gomock.Any(), gomock.Any(), 10).Return([]int{11, 12}, nil)
+	deps.bookings.EXPECT().GetBookingByIDForUpdate(gomock.Any(), 11).
+		Return(&models.Booking{ID: 11, FlightID: 7, Class: "economy", FareClass: "M", Status: "held"}, nil)
+	deps.flights.EXPECT().AdjustBookedSeats(gomock.Any(), 7, "economy", -1).Return(nil)
+	deps.flights.EXPECT().AdjustFareClassSeats(gomock.Any(), 7, "M", -1).Return(nil)
+	deps.holds.EXPECT().DeleteHold(gomock.Any(), 11).Return(nil)
+	deps.bookings.EXPECT().UpdateBooking(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, b *models.Booking) {
+			assert.Equal(t, "expired", b.Status)
+		}).Return(nil)
+	deps.cache.EXPECT().InvalidateFlight(gomock.Any(), 7)
+	deps.notify.EXPECT().NotifyPassenger(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	// 保留期間留下的預授權在過期後撤銷
+	authorized := &models.Payment{ID: 5, BookingID: 11, Status: models.PaymentAuthorized}
+	deps.payments.EXPECT().ListPayments(gomock.Any(), 11).
+		Return([]*models.Payment{{ID: 4, BookingID: 11, Status: models.PaymentDeclined}, authorized}, nil)
+	deps.payments.EXPECT().Void(gomock.Any(), authorized).Return(nil)
+
+	// 12 在列出之後已被確認，只清理殘留的保留而不釋放座位
+	deps.bookings.EXPECT().GetBookingByIDForUpdate(gomock.Any(), 12).
+		Return(&models.Booking{ID: 12, FlightID: 7, Class: "economy", Status: "confirmed"}, nil)
+	deps.holds.EXPECT().DeleteHold(gomock.Any(), 12).Return(nil)
+
+	released, err := service.ReleaseExpiredHolds(ctx, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, released)
+}
+
+func TestBookingService_PayBooking(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+	req := models.PaymentRequest{IdempotencyKey: "k1", PaymentMethod: "tok_visa"}
+
+	held := &models.Booking{ID: 11, FlightID: 7, Status: "held", Price: models.Money{Amount: 300, Currency: "USD"}}
+	deps.bookings.EXPECT().GetBookingByID(gomock.Any(), 11).Return(held, nil)
+	deps.holds.EXPECT().GetHold(gomock.Any(), 11).Return(time.Now().Add(time.Minute), nil).Times(2)
+	payment := &models.Payment{ID: 5, BookingID: 11, Status: models.PaymentAuthorized}
+	gomock.InOrder(
+		deps.payments.EXPECT().Authorize(gomock.Any(), held, req).Return(payment, nil),
+		// 先確認預訂再扣款
+		deps.bookings.EXPECT().GetBookingByIDForUpdate(gomock.Any(), 11).
+			Return(&models.Booking{ID: 11, FlightID: 7, Status: "held"}, nil),
+		deps.payments.EXPECT().ListPayments(gomock.Any(), 11).Return([]*models.Payment{payment}, nil),
+		deps.holds.EXPECT().DeleteHold(gomock.Any(), 11).Return(nil),
+		deps.bookings.EXPECT().UpdateBooking(gomock.Any(), gomock.Any()).Return(nil),
+		deps.payments.EXPECT().Capture(gomock.Any(), payment).
+			DoAndReturn(func(_ context.Context, p *models.Payment) error {
+				p.Status = models.PaymentCaptured
+				return nil
+			}),
+	)
+	deps.notify.EXPECT().NotifyPassenger(gomock.Any(), gomock.Any(), "Your booking has been confirmed.").Return(nil)
+
+	paid, err := service.PayBooking(ctx, 11, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.PaymentCaptured, paid.Status)
+}
+
+func TestBookingService_PayBooking_Declined(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+
+	deps.bookings.EXPECT().GetBookingByID(gomock.Any(), 11).Return(&models.Booking{ID: 11, Status: "held"}, nil)
+	deps.holds.EXPECT().GetHold(gomock.Any(), 11).Return(time.Now().Add(time.Minute), nil)
+	deps.payments.EXPECT().Authorize(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&models.Payment{ID: 5, Status: models.PaymentDeclined}, &services.PaymentDeclinedError{Reason: "card_declined"})
+	// 被拒時預訂保持保留狀態，可以換卡重試
+	deps.bookings.EXPECT().GetBookingByIDForUpdate(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := service.PayBooking(ctx, 11, models.PaymentRequest{IdempotencyKey: "k1", PaymentMethod: services.FakeCardDeclined})
+
+	assert.ErrorIs(t, err, services.ErrPaymentDeclined)
+}
+
+func TestBookingService_PayBooking_HoldExpiredDuringAuthorization(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+
+	deps.bookings.EXPECT().GetBookingByID(gomock.Any(), 11).Return(&models.Booking{ID: 11, Status: "held"}, nil)
+	deps.holds.EXPECT().GetHold(gomock.Any(), 11).Return(time.Now().Add(time.Minute), nil)
+	payment := &models.Payment{ID: 5, Status: models.PaymentAuthorized}
+	deps.payments.EXPECT().Authorize(gomock.Any(), gomock.Any(), gomock.Any()).Return(payment, nil)
+	// 預授權期間保留已被清理
+	deps.bookings.EXPECT().GetBookingByIDForUpdate(gomock.Any(), 11).Return(&models.Booking{ID: 11, Status: "expired"}, nil)
+	deps.payments.EXPECT().Void(gomock.Any(), payment).Return(nil)
+	deps.payments.EXPECT().Capture(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := service.PayBooking(ctx, 11, models.PaymentRequest{IdempotencyKey: "k1", PaymentMethod: "tok_visa"})
+
+	assert.ErrorIs(t, err, services.ErrHoldExpired)
+}
+
+func TestBookingService_PayBooking_AlreadyConfirmed(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+
+	deps.bookings.EXPECT().GetBookingByID(gomock.Any(), 11).Return(&models.Booking{ID: 11, Status: "confirmed"}, nil)
+	payment := &models.Payment{ID: 5, Status: models.PaymentAuthorized}
+	deps.payments.EXPECT().Authorize(gomock.Any(), gomock.Any(), gomock.Any()).Return(payment, nil)
+	// 扣款前中斷後的重試：預訂已確認，不重複更新與通知，只補扣款
+	deps.bookings.EXPECT().GetBookingByIDForUpdate(gomock.Any(), 11).Return(&models.Booking{ID: 11, Status: "confirmed"}, nil)
+	deps.bookings.EXPECT().UpdateBooking(gomock.Any(), gomock.Any()).Times(0)
+	deps.notify.EXPECT().NotifyPassenger(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	deps.payments.EXPECT().Capture(gomock.Any(), payment).Return(nil)
+
+	_, err := service.PayBooking(ctx, 11, models.PaymentRequest{IdempotencyKey: "k1", PaymentMethod: "tok_visa"})
+
+	assert.NoError(t, err)
+}
+
+func TestBookingService_PayBooking_HoldExpiredBeforeConfirm(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+
+	deps.bookings.EXPECT().GetBookingByID(gomock.Any(), 11).Return(&models.Booking{ID: 11, Status: "held"}, nil)
+	gomock.InOrder(
+		deps.holds.EXPECT().GetHold(gomock.Any(), 11).Return(time.Now().Add(time.Minute), nil),
+		// 已到期但還沒被清理的保留
+		deps.holds.EXPECT().GetHold(gomock.Any(), 11).Return(time.Now().Add(-time.Second), nil),
+	)
+	payment := &models.Payment{ID: 5, Status: models.PaymentAuthorized}
+	deps.payments.EXPECT().Authorize(gomock.Any(), gomock.Any(), gomock.Any()).Return(payment, nil)
+	deps.bookings.EXPECT().GetBookingByIDForUpdate(gomock.Any(), 11).Return(&models.Booking{ID: 11, Status: "held"}, nil)
+	deps.bookings.EXPECT().UpdateBooking(gomock.Any(), gomock.Any()).Times(0)
+	deps.payments.EXPECT().Void(gomock.Any(), payment).Return(nil)
+	deps.payments.EXPECT().Capture(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := service.PayBooking(ctx, 11, models.PaymentRequest{IdempotencyKey: "k1", PaymentMethod: "tok_visa"})
+
+	assert.ErrorIs(t, err, services.ErrHoldExpired)
+}
+
+func TestBookingService_PayBooking_AuthorizationNotRecorded(t *testing.T) {
+	service, deps := newBookingService(t)
+	ctx := context.Background()
+
+	deps.bookings.EXPECT().GetBookingByID(gomock.Any(), 11).Return(&models.Booking{ID: 11, Status: "held"}, nil)
+	deps.holds.EXPECT().GetHold(gomock.Any(), 11).Return(time.Now().Add(time.Minute), nil).Times(2)
+	payment := &models.Payment{ID: 5, Status: models.PaymentAuthorized}
+	deps.payments.EXPECT().Authorize(gomock.Any(), gomock.Any(), gomock.Any()).Return(payment, nil)
+	deps.bookings.EXPECT().GetBookingByIDForUpdate(gomock.Any(), 11).Return(&models.Booking{ID: 11, Status: "held"}, nil)
+	// 事務中看不到有效的付款（例如預授權已被並發撤銷）時不確認預訂
+	deps.payments.EXPECT().ListPayments(gomock.Any(), 11).
+		Return([]*models.Payment{{ID: 5, Status: models.PaymentVoided}}, nil)
+	deps.holds.EXPECT().DeleteHold(gomock.Any(), gomock.Any()).Times(0)
+	deps.bookings.EXPECT().UpdateBooking(gomock.Any(), gomock.Any()).Times(0)
+	deps.payments.EXPECT().Capture(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := service.PayBooking(ctx, 11, models.PaymentRequest{IdempotencyKey: "k1", PaymentMethod: "tok_visa"})
+
+	assert.ErrorIs(t, err, services.ErrBookingNotPaid)
+}
+
+func TestBookingService_PayBooking_Cancelled(t *testing.T) {
+	service, deps := newBookingService(t)
+
+	deps.bookings.EXPECT().GetBookingByID(gomock.Any(), 11).Return(&models.Booking{ID: 11, Status: "cancelled"}, nil)
+	deps.payments.EXPECT().Authorize(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := service.PayBooking(context.Background(), 11, models.PaymentRequest{IdempotencyKey: "k1", PaymentMethod: "tok_visa"})
+
+	assert.ErrorIs(t, err, services.ErrBookingAlreadyCancelled)
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrBookingAlreadyCancelled 表示預訂已經取消，不能重複取消
+	ErrBookingAlreadyCancelled = errors.New("booking is already cancelled")
+	// ErrBookingNotConfirmed 表示預訂不在 confirmed 狀態，無法辦理登機
+	ErrBookingNotConfirmed = errors.New("booking is not in a confirmed state")
+	// ErrFareNotChangeable 表示預訂的票價艙等不允許改簽
+	ErrFareNotChangeable = errors.New("fare class does not allow changes")
+	// ErrBookingNotHeld 表示預訂不在等待支付的保留狀態，無法確認
+	ErrBookingNotHeld = errors.New("booking is not held")
+	// ErrBookingNotPaid 表示預訂沒有已預授權或已扣款的付款，不能確認
+	ErrBookingNotPaid = errors.New("booking has no authorized payment")
+	// ErrHoldExpired 表示預訂的保留已到期，座位已經或即將被釋放
+	ErrHoldExpired = errors.New("booking hold has expired")
+	// ErrPaymentDeclined 是所有付款被拒錯誤的哨兵值，具體原因見 PaymentDeclinedError
+	ErrPaymentDeclined = errors.New("payment declined")
+	// ErrPaymentGatewayTimeout 表示沒有得到支付網關的明確結果，客戶端應以同一個冪等鍵重試
+	ErrPaymentGatewayTimeout = errors.New("payment gateway timed out")
+	// ErrInvalidPaymentTransition 表示付款的當前狀態不允許該操作，例如撤銷已扣款的付款
+	ErrInvalidPaymentTransition = errors.New("invalid payment state transition")
+	// ErrPaymentNotCollected 表示預訂沒有已扣款的付款，無法退款
+	ErrPaymentNotCollected = errors.New("booking has no collected payment")
+)
+
+// ValidationError 表示請求參數不合法，控制器會把它映射為 400
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// PaymentDeclinedError 描述網關拒絕付款的原因
+type PaymentDeclinedError struct {
+	Reason string
+}
+
+func (e *PaymentDeclinedError) Error() string {
+	return fmt.Sprintf("payment declined: %s", e.Reason)
+}
+
+// Is 讓 errors.Is(err, ErrPaymentDeclined) 對所有 PaymentDeclinedError 成立
+func (e *PaymentDeclinedError) Is(target error) bool {
+	return target == ErrPaymentDeclined
+}
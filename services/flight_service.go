@@ -1,140 +1,831 @@
 package services
 
 import (
+	"cmp"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"sync"
+	"slices"
+	"strings"
 	"time"
 
 	"airline-booking/logger"
 	"airline-booking/models"
 	"airline-booking/repositories"
 
-	"github.com/go-redis/redis/v8"
 	"go.uber.org/zap"
 )
 
 type FlightService interface {
 	SearchFlights(ctx context.Context, req models.SearchRequest) (string, error)
-	GetSearchResults(requestID string) ([]models.Flight, error)
-	ProcessSearchRequests()
-	GetSearchQueue() <-chan models.SearchRequest
+	GetSearchJob(ctx context.Context, requestID string) (*models.SearchJob, error)
+	// WaitForSearchJob 等待任務結束，ctx 到期時返回當前狀態與 ctx 的錯誤
+	WaitForSearchJob(ctx context.Context, requestID string) (*models.SearchJob, error)
+	// ProcessSearchJob 執行一個搜索任務，並把狀態、結果或錯誤寫回隊列
+	ProcessSearchJob(ctx context.Context, job *models.SearchJob)
+	GetSearchQueue() SearchQueue
+	// SearchItineraries 同步搜索含轉機的行程，並按 req.SortBy 排序
+	SearchItineraries(ctx context.Context, req models.ItinerarySearchRequest) ([]models.Itinerary, error)
+	// GetFareCalendar 返回日期範圍內逐日的最低價與可售情況
+	GetFareCalendar(ctx context.Context, req models.FareCalendarRequest) (*models.FareCalendar, error)
+	// GetFareClasses 返回航班的票價艙等與剩餘配額，按艙位、票價排列
+	GetFareClasses(ctx context.Context, flightID int) ([]models.FareClass, error)
 }
 
+const (
+	maxSearchLegs      = 6
+	maxSearchPartySize = 9
+
+	defaultFareCalendarFlexDays = 3
+	maxFareCalendarFlexDays     = 15
+
+	defaultItineraryMaxStops    = 1
+	maxItineraryStops           = 2
+	defaultMinConnectionMinutes = 45
+	defaultMaxConnectionMinutes = 6 * 60
+	maxConnectionMinutes        = 24 * 60
+	defaultItineraryLimit       = 20
+	maxItineraryLimit           = 100
+
+	// maxPricedSearchFlights 是按價格過濾或排序時從數據庫取出的航班數上限，超出時結果標記為 Truncated
+	maxPricedSearchFlights = 500
+)
+
 type flightService struct {
-	repo         repositories.FlightRepository
-	redis        *redis.Client
-	searchQueue  chan models.SearchRequest
-	results      map[string][]models.Flight
-	resultsMutex sync.RWMutex
+	repo    repositories.FlightRepository
+	cache   SearchCache
+	queue   SearchQueue
+	pricing PricingService
 }
 
-func NewFlightService(repo repositories.FlightRepository, redis *redis.Client) FlightService {
+// NewFlightService 創建航班服務，搜索結果經由 cache 緩存並在座位變化時失效，票價由 pricing 計算
+func NewFlightService(repo repositories.FlightRepository, cache SearchCache, queue SearchQueue, pricing PricingService) FlightService {
 	return &flightService{
-		repo:        repo,
-		redis:       redis,
-		searchQueue: make(chan models.SearchRequest, 100), // 緩衝區大小可以根據需求調整
-		results:     make(map[string][]models.Flight),
+		repo:    repo,
+		cache:   cache,
+		queue:   queue,
+		pricing: pricing,
 	}
 }
 
 func (s *flightService) SearchFlights(ctx context.Context, req models.SearchRequest) (string, error) {
-	requestID := fmt.Sprintf("%s-%s-%s-%d", req.Origin, req.Destination, req.Date.Format("2006-01-02"), time.Now().UnixNano())
+	// 在入隊前校驗，參數錯誤立即返回 400，而不是等到任務失敗
+	if err := normalizeSearchRequest(&req); err != nil {
+		return "", err
+	}
+
+	first := req.ResolveLegs()[0]
+	requestID := fmt.Sprintf("%s-%s-%s-%d", first.Origin, first.Destination, first.Date.Format("2006-01-02"), time.Now().UnixNano())
 
-	s.searchQueue <- req
+	now := time.Now()
+	job := &models.SearchJob{
+		ID:        requestID,
+		Request:   req,
+		Status:    models.SearchJobQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	// 隊列已滿時直接返回錯誤，不阻塞 HTTP 處理協程
+	if err := s.queue.Enqueue(ctx, job); err != nil {
+		return "", err
+	}
 
 	logger.LogWithTracing(ctx, "Search request queued",
 		zap.String("requestID", requestID),
-		zap.String("origin", req.Origin),
-		zap.String("destination", req.Destination))
+		zap.String("origin", first.Origin),
+		zap.String("destination", first.Destination),
+		zap.Int("legs", len(req.ResolveLegs())))
 
 	return requestID, nil
 }
 
-func (s *flightService) GetSearchResults(requestID string) ([]models.Flight, error) {
-	s.resultsMutex.RLock()
-	defer s.resultsMutex.RUnlock()
+func (s *flightService) GetSearchJob(ctx context.Context, requestID string) (*models.SearchJob, error) {
+	return s.queue.GetJob(ctx, requestID)
+}
+
+func (s *flightService) WaitForSearchJob(ctx context.Context, requestID string) (*models.SearchJob, error) {
+	return s.queue.WaitForJob(ctx, requestID)
+}
+
+func (s *flightService) ProcessSearchJob(ctx context.Context, job *models.SearchJob) {
+	s.saveJob(ctx, job, models.SearchJobRunning)
 
-	if flights, ok := s.results[requestID]; ok {
-		return flights, nil
+	legs, err := s.processRequest(ctx, job.Request)
+	if err != nil {
+		logger.Error("Failed to process search request",
+			zap.Error(err),
+			zap.String("requestID", job.ID),
+			zap.String("origin", job.Request.Origin),
+			zap.String("destination", job.Request.Destination))
+		job.Error = err.Error()
+		s.saveFinalJob(job, models.SearchJobFailed)
+		return
 	}
 
-	return nil, fmt.Errorf("results not ready or not found")
+	job.Legs = legs
+	job.Results = legs[0].Flights
+	s.saveFinalJob(job, models.SearchJobDone)
 }
 
-func (s *flightService) ProcessSearchRequests() {
-	for req := range s.searchQueue {
-		ctx := context.Background()
-		if err := s.processRequest(ctx, req); err != nil {
-			logger.Error("Failed to process search request",
-				zap.Error(err),
-				zap.String("origin", req.Origin),
-				zap.String("destination", req.Destination))
+// processRequest 逐段搜索航程。過濾條件、艙位與人數對每段航程都適用，
+// 各段結果相互獨立，客戶端可以任意組合去程與回程。
+func (s *flightService) processRequest(ctx context.Context, req models.SearchRequest) ([]models.SearchLegResult, error) {
+	legs := req.ResolveLegs()
+	results := make([]models.SearchLegResult, 0, len(legs))
+	for _, leg := range legs {
+		legReq := req
+		legReq.Origin, legReq.Destination, legReq.Date = leg.Origin, leg.Destination, leg.Date
+		legReq.ReturnDate, legReq.Legs = time.Time{}, nil
+
+		result, err := s.searchLeg(ctx, legReq)
+		if err != nil {
+			return nil, fmt.Errorf("leg %s-%s: %w", leg.Origin, leg.Destination, err)
 		}
+		result.SearchLeg = leg
+		results = append(results, result)
 	}
+	return results, nil
 }
 
-func (s *flightService) processRequest(ctx context.Context, req models.SearchRequest) error {
-	cacheKey := fmt.Sprintf("flights:%s:%s:%s:%d:%d",
-		req.Origin, req.Destination, req.Date.Format("2006-01-02"), req.Page, req.PageSize)
+// searchLeg 搜索單段航程的直飛航班，並在允許轉機時附上轉機行程
+func (s *flightService) searchLeg(ctx context.Context, req models.SearchRequest) (models.SearchLegResult, error) {
+	// 轉機行程不分頁，只隨第一頁返回
+	withConnections := req.Filters.MaxStops > 0 && req.Cursor == ""
+	scopes := []string{routeScope(req.Origin, req.Destination, req.Date)}
+	if withConnections {
+		scopes = append(scopes, connectionScopes(req.Date)...)
+	}
 
-	cachedData, err := s.redis.Get(ctx, cacheKey).Bytes()
-	if err == nil {
-		var flights []models.Flight
-		err = json.Unmarshal(cachedData, &flights)
-		if err == nil {
-			s.storeResults(cacheKey, flights)
-			return nil
+	var result models.SearchLegResult
+	err := s.cache.Fetch(ctx, searchCacheKey(req), scopes, &result, func(ctx context.Context) (interface{}, error) {
+		// 票價取決於載客率，與結果一起緩存；座位變化使緩存失效時也會重新定價
+		page, truncated, err := s.searchDirect(ctx, req)
+		if err != nil {
+			return nil, err
 		}
+		result := models.SearchLegResult{Flights: page.Items, NextCursor: page.NextCursor, Total: page.Total, Truncated: truncated}
+
+		if withConnections {
+			result.Itineraries, err = s.searchConnections(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	})
+	return result, err
+}
+
+// searchDirect 返回一頁定價後的直飛航班。售價在定價後才知道，按價格過濾或排序時
+// 取出航線當天所有符合其他條件的航班，定價後在內存中過濾、排序與分頁；否則都在數據庫中完成。
+// 符合條件的航班超過 maxPricedSearchFlights 時只處理出發最早的部分並返回 truncated
+func (s *flightService) searchDirect(ctx context.Context, req models.SearchRequest) (*models.FlightPage, bool, error) {
+	if req.SortBy != models.FlightSortPrice && req.Filters.MaxPrice == 0 {
+		page, err := s.repo.SearchFlights(ctx, req)
+		if err != nil {
+			return nil, false, err
+		}
+		if err := s.pricing.PriceFlights(ctx, page.Items); err != nil {
+			return nil, false, err
+		}
+		return page, false, nil
 	}
 
-	// 如果緩存中沒有，則從數據庫中搜索
-	flights, err := s.repo.SearchFlights(ctx, req)
+	all := req
+	all.SortBy, all.Cursor, all.PageSize = models.FlightSortDeparture, "", maxPricedSearchFlights
+	all.Filters.MaxPrice = 0
+	page, err := s.repo.SearchFlights(ctx, all)
 	if err != nil {
-		return err
+		return nil, false, err
+	}
+	truncated := page.Total > len(page.Items)
+	if truncated {
+		logger.Info("Priced flight search truncated",
+			zap.String("route", req.Origin+"-"+req.Destination),
+			zap.Int("matched", page.Total),
+			zap.Int("limit", maxPricedSearchFlights))
+	}
+	if err := s.pricing.PriceFlights(ctx, page.Items); err != nil {
+		return nil, false, err
+	}
+
+	// 沒有所選艙位票價的航班已經售完，無法按價格比較
+	flights := make([]models.Flight, 0, len(page.Items))
+	for _, f := range page.Items {
+		fare, ok := f.Fares[req.Cabin]
+		if !ok || (req.Filters.MaxPrice > 0 && fare.Price.Amount > req.Filters.MaxPrice) {
+			continue
+		}
+		flights = append(flights, f)
+	}
+	result, err := paginateFlights(flights, req)
+	return result, truncated, err
+}
+
+// paginateFlights 按 req 的排序方式與游標在內存中分頁，次序與數據庫分頁一致：
+// 主排序值，再按出發時間與 ID
+func paginateFlights(flights []models.Flight, req models.SearchRequest) (*models.FlightPage, error) {
+	compare := func(a, b models.FlightCursor) int {
+		c := cmp.Compare(a.Key, b.Key)
+		if req.SortBy == models.FlightSortAvailability {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+		if c := a.DepartureTime.Compare(b.DepartureTime); c != 0 {
+			return c
+		}
+		return a.ID - b.ID
+	}
+	slices.SortFunc(flights, func(a, b models.Flight) int {
+		return compare(flightPosition(a, req.SortBy, req.Cabin), flightPosition(b, req.SortBy, req.Cabin))
+	})
+
+	start := 0
+	if req.Cursor != "" {
+		var cursor models.FlightCursor
+		if err := models.DecodeCursor(req.Cursor, &cursor); err != nil {
+			return nil, err
+		}
+		for start < len(flights) && compare(flightPosition(flights[start], req.SortBy, req.Cabin), cursor) <= 0 {
+			start++
+		}
+	}
+	end := min(start+req.PageSize, len(flights))
+
+	page := &models.FlightPage{Items: flights[start:end], Total: len(flights)}
+	if end < len(flights) {
+		page.NextCursor = models.EncodeCursor(flightPosition(flights[end-1], req.SortBy, req.Cabin))
+	}
+	return page, nil
+}
+
+// flightPosition 返回航班在排序方式下的分頁位置，與數據庫分頁的游標格式一致，價格取所選艙位的票價
+func flightPosition(f models.Flight, sortBy, cabin string) models.FlightCursor {
+	cursor := models.FlightCursor{SortBy: sortBy, DepartureTime: f.DepartureTime, ID: f.ID}
+	switch sortBy {
+	case models.FlightSortPrice:
+		cursor.Key = f.Fares[cabin].Price.Amount
+	case models.FlightSortDuration:
+		cursor.Key = f.ArrivalTime.Sub(f.DepartureTime).Seconds()
+	case models.FlightSortAvailability:
+		cursor.Key = float64(f.Availability.For(cabin))
+	}
+	return cursor
+}
+
+// searchConnections 搜索單段航程的轉機行程，並套用與直飛航班相同的過濾與排序
+func (s *flightService) searchConnections(ctx context.Context, req models.SearchRequest) ([]models.Itinerary, error) {
+	itinReq := models.ItinerarySearchRequest{
+		Origin:      req.Origin,
+		Destination: req.Destination,
+		Date:        req.Date,
+		MaxStops:    &req.Filters.MaxStops,
+	}
+	// 按價格搜索時候選行程也按價格截斷，其他排序方式按時長
+	if req.SortBy == models.FlightSortPrice {
+		itinReq.SortBy = models.ItinerarySortPrice
+	}
+	if err := normalizeItineraryRequest(&itinReq); err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.repo.SearchItineraries(ctx, itinReq)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.priceSegments(ctx, candidates); err != nil {
+		return nil, err
+	}
+
+	var itineraries []models.Itinerary
+	for _, segments := range candidates {
+		// 直飛航班已經在 Flights 中
+		if len(segments) < 2 {
+			continue
+		}
+		itinerary, ok := models.NewItinerary(segments, req.Cabin)
+		if ok && matchesFilters(itinerary, req) {
+			itineraries = append(itineraries, itinerary)
+		}
+	}
+
+	sortLegItineraries(itineraries, req.SortBy, req.Cabin)
+	if len(itineraries) > req.PageSize {
+		itineraries = itineraries[:req.PageSize]
+	}
+	return itineraries, nil
+}
+
+// matchesFilters 判斷轉機行程是否符合搜索條件：出發時間看第一段，到達時間看最後一段，
+// 價格看所選艙位定價後的總價，承運人與座位需要每一段都符合
+func matchesFilters(itinerary models.Itinerary, req models.SearchRequest) bool {
+	f := req.Filters
+	first, last := itinerary.Segments[0], itinerary.Segments[len(itinerary.Segments)-1]
+
+	if !inClockWindow(localClock(first.DepartureTime, first.OriginTimezone), f.DepartureAfter, f.DepartureBefore) ||
+		!inClockWindow(localClock(last.ArrivalTime, last.DestinationTimezone), f.ArrivalAfter, f.ArrivalBefore) {
+		return false
+	}
+	if f.MaxPrice > 0 && itinerary.TotalPrice > f.MaxPrice {
+		return false
+	}
+	if itinerary.Availability.For(req.Cabin) < req.Passengers.Seated() {
+		return false
+	}
+	if len(f.Carriers) > 0 {
+		for _, segment := range itinerary.Segments {
+			if !slices.Contains(f.Carriers, segment.Carrier) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// localClock 返回時間在指定時區的 HH:MM，時區無效時使用 UTC
+func localClock(t time.Time, timezone string) string {
+	if loc, err := time.LoadLocation(timezone); err == nil {
+		t = t.In(loc)
+	}
+	return t.Format("15:04")
+}
+
+// inClockWindow 判斷 HH:MM 是否落在包含端點的窗口內，空端點表示不限制
+func inClockWindow(clock, after, before string) bool {
+	return (after == "" || clock >= after) && (before == "" || clock <= before)
+}
+
+// sortLegItineraries 按航班搜索的排序方式排列轉機行程
+func sortLegItineraries(itineraries []models.Itinerary, sortBy, cabin string) {
+	slices.SortStableFunc(itineraries, func(a, b models.Itinerary) int {
+		var c int
+		switch sortBy {
+		case models.FlightSortPrice:
+			c = cmp.Compare(a.TotalPrice, b.TotalPrice)
+		case models.FlightSortDuration:
+			c = a.DurationMinutes - b.DurationMinutes
+		case models.FlightSortAvailability:
+			c = b.Availability.For(cabin) - a.Availability.For(cabin)
+		}
+		if c != 0 {
+			return c
+		}
+		return a.DepartureTime.Compare(b.DepartureTime)
+	})
+}
+
+// searchCacheKey 以除航程外所有影響結果的條件計算哈希，不同過濾條件不會共用緩存；
+// 航線與日期保留明文，便於按航線排查或清理緩存
+func searchCacheKey(req models.SearchRequest) string {
+	data, _ := json.Marshal(struct {
+		Passengers models.PassengerCounts
+		Cabin      string
+		Filters    models.SearchFilters
+		SortBy     string
+		Cursor     string
+		PageSize   int
+	}{req.Passengers, req.Cabin, req.Filters, req.SortBy, req.Cursor, req.PageSize})
+	sum := sha256.Sum256(data)
+
+	return fmt.Sprintf("flights:%s:%s:%s:%x",
+		req.Origin, req.Destination, req.Date.Format("2006-01-02"), sum[:12])
+}
+
+// normalizeSearchRequest 填充默認值並校驗航班搜索條件
+func normalizeSearchRequest(req *models.SearchRequest) error {
+	if len(req.Legs) > 0 {
+		if req.Origin != "" || req.Destination != "" || !req.Date.IsZero() || !req.ReturnDate.IsZero() {
+			return &ValidationError{Field: "legs", Message: "cannot be combined with origin, destination, date or return_date"}
+		}
+		if len(req.Legs) > maxSearchLegs {
+			return &ValidationError{Field: "legs", Message: fmt.Sprintf("at most %d legs are supported", maxSearchLegs)}
+		}
+	} else if !req.ReturnDate.IsZero() && req.ReturnDate.Before(req.Date) {
+		return &ValidationError{Field: "return_date", Message: "must not be before date"}
 	}
 
-	// 將結果存入 Redis 緩存
-	cacheData, err := json.Marshal(flights)
-	if err == nil {
-		// 使用帶有 context 的 Set 方法
-		// 好處：
-		// 1. 可以傳遞超時設置，允許控制操作的最長執行時間
-		// 2. 支持取消操作，如果上層調用被取消，Redis 操作也會被取消
-		// 3. 可以傳遞請求級別的元數據，如追蹤 ID，有助於分佈式追蹤
-		// 4. 提高了代碼的一致性，與其他使用 context 的 Go 標準庫和第三方庫保持一致
-		if err := s.redis.Set(ctx, cacheKey, cacheData, 15*time.Minute).Err(); err != nil {
-			logger.Error("Failed to set cache", zap.Error(err), zap.String("cacheKey", cacheKey))
+	legs := req.ResolveLegs()
+	for i, leg := range legs {
+		if leg.Origin == "" || leg.Destination == "" || leg.Date.IsZero() {
+			return &ValidationError{Field: "legs", Message: fmt.Sprintf("leg %d: origin, destination and date are required", i+1)}
+		}
+		if leg.Origin == leg.Destination {
+			return &ValidationError{Field: "legs", Message: fmt.Sprintf("leg %d: destination must differ from origin", i+1)}
 		}
+		if i > 0 && leg.Date.Before(legs[i-1].Date) {
+			return &ValidationError{Field: "legs", Message: fmt.Sprintf("leg %d: date must not be before the previous leg", i+1)}
+		}
+	}
+
+	if err := normalizePassengers(&req.Passengers); err != nil {
+		return err
+	}
+
+	if req.Cabin == "" {
+		req.Cabin = "economy"
+	}
+	if !isValidClass(req.Cabin) {
+		return &ValidationError{Field: "cabin", Message: "must be one of economy, business, first"}
 	}
 
-	s.storeResults(cacheKey, flights)
+	if err := normalizeSearchFilters(&req.Filters); err != nil {
+		return err
+	}
+	switch req.SortBy {
+	case "":
+		req.SortBy = models.FlightSortDeparture
+	case models.FlightSortDeparture, models.FlightSortPrice, models.FlightSortDuration, models.FlightSortAvailability:
+	default:
+		return &ValidationError{Field: "sort_by", Message: "must be one of departure, price, duration, availability"}
+	}
+
+	// 游標只描述一段航程中的位置，往返與多城市搜索需對單段重新搜索才能翻頁
+	var cursor models.FlightCursor
+	if err := normalizePage(req.Cursor, &req.PageSize, &cursor); err != nil {
+		return err
+	}
+	if req.Cursor != "" {
+		if len(legs) > 1 {
+			return &ValidationError{Field: "cursor", Message: "is only supported for one-way searches"}
+		}
+		if cursor.SortBy != req.SortBy {
+			return &ValidationError{Field: "cursor", Message: "was issued for a different sort_by"}
+		}
+	}
 	return nil
 }
 
-func (s *flightService) storeResults(requestID string, flights []models.Flight) {
-	s.resultsMutex.Lock()
-	defer s.resultsMutex.Unlock()
-	s.results[requestID] = flights
+func (s *flightService) SearchItineraries(ctx context.Context, req models.ItinerarySearchRequest) ([]models.Itinerary, error) {
+	if err := normalizeItineraryRequest(&req); err != nil {
+		return nil, err
+	}
+
+	cacheKey := fmt.Sprintf("itineraries:%s:%s:%s:%d:%d:%d:%s:%d",
+		req.Origin, req.Destination, req.Date.Format("2006-01-02"),
+		*req.MaxStops, req.MinConnectionMinutes, req.MaxConnectionMinutes, req.SortBy, req.Limit)
+
+	var itineraries []models.Itinerary
+	err := s.cache.Fetch(ctx, cacheKey, connectionScopes(req.Date), &itineraries, func(ctx context.Context) (interface{}, error) {
+		candidates, err := s.repo.SearchItineraries(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.priceSegments(ctx, candidates); err != nil {
+			return nil, err
+		}
+
+		// 行程搜索不指定艙位，按經濟艙定價
+		itineraries := make([]models.Itinerary, 0, len(candidates))
+		for _, segments := range candidates {
+			if itinerary, ok := models.NewItinerary(segments, "economy"); ok {
+				itineraries = append(itineraries, itinerary)
+			}
+		}
+		sortItineraries(itineraries, req.SortBy)
+		if len(itineraries) > req.Limit {
+			itineraries = itineraries[:req.Limit]
+		}
+		return itineraries, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return itineraries, nil
+}
+
+func (s *flightService) GetFareClasses(ctx context.Context, flightID int) ([]models.FareClass, error) {
+	// 先確認航班存在，區分不存在的航班與沒有定義票價艙等的航班
+	if _, err := s.repo.GetFlightByID(ctx, flightID); err != nil {
+		return nil, err
+	}
+	classes, err := s.repo.GetFareClasses(ctx, []int{flightID})
+	if err != nil {
+		return nil, err
+	}
+	if classes[flightID] == nil {
+		return []models.FareClass{}, nil
+	}
+	return classes[flightID], nil
 }
 
-func (s *flightService) GetSearchQueue() <-chan models.SearchRequest {
-	return s.searchQueue
+// priceSegments 一次為所有候選行程的航段定價，避免逐個行程查詢票價艙等
+func (s *flightService) priceSegments(ctx context.Context, candidates [][]models.Flight) error {
+	var flights []models.Flight
+	for _, segments := range candidates {
+		flights = append(flights, segments...)
+	}
+	if err := s.pricing.PriceFlights(ctx, flights); err != nil {
+		return err
+	}
+	offset := 0
+	for _, segments := range candidates {
+		offset += copy(segments, flights[offset:])
+	}
+	return nil
+}
+
+// normalizeItineraryRequest 填充默認值並校驗行程搜索條件
+func normalizeItineraryRequest(req *models.ItinerarySearchRequest) error {
+	if req.Origin == "" || req.Destination == "" {
+		return &ValidationError{Field: "origin", Message: "origin and destination are required"}
+	}
+	if req.Origin == req.Destination {
+		return &ValidationError{Field: "destination", Message: "must differ from origin"}
+	}
+	if req.Date.IsZero() {
+		return &ValidationError{Field: "date", Message: "is required"}
+	}
+
+	if req.MaxStops == nil {
+		stops := defaultItineraryMaxStops
+		req.MaxStops = &stops
+	}
+	if *req.MaxStops < 0 || *req.MaxStops > maxItineraryStops {
+		return &ValidationError{Field: "max_stops", Message: fmt.Sprintf("must be between 0 and %d", maxItineraryStops)}
+	}
+	if req.MinConnectionMinutes == 0 {
+		req.MinConnectionMinutes = defaultMinConnectionMinutes
+	}
+	if req.MaxConnectionMinutes == 0 {
+		req.MaxConnectionMinutes = defaultMaxConnectionMinutes
+	}
+	if req.MinConnectionMinutes < 0 || req.MaxConnectionMinutes > maxConnectionMinutes ||
+		req.MinConnectionMinutes > req.MaxConnectionMinutes {
+		return &ValidationError{Field: "max_connection_minutes",
+			Message: fmt.Sprintf("connection window must satisfy 0 <= min <= max <= %d", maxConnectionMinutes)}
+	}
+
+	switch req.SortBy {
+	case "":
+		req.SortBy = models.ItinerarySortDuration
+	case models.ItinerarySortDuration, models.ItinerarySortPrice, models.ItinerarySortStops:
+	default:
+		return &ValidationError{Field: "sort_by", Message: "must be one of duration, price, stops"}
+	}
+
+	if req.Limit == 0 {
+		req.Limit = defaultItineraryLimit
+	}
+	if req.Limit < 0 || req.Limit > maxItineraryLimit {
+		return &ValidationError{Field: "limit", Message: fmt.Sprintf("must be between 1 and %d", maxItineraryLimit)}
+	}
+	return nil
 }
 
-func (s *flightService) calculateAvailableSeats(flight *models.Flight, class string) int {
-	switch class {
-	case "economy":
-		return int(float64(flight.EconomySeats.Total)*(1+flight.EconomySeats.OverbookingRatio)) - flight.EconomySeats.Booked
-	case "business":
-		return int(float64(flight.BusinessSeats.Total)*(1+flight.BusinessSeats.OverbookingRatio)) - flight.BusinessSeats.Booked
-	case "first":
-		return int(float64(flight.FirstClassSeats.Total)*(1+flight.FirstClassSeats.OverbookingRatio)) - flight.FirstClassSeats.Booked
+// sortItineraries 按主排序鍵排序，其餘兩個鍵依次作為平手時的次序，最後按出發時間
+func sortItineraries(itineraries []models.Itinerary, sortBy string) {
+	byDuration := func(a, b models.Itinerary) int { return a.DurationMinutes - b.DurationMinutes }
+	byPrice := func(a, b models.Itinerary) int { return cmp.Compare(a.TotalPrice, b.TotalPrice) }
+	byStops := func(a, b models.Itinerary) int { return a.Stops - b.Stops }
+
+	var keys []func(a, b models.Itinerary) int
+	switch sortBy {
+	case models.ItinerarySortPrice:
+		keys = append(keys, byPrice, byDuration, byStops)
+	case models.ItinerarySortStops:
+		keys = append(keys, byStops, byDuration, byPrice)
 	default:
-		return 0
+		keys = append(keys, byDuration, byPrice, byStops)
 	}
+
+	slices.SortStableFunc(itineraries, func(a, b models.Itinerary) int {
+		for _, key := range keys {
+			if c := key(a, b); c != 0 {
+				return c
+			}
+		}
+		return a.DepartureTime.Compare(b.DepartureTime)
+	})
+}
+
+func (s *flightService) GetFareCalendar(ctx context.Context, req models.FareCalendarRequest) (*models.FareCalendar, error) {
+	from, to, err := normalizeFareCalendarRequest(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 與航班搜索緩存共用 flights: 前綴；日曆按艙位與佔座人數區分
+	cacheKey := fmt.Sprintf("flights:calendar:%s:%s:%s:%s:%s:%d",
+		req.Origin, req.Destination, req.Cabin, from.Format("2006-01-02"), to.Format("2006-01-02"), req.Passengers.Seated())
+
+	// 日曆依賴範圍內每一天的航線版本，任何一天的座位變化都會使整個日曆失效
+	var scopes []string
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		scopes = append(scopes, routeScope(req.Origin, req.Destination, d))
+	}
+
+	var calendar models.FareCalendar
+	err = s.cache.Fetch(ctx, cacheKey, scopes, &calendar, func(ctx context.Context) (interface{}, error) {
+		// 最低價取定價後的售價而不是基礎票價，與同一天搜索返回的 fares 一致
+		flights, err := s.repo.GetFlightsByDateRange(ctx, req.Origin, req.Destination, from, to)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.pricing.PriceFlights(ctx, flights); err != nil {
+			return nil, err
+		}
+		return buildFareCalendar(req, from, to, fareCalendarDays(flights, req.Cabin, req.Passengers.Seated())), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &calendar, nil
+}
+
+// fareCalendarDays 按出發機場當地日期彙總定價後的航班。座位足夠且所選艙位有票價的航班才可訂，
+// 最低價取這些航班在所選艙位的售價；只返回有航班的日期
+func fareCalendarDays(flights []models.Flight, cabin string, seats int) []models.FareCalendarDay {
+	var days []models.FareCalendarDay
+	index := make(map[string]int)
+	for _, f := range flights {
+		departure := f.DepartureTime
+		if loc, err := time.LoadLocation(f.OriginTimezone); err == nil {
+			departure = departure.In(loc)
+		}
+		date := departure.Format("2006-01-02")
+		i, ok := index[date]
+		if !ok {
+			i = len(days)
+			index[date] = i
+			days = append(days, models.FareCalendarDay{Date: date})
+		}
+		day := &days[i]
+
+		available := f.Availability.For(cabin)
+		day.Flights++
+		day.MaxAvailableSeats = max(day.MaxAvailableSeats, available)
+		fare, ok := f.Fares[cabin]
+		if !ok || available < seats {
+			continue
+		}
+		day.BookableFlights++
+		if day.MinPrice == nil || fare.Price.Amount < *day.MinPrice {
+			price := fare.Price.Amount
+			day.MinPrice = &price
+		}
+	}
+	return days
+}
+
+// buildFareCalendar 將逐日統計補齊為連續的日曆，並找出最便宜的日期
+func buildFareCalendar(req models.FareCalendarRequest, from, to time.Time, days []models.FareCalendarDay) models.FareCalendar {
+	calendar := models.FareCalendar{
+		Origin:      req.Origin,
+		Destination: req.Destination,
+		Cabin:       req.Cabin,
+		From:        from.Format("2006-01-02"),
+		To:          to.Format("2006-01-02"),
+	}
+
+	// 只有有航班的日期有統計，補齊其餘日期，客戶端可以直接按天渲染
+	byDate := make(map[string]models.FareCalendarDay, len(days))
+	for _, day := range days {
+		byDate[day.Date] = day
+	}
+	var cheapest *float64
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		day, ok := byDate[date]
+		if !ok {
+			day = models.FareCalendarDay{Date: date}
+		}
+		if day.MinPrice != nil && (cheapest == nil || *day.MinPrice < *cheapest) {
+			cheapest = day.MinPrice
+			calendar.CheapestDate = date
+		}
+		calendar.Days = append(calendar.Days, day)
+	}
+	return calendar
+}
+
+// normalizeFareCalendarRequest 填充默認值、校驗條件並返回查詢的起止日期（含）
+func normalizeFareCalendarRequest(req *models.FareCalendarRequest) (time.Time, time.Time, error) {
+	var from, to time.Time
+	if req.Origin == "" || req.Destination == "" {
+		return from, to, &ValidationError{Field: "origin", Message: "origin and destination are required"}
+	}
+	if req.Origin == req.Destination {
+		return from, to, &ValidationError{Field: "destination", Message: "must differ from origin"}
+	}
+
+	if req.Month != "" {
+		if !req.Date.IsZero() || req.FlexDays != 0 {
+			return from, to, &ValidationError{Field: "month", Message: "cannot be combined with date or flex_days"}
+		}
+		month, err := time.Parse("2006-01", req.Month)
+		if err != nil {
+			return from, to, &ValidationError{Field: "month", Message: "must be in YYYY-MM format"}
+		}
+		from, to = month, month.AddDate(0, 1, -1)
+	} else {
+		if req.Date.IsZero() {
+			return from, to, &ValidationError{Field: "date", Message: "date or month is required"}
+		}
+		if req.FlexDays == 0 {
+			req.FlexDays = defaultFareCalendarFlexDays
+		}
+		if req.FlexDays < 0 || req.FlexDays > maxFareCalendarFlexDays {
+			return from, to, &ValidationError{Field: "flex_days", Message: fmt.Sprintf("must be between 1 and %d", maxFareCalendarFlexDays)}
+		}
+		// 只取日期部分，避免時間與時區影響逐日的範圍
+		date := time.Date(req.Date.Year(), req.Date.Month(), req.Date.Day(), 0, 0, 0, 0, time.UTC)
+		from, to = date.AddDate(0, 0, -req.FlexDays), date.AddDate(0, 0, req.FlexDays)
+	}
+
+	if req.Cabin == "" {
+		req.Cabin = "economy"
+	}
+	if !isValidClass(req.Cabin) {
+		return from, to, &ValidationError{Field: "cabin", Message: "must be one of economy, business, first"}
+	}
+	if err := normalizePassengers(&req.Passengers); err != nil {
+		return from, to, err
+	}
+	return from, to, nil
+}
+
+// normalizeSearchFilters 校驗過濾條件，並將承運人代碼統一為大寫
+func normalizeSearchFilters(f *models.SearchFilters) error {
+	windows := []struct {
+		field         string
+		after, before string
+	}{
+		{"departure", f.DepartureAfter, f.DepartureBefore},
+		{"arrival", f.ArrivalAfter, f.ArrivalBefore},
+	}
+	for _, w := range windows {
+		for _, clock := range []string{w.after, w.before} {
+			if clock == "" {
+				continue
+			}
+			if _, err := time.Parse("15:04", clock); err != nil || len(clock) != 5 {
+				return &ValidationError{Field: "filters." + w.field, Message: "times must be in HH:MM format"}
+			}
+		}
+		if w.after != "" && w.before != "" && w.after > w.before {
+			return &ValidationError{Field: "filters." + w.field, Message: "after must not be later than before"}
+		}
+	}
+
+	if f.MaxPrice < 0 {
+		return &ValidationError{Field: "filters.max_price", Message: "must not be negative"}
+	}
+	if f.MaxStops < 0 || f.MaxStops > maxItineraryStops {
+		return &ValidationError{Field: "filters.max_stops", Message: fmt.Sprintf("must be between 0 and %d", maxItineraryStops)}
+	}
+	for i, carrier := range f.Carriers {
+		if len(carrier) != 2 {
+			return &ValidationError{Field: "filters.carriers", Message: "must be two-character airline codes"}
+		}
+		f.Carriers[i] = strings.ToUpper(carrier)
+	}
+	return nil
+}
+
+// normalizePassengers 未指定人數時默認 1 位成人，並校驗團體組成
+func normalizePassengers(p *models.PassengerCounts) error {
+	if *p == (models.PassengerCounts{}) {
+		p.Adults = 1
+	}
+	if p.Adults < 0 || p.Children < 0 || p.Infants < 0 {
+		return &ValidationError{Field: "passengers", Message: "counts must not be negative"}
+	}
+	if p.Adults == 0 {
+		return &ValidationError{Field: "passengers", Message: "at least one adult is required"}
+	}
+	// 每位嬰兒需要一位成人懷抱
+	if p.Infants > p.Adults {
+		return &ValidationError{Field: "passengers", Message: "each infant must travel with an adult"}
+	}
+	if p.Seated() > maxSearchPartySize {
+		return &ValidationError{Field: "passengers", Message: fmt.Sprintf("at most %d seated passengers per search", maxSearchPartySize)}
+	}
+	return nil
+}
+
+func (s *flightService) saveJob(ctx context.Context, job *models.SearchJob, status string) {
+	job.Status = status
+	job.UpdatedAt = time.Now()
+	if err := s.queue.SaveJob(ctx, job); err != nil {
+		logger.Error("Failed to save search job", zap.Error(err), zap.String("requestID", job.ID))
+	}
+}
+
+// saveFinalJob 寫回任務的最終狀態。任務的 ctx 可能已因超時或關閉而取消，
+// 最終狀態必須寫入，否則任務停留在 running，等待中的客戶端收不到結束事件
+func (s *flightService) saveFinalJob(job *models.SearchJob, status string) {
+	ctx, cancel := context.WithTimeout(context.Background(), searchJobSaveTimeout)
+	defer cancel()
+	s.saveJob(ctx, job, status)
+}
+
+func (s *flightService) GetSearchQueue() SearchQueue {
+	return s.queue
 }
 
 func (s *flightService) BookFlight(ctx context.Context, flightID int, class string, numSeats int) error {
@@ -143,8 +834,11 @@ func (s *flightService) BookFlight(ctx context.Context, flightID int, class stri
 		return err
 	}
 
-	availableSeats := s.calculateAvailableSeats(flight, class)
-	if numSeats > availableSeats {
+	seats, ok := flight.Seats(class)
+	if !ok {
+		return repositories.ErrInvalidClass
+	}
+	if numSeats > seats.Available() {
 		return errors.New("not enough seats available")
 	}
 
@@ -2,6 +2,9 @@ package services_test
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -21,6 +24,37 @@ func init() {
 	logger.SetLoggerForTest(testLogger)
 }
 
+// stubPricing 返回以基礎票價作為經濟艙票價的定價服務，定價本身由 pricing_service_test 覆蓋
+func stubPricing(ctrl *gomock.Controller) *mocks.MockPricingService {
+	pricing := mocks.NewMockPricingService(ctrl)
+	pricing.EXPECT().PriceFlights(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, flights []models.Flight) error {
+			for i := range flights {
+				flights[i].Fares = map[string]models.Fare{
+					"economy": {FareClass: "Y", Price: models.Money{Amount: flights[i].Price, Currency: "USD"}},
+				}
+			}
+			return nil
+		}).AnyTimes()
+	return pricing
+}
+
+// businessPricing 以 fares 中按航班 ID 給定的金額作為公務艙售價，經濟艙售價為基礎票價
+func businessPricing(ctrl *gomock.Controller, fares map[int]float64) *mocks.MockPricingService {
+	pricing := mocks.NewMockPricingService(ctrl)
+	pricing.EXPECT().PriceFlights(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, flights []models.Flight) error {
+			for i := range flights {
+				flights[i].Fares = map[string]models.Fare{
+					"economy":  {Price: models.Money{Amount: flights[i].Price, Currency: "USD"}},
+					"business": {Price: models.Money{Amount: fares[flights[i].ID], Currency: "USD"}},
+				}
+			}
+			return nil
+		}).AnyTimes()
+	return pricing
+}
+
 func TestFlightService_SearchFlights(t *testing.T) {
 
 	testLogger, _ := zap.NewDevelopment()
@@ -33,9 +67,9 @@ func TestFlightService_SearchFlights(t *testing.T) {
 	mockRedis, _ := redismock.NewClientMock()
 
 	// 設置預期行為
-	mockRepo.EXPECT().SearchFlights(gomock.Any(), gomock.Any()).Return([]models.Flight{}, nil).AnyTimes()
+	mockRepo.EXPECT().SearchFlights(gomock.Any(), gomock.Any()).Return(&models.FlightPage{}, nil).AnyTimes()
 
-	service := services.NewFlightService(mockRepo, mockRedis)
+	service := services.NewFlightService(mockRepo, services.NewSearchCache(mockRedis, mockRepo, 15*time.Minute, services.LRUOptions{}), services.NewMemorySearchQueue(100, services.LRUOptions{}), stubPricing(ctrl))
 
 	ctx := context.Background()
 	req := models.SearchRequest{
@@ -52,12 +86,667 @@ func TestFlightService_SearchFlights(t *testing.T) {
 	// 驗證 requestID 格式
 	assert.Regexp(t, `^New York-London-\d{4}-\d{2}-\d{2}-\d+$`, requestID)
 
-	// 驗證請求被加入隊列
-	select {
-	case receivedReq := <-service.GetSearchQueue():
-		assert.Equal(t, req.Origin, receivedReq.Origin)
-		assert.Equal(t, req.Destination, receivedReq.Destination)
-	case <-time.After(time.Second):
-		t.Error("Request was not added to the queue within the expected time")
+	// 驗證任務帶著請求 ID 被加入隊列
+	dequeueCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	job, err := service.GetSearchQueue().Dequeue(dequeueCtx)
+	if assert.NoError(t, err, "Request was not added to the queue within the expected time") {
+		assert.Equal(t, requestID, job.ID)
+		assert.Equal(t, req.Origin, job.Request.Origin)
+		assert.Equal(t, req.Destination, job.Request.Destination)
+		assert.Equal(t, models.SearchJobQueued, job.Status)
+	}
+}
+
+func TestFlightService_SearchFlights_QueueFull(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRedis, _ := redismock.NewClientMock()
+	service := services.NewFlightService(mocks.NewMockFlightRepository(ctrl), services.NewSearchCache(mockRedis, nil, 15*time.Minute, services.LRUOptions{}), services.NewMemorySearchQueue(1, services.LRUOptions{}), stubPricing(ctrl))
+
+	req := models.SearchRequest{Origin: "TPE", Destination: "NRT", Date: time.Now()}
+	_, err := service.SearchFlights(context.Background(), req)
+	assert.NoError(t, err)
+
+	// 隊列已滿時立即拒絕，而不是阻塞調用方
+	_, err = service.SearchFlights(context.Background(), req)
+	assert.ErrorIs(t, err, services.ErrSearchQueueFull)
+}
+
+func TestFlightService_GetSearchJob_ResolvesResults(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockFlightRepository(ctrl)
+	mockRedis, _ := redismock.NewClientMock()
+
+	flights := []models.Flight{{ID: 1, Origin: "TPE", Destination: "NRT", Availability: models.CabinAvailability{Economy: 5}}}
+	mockRepo.EXPECT().SearchFlights(gomock.Any(), gomock.Any()).Return(&models.FlightPage{Items: flights, Total: 1}, nil)
+
+	service := services.NewFlightService(mockRepo, services.NewSearchCache(mockRedis, mockRepo, 15*time.Minute, services.LRUOptions{}), services.NewMemorySearchQueue(100, services.LRUOptions{}), stubPricing(ctrl))
+	pool := services.NewSearchWorkerPool(service, 2, time.Second)
+	pool.Start()
+	defer pool.Shutdown(context.Background())
+
+	requestID, err := service.SearchFlights(context.Background(), models.SearchRequest{
+		Origin:      "TPE",
+		Destination: "NRT",
+		Date:        time.Now(),
+	})
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		job, err := service.GetSearchJob(context.Background(), requestID)
+		return err == nil && job.Status == models.SearchJobDone
+	}, time.Second, 10*time.Millisecond)
+
+	job, _ := service.GetSearchJob(context.Background(), requestID)
+	assert.Equal(t, flights, job.Results)
+
+	_, err = service.GetSearchJob(context.Background(), "unknown")
+	assert.ErrorIs(t, err, services.ErrSearchJobNotFound)
+}
+
+func TestFlightService_GetSearchJob_Failed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockFlightRepository(ctrl)
+	mockRedis, _ := redismock.NewClientMock()
+	mockRepo.EXPECT().SearchFlights(gomock.Any(), gomock.Any()).Return(nil, errors.New("db down"))
+
+	service := services.NewFlightService(mockRepo, services.NewSearchCache(mockRedis, mockRepo, 15*time.Minute, services.LRUOptions{}), services.NewMemorySearchQueue(100, services.LRUOptions{}), stubPricing(ctrl))
+	pool := services.NewSearchWorkerPool(service, 2, time.Second)
+	pool.Start()
+	defer pool.Shutdown(context.Background())
+
+	requestID, _ := service.SearchFlights(context.Background(), models.SearchRequest{Origin: "TPE", Destination: "NRT", Date: time.Now()})
+
+	assert.Eventually(t, func() bool {
+		job, err := service.GetSearchJob(context.Background(), requestID)
+		return err == nil && job.Status == models.SearchJobFailed && strings.HasSuffix(job.Error, "db down")
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestMemorySearchQueue_WaitForJob(t *testing.T) {
+	queue := services.NewMemorySearchQueue(10, services.LRUOptions{})
+	job := &models.SearchJob{ID: "wait", Status: models.SearchJobQueued}
+	assert.NoError(t, queue.Enqueue(context.Background(), job))
+
+	// 任務未結束時 ctx 到期，返回當前快照與 ctx 的錯誤
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	snapshot, err := queue.WaitForJob(ctx, "wait")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, models.SearchJobQueued, snapshot.Status)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		finished := *job
+		finished.Status = models.SearchJobDone
+		queue.SaveJob(context.Background(), &finished)
+	}()
+
+	result, err := queue.WaitForJob(context.Background(), "wait")
+	assert.NoError(t, err)
+	assert.Equal(t, models.SearchJobDone, result.Status)
+
+	_, err = queue.WaitForJob(context.Background(), "missing")
+	assert.ErrorIs(t, err, services.ErrSearchJobNotFound)
+}
+
+func itinerarySegment(id int, depart time.Time, minutes int, price float64, economy int) models.Flight {
+	return models.Flight{
+		ID:            id,
+		DepartureTime: depart,
+		ArrivalTime:   depart.Add(time.Duration(minutes) * time.Minute),
+		Price:         price,
+		Availability:  models.CabinAvailability{Economy: economy, Business: 2, First: 1},
+	}
+}
+
+func TestFlightService_SearchItineraries_Ranking(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockFlightRepository(ctrl)
+	mockRedis, _ := redismock.NewClientMock()
+	service := services.NewFlightService(mockRepo, services.NewSearchCache(mockRedis, mockRepo, 15*time.Minute, services.LRUOptions{}), services.NewMemorySearchQueue(1, services.LRUOptions{}), stubPricing(ctrl))
+
+	day := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	// 直飛：10 小時，1000；一次轉機：9 小時，600，第二段經濟艙只剩 3 個座位
+	direct := []models.Flight{itinerarySegment(1, day, 600, 1000, 50)}
+	oneStop := []models.Flight{
+		itinerarySegment(2, day, 240, 300, 40),
+		itinerarySegment(3, day.Add(5*time.Hour), 240, 300, 3),
+	}
+
+	mockRepo.EXPECT().SearchItineraries(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, req models.ItinerarySearchRequest) ([][]models.Flight, error) {
+			// 未指定的條件由服務填充默認值
+			assert.Equal(t, 1, *req.MaxStops)
+			assert.Equal(t, 45, req.MinConnectionMinutes)
+			return [][]models.Flight{direct, oneStop}, nil
+		}).Times(2)
+
+	req := models.ItinerarySearchRequest{Origin: "TPE", Destination: "LHR", Date: day}
+	byDuration, err := service.SearchItineraries(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Len(t, byDuration, 2)
+	assert.Equal(t, 1, byDuration[0].Stops)
+	assert.Equal(t, 540, byDuration[0].DurationMinutes)
+	assert.Equal(t, 600.0, byDuration[0].TotalPrice)
+	assert.Equal(t, 3, byDuration[0].Availability.Economy)
+
+	req.SortBy = models.ItinerarySortStops
+	byStops, err := service.SearchItineraries(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, byStops[0].Stops)
+}
+
+func TestFlightService_SearchItineraries_Validation(t *testing.T) {
+	service := services.NewFlightService(nil, nil, services.NewMemorySearchQueue(1, services.LRUOptions{}), nil)
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	three := 3
+
+	tests := []struct {
+		name  string
+		req   models.ItinerarySearchRequest
+		field string
+	}{
+		{"same airport", models.ItinerarySearchRequest{Origin: "TPE", Destination: "TPE", Date: day}, "destination"},
+		{"too many stops", models.ItinerarySearchRequest{Origin: "TPE", Destination: "LHR", Date: day, MaxStops: &three}, "max_stops"},
+		{"inverted window", models.ItinerarySearchRequest{Origin: "TPE", Destination: "LHR", Date: day,
+			MinConnectionMinutes: 120, MaxConnectionMinutes: 60}, "max_connection_minutes"},
+		{"unknown sort", models.ItinerarySearchRequest{Origin: "TPE", Destination: "LHR", Date: day, SortBy: "fun"}, "sort_by"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.SearchItineraries(context.Background(), tt.req)
+			var validationErr *services.ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.field, validationErr.Field)
+		})
+	}
+}
+
+func TestFlightService_SearchFlights_RoundTrip(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockFlightRepository(ctrl)
+	mockRedis, _ := redismock.NewClientMock()
+
+	outbound := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	inbound := outbound.AddDate(0, 0, 7)
+
+	// 每段航程都帶著艙位與團體人數查詢，座位過濾由數據庫完成
+	mockRepo.EXPECT().SearchFlights(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, req models.SearchRequest) (*models.FlightPage, error) {
+			assert.Equal(t, "business", req.Cabin)
+			assert.Equal(t, 3, req.Passengers.Seated())
+			assert.Empty(t, req.Legs)
+			assert.True(t, req.ReturnDate.IsZero())
+			if req.Origin == "TPE" {
+				assert.Equal(t, outbound, req.Date)
+				return &models.FlightPage{Items: []models.Flight{{ID: 2}}, Total: 1}, nil
+			}
+			assert.Equal(t, "NRT", req.Origin)
+			assert.Equal(t, inbound, req.Date)
+			return &models.FlightPage{Items: []models.Flight{{ID: 3}, {ID: 4}}, Total: 2}, nil
+		}).Times(2)
+
+	service := services.NewFlightService(mockRepo, services.NewSearchCache(mockRedis, mockRepo, 15*time.Minute, services.LRUOptions{}), services.NewMemorySearchQueue(10, services.LRUOptions{}), stubPricing(ctrl))
+	pool := services.NewSearchWorkerPool(service, 1, time.Second)
+	pool.Start()
+	defer pool.Shutdown(context.Background())
+
+	requestID, err := service.SearchFlights(context.Background(), models.SearchRequest{
+		Origin:      "TPE",
+		Destination: "NRT",
+		Date:        outbound,
+		ReturnDate:  inbound,
+		// 嬰兒不佔座位
+		Passengers: models.PassengerCounts{Adults: 2, Children: 1, Infants: 1},
+		Cabin:      "business",
+	})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	job, err := service.WaitForSearchJob(ctx, requestID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.SearchJobDone, job.Status)
+	if assert.Len(t, job.Legs, 2) {
+		assert.Equal(t, "TPE", job.Legs[0].Origin)
+		assert.Equal(t, []int{2}, flightIDs(job.Legs[0].Flights))
+		assert.Equal(t, "NRT", job.Legs[1].Origin)
+		assert.Equal(t, []int{3, 4}, flightIDs(job.Legs[1].Flights))
+	}
+}
+
+func TestFlightService_SearchFlights_Validation(t *testing.T) {
+	service := services.NewFlightService(nil, nil, services.NewMemorySearchQueue(10, services.LRUOptions{}), nil)
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		req   models.SearchRequest
+		field string
+	}{
+		{"return before outbound", models.SearchRequest{Origin: "TPE", Destination: "NRT", Date: day, ReturnDate: day.AddDate(0, 0, -1)}, "return_date"},
+		{"legs mixed with origin", models.SearchRequest{Origin: "TPE", Legs: []models.SearchLeg{{Origin: "TPE", Destination: "NRT", Date: day}}}, "legs"},
+		{"legs out of order", models.SearchRequest{Legs: []models.SearchLeg{
+			{Origin: "TPE", Destination: "NRT", Date: day},
+			{Origin: "NRT", Destination: "LAX", Date: day.AddDate(0, 0, -1)},
+		}}, "legs"},
+		{"infants without adults", models.SearchRequest{Origin: "TPE", Destination: "NRT", Date: day,
+			Passengers: models.PassengerCounts{Adults: 1, Infants: 2}}, "passengers"},
+		{"party too large", models.SearchRequest{Origin: "TPE", Destination: "NRT", Date: day,
+			Passengers: models.PassengerCounts{Adults: 6, Children: 4}}, "passengers"},
+		{"unknown cabin", models.SearchRequest{Origin: "TPE", Destination: "NRT", Date: day, Cabin: "premium"}, "cabin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.SearchFlights(context.Background(), tt.req)
+			var validationErr *services.ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.field, validationErr.Field)
+		})
+	}
+}
+
+func flightIDs(flights []models.Flight) []int {
+	ids := make([]int, 0, len(flights))
+	for _, f := range flights {
+		ids = append(ids, f.ID)
+	}
+	return ids
+}
+
+func TestFlightService_GetFareCalendar(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockFlightRepository(ctrl)
+	mockRedis, _ := redismock.NewClientMock()
+	service := services.NewFlightService(mockRepo, services.NewSearchCache(mockRedis, mockRepo, 15*time.Minute, services.LRUOptions{}), services.NewMemorySearchQueue(1, services.LRUOptions{}), businessPricing(ctrl, map[int]float64{1: 540, 2: 300, 3: 360, 4: 200}))
+
+	from := time.Date(2024, 5, 8, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC)
+	flight := func(id int, departure time.Time, businessSeats int) models.Flight {
+		f := models.Flight{ID: id, OriginTimezone: "Asia/Taipei", DepartureTime: departure, Price: 100,
+			BusinessSeats: models.SeatInventory{Total: businessSeats}}
+		f.UpdateAvailability()
+		return f
+	}
+	mockRepo.EXPECT().GetFlightsByDateRange(gomock.Any(), "TPE", "NRT", from, to).Return([]models.Flight{
+		flight(1, time.Date(2024, 5, 9, 2, 0, 0, 0, time.UTC), 4),
+		// 座位不足的航班不計入最低價
+		flight(2, time.Date(2024, 5, 9, 4, 0, 0, 0, time.UTC), 1),
+		// 按出發機場當地日期歸入 5 月 11 日
+		flight(3, time.Date(2024, 5, 10, 18, 0, 0, 0, time.UTC), 2),
+		flight(4, time.Date(2024, 5, 12, 2, 0, 0, 0, time.UTC), 1),
+	}, nil)
+
+	calendar, err := service.GetFareCalendar(context.Background(), models.FareCalendarRequest{
+		Origin:      "TPE",
+		Destination: "NRT",
+		Date:        time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC),
+		FlexDays:    2,
+		Cabin:       "business",
+		Passengers:  models.PassengerCounts{Adults: 1, Children: 1, Infants: 1},
+	})
+	assert.NoError(t, err)
+
+	// 沒有航班的日期也會列出
+	assert.Len(t, calendar.Days, 5)
+	assert.Equal(t, "2024-05-08", calendar.Days[0].Date)
+	assert.Nil(t, calendar.Days[0].MinPrice)
+	// 最低價是定價後的公務艙售價，不是基礎票價
+	assert.Equal(t, 540.0, *calendar.Days[1].MinPrice)
+	assert.Equal(t, 2, calendar.Days[1].Flights)
+	assert.Equal(t, 1, calendar.Days[1].BookableFlights)
+	assert.Equal(t, 4, calendar.Days[1].MaxAvailableSeats)
+	assert.Equal(t, 360.0, *calendar.Days[3].MinPrice)
+	// 有航班但座位不足的日期沒有最低價
+	assert.Nil(t, calendar.Days[4].MinPrice)
+	assert.Equal(t, 1, calendar.Days[4].Flights)
+	assert.Equal(t, "2024-05-11", calendar.CheapestDate)
+	assert.Equal(t, "2024-05-08", calendar.From)
+	assert.Equal(t, "2024-05-12", calendar.To)
+}
+
+func TestFlightService_GetFareCalendar_MatchesSearch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	flights := []models.Flight{
+		{ID: 1, OriginTimezone: "UTC", DepartureTime: day.Add(8 * time.Hour), Price: 100, BusinessSeats: models.SeatInventory{Total: 10}},
+		{ID: 2, OriginTimezone: "UTC", DepartureTime: day.Add(9 * time.Hour), Price: 200, BusinessSeats: models.SeatInventory{Total: 10}},
+	}
+	for i := range flights {
+		flights[i].UpdateAvailability()
+	}
+
+	mockRepo := mocks.NewMockFlightRepository(ctrl)
+	mockRepo.EXPECT().SearchFlights(gomock.Any(), gomock.Any()).
+		Return(&models.FlightPage{Items: slices.Clone(flights), Total: len(flights)}, nil)
+	mockRepo.EXPECT().GetFlightsByDateRange(gomock.Any(), "TPE", "NRT", gomock.Any(), gomock.Any()).Return(slices.Clone(flights), nil)
+
+	// 基礎票價較低的航班公務艙售價反而較高
+	mockRedis, _ := redismock.NewClientMock()
+	service := services.NewFlightService(mockRepo, services.NewSearchCache(mockRedis, mockRepo, 15*time.Minute, services.LRUOptions{}), services.NewMemorySearchQueue(10, services.LRUOptions{}), businessPricing(ctrl, map[int]float64{1: 650, 2: 480}))
+
+	_, err := service.SearchFlights(context.Background(), models.SearchRequest{
+		Origin: "TPE", Destination: "NRT", Date: day, Cabin: "business", SortBy: models.FlightSortPrice,
+	})
+	assert.NoError(t, err)
+	job, err := service.GetSearchQueue().Dequeue(context.Background())
+	assert.NoError(t, err)
+	service.ProcessSearchJob(context.Background(), job)
+	cheapest := job.Legs[0].Flights[0].Fares["business"].Price.Amount
+
+	calendar, err := service.GetFareCalendar(context.Background(), models.FareCalendarRequest{
+		Origin: "TPE", Destination: "NRT", Date: day, FlexDays: 1, Cabin: "business",
+	})
+	assert.NoError(t, err)
+
+	// 日曆的最低價就是同一天按價格搜索的第一個航班的售價
+	assert.Equal(t, 480.0, cheapest)
+	assert.Equal(t, "2024-05-01", calendar.Days[1].Date)
+	assert.Equal(t, cheapest, *calendar.Days[1].MinPrice)
+}
+
+func TestFlightService_GetFareCalendar_Month(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockFlightRepository(ctrl)
+	mockRedis, _ := redismock.NewClientMock()
+	service := services.NewFlightService(mockRepo, services.NewSearchCache(mockRedis, mockRepo, 15*time.Minute, services.LRUOptions{}), services.NewMemorySearchQueue(1, services.LRUOptions{}), stubPricing(ctrl))
+
+	mockRepo.EXPECT().GetFlightsByDateRange(gomock.Any(), "TPE", "NRT",
+		time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)).
+		Return(nil, nil)
+
+	calendar, err := service.GetFareCalendar(context.Background(), models.FareCalendarRequest{
+		Origin: "TPE", Destination: "NRT", Month: "2024-02",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, calendar.Days, 29)
+	assert.Empty(t, calendar.CheapestDate)
+
+	_, err = service.GetFareCalendar(context.Background(), models.FareCalendarRequest{
+		Origin: "TPE", Destination: "NRT", Month: "2024-02", FlexDays: 3,
+	})
+	var validationErr *services.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func TestFlightService_SearchFlights_CacheKeyIncludesFilters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockFlightRepository(ctrl)
+	mockRepo.EXPECT().SearchFlights(gomock.Any(), gomock.Any()).Return(&models.FlightPage{}, nil).Times(2)
+
+	// 記錄每次讀緩存使用的鍵
+	mockRedis, redisMock := redismock.NewClientMock()
+	var keys []string
+	captureKey := func(expected, actual []interface{}) error {
+		keys = append(keys, actual[1].(string))
+		return nil
+	}
+	for i := 0; i < 2; i++ {
+		redisMock.ExpectMGet("flights:version:TPE:NRT:2024-05-01").SetVal([]interface{}{nil})
+		redisMock.CustomMatch(captureKey).ExpectGet("").RedisNil()
+		redisMock.CustomMatch(captureKey).ExpectSet("", nil, 15*time.Minute).SetVal("OK")
+	}
+
+	service := services.NewFlightService(mockRepo, services.NewSearchCache(mockRedis, mockRepo, 15*time.Minute, services.LRUOptions{}), services.NewMemorySearchQueue(10, services.LRUOptions{}), stubPricing(ctrl))
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, filters := range []models.SearchFilters{{MaxPrice: 500}, {MaxPrice: 800}} {
+		_, err := service.SearchFlights(context.Background(), models.SearchRequest{
+			Origin: "TPE", Destination: "NRT", Date: day, Filters: filters,
+		})
+		assert.NoError(t, err)
+
+		job, err := service.GetSearchQueue().Dequeue(context.Background())
+		assert.NoError(t, err)
+		service.ProcessSearchJob(context.Background(), job)
+	}
+
+	// 每次搜索的讀與寫使用同一個鍵，不同過濾條件使用不同的鍵
+	if assert.Len(t, keys, 4) {
+		assert.Equal(t, keys[0], keys[1])
+		assert.Equal(t, keys[2], keys[3])
+		assert.NotEqual(t, keys[0], keys[2])
+		assert.True(t, strings.HasPrefix(keys[0], "flights:TPE:NRT:2024-05-01:"))
+	}
+}
+
+func TestFlightService_SearchFlights_FilterValidation(t *testing.T) {
+	service := services.NewFlightService(nil, nil, services.NewMemorySearchQueue(10, services.LRUOptions{}), nil)
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		filters models.SearchFilters
+		sortBy  string
+		field   string
+	}{
+		{"bad clock", models.SearchFilters{DepartureAfter: "9am"}, "", "filters.departure"},
+		{"inverted window", models.SearchFilters{ArrivalAfter: "18:00", ArrivalBefore: "06:00"}, "", "filters.arrival"},
+		{"negative price", models.SearchFilters{MaxPrice: -1}, "", "filters.max_price"},
+		{"too many stops", models.SearchFilters{MaxStops: 3}, "", "filters.max_stops"},
+		{"bad carrier", models.SearchFilters{Carriers: []string{"EVA"}}, "", "filters.carriers"},
+		{"unknown sort", models.SearchFilters{}, "cheapest", "sort_by"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.SearchFlights(context.Background(), models.SearchRequest{
+				Origin: "TPE", Destination: "NRT", Date: day, Filters: tt.filters, SortBy: tt.sortBy,
+			})
+			var validationErr *services.ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.field, validationErr.Field)
+		})
+	}
+}
+
+func TestFlightService_SearchFlights_ConnectionsFiltered(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hour int) time.Time { return day.Add(time.Duration(hour) * time.Hour) }
+	segment := func(id int, carrier string, dep, arr int, price float64) models.Flight {
+		return models.Flight{
+			ID: id, Carrier: carrier, DepartureTime: at(dep), ArrivalTime: at(arr), Price: price,
+			OriginTimezone: "UTC", DestinationTimezone: "UTC",
+			Availability: models.CabinAvailability{Economy: 9},
+		}
+	}
+
+	mockRepo := mocks.NewMockFlightRepository(ctrl)
+	mockRepo.EXPECT().SearchFlights(gomock.Any(), gomock.Any()).Return(&models.FlightPage{}, nil)
+	mockRepo.EXPECT().SearchItineraries(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, req models.ItinerarySearchRequest) ([][]models.Flight, error) {
+			assert.Equal(t, 1, *req.MaxStops)
+			// 候選行程在截斷前按同一排序鍵排序
+			assert.Equal(t, models.ItinerarySortPrice, req.SortBy)
+			return [][]models.Flight{
+				{segment(1, "BR", 8, 18, 900)},                                // 直飛，不重複列出
+				{segment(2, "BR", 8, 11, 300), segment(3, "BR", 13, 17, 250)}, // 符合
+				{segment(4, "BR", 9, 12, 200), segment(5, "CI", 14, 16, 200)}, // 承運人不符
+				{segment(6, "BR", 5, 8, 100), segment(7, "BR", 10, 12, 100)},  // 出發太早
+				{segment(8, "BR", 7, 10, 400), segment(9, "BR", 11, 15, 400)}, // 超出價格
+			}, nil
+		})
+
+	mockRedis, redisMock := redismock.NewClientMock()
+	// 含轉機時緩存同時依賴航線版本與之後幾天的全局版本
+	redisMock.ExpectMGet("flights:version:TPE:LHR:2024-05-01",
+		"flights:version:2024-05-01", "flights:version:2024-05-02", "flights:version:2024-05-03").
+		SetVal([]interface{}{nil, nil, nil, nil})
+	redisMock.Regexp().ExpectGet(`^flights:TPE:LHR:2024-05-01:[0-9a-f]+:v[0-9a-f]+$`).RedisNil()
+
+	service := services.NewFlightService(mockRepo, services.NewSearchCache(mockRedis, mockRepo, 15*time.Minute, services.LRUOptions{}), services.NewMemorySearchQueue(10, services.LRUOptions{}), stubPricing(ctrl))
+	_, err := service.SearchFlights(context.Background(), models.SearchRequest{
+		Origin: "TPE", Destination: "LHR", Date: day, SortBy: models.FlightSortPrice,
+		Filters: models.SearchFilters{
+			DepartureAfter: "06:00", MaxPrice: 600, Carriers: []string{"br"}, MaxStops: 1,
+		},
+	})
+	assert.NoError(t, err)
+
+	job, err := service.GetSearchQueue().Dequeue(context.Background())
+	assert.NoError(t, err)
+	service.ProcessSearchJob(context.Background(), job)
+
+	assert.Equal(t, models.SearchJobDone, job.Status)
+	if assert.Len(t, job.Legs, 1) && assert.Len(t, job.Legs[0].Itineraries, 1) {
+		assert.Equal(t, []int{2, 3}, flightIDs(job.Legs[0].Itineraries[0].Segments))
+		assert.Equal(t, 550.0, job.Legs[0].Itineraries[0].TotalPrice)
+	}
+}
+
+func TestFlightService_SearchFlights_PricedFilterAndSort(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	// 公務艙票價與基礎票價的高低不一致：1 號的基礎票價低於上限，公務艙票價超過上限
+	businessFares := map[int]float64{1: 620, 2: 480, 3: 440, 4: 450}
+	flights := []models.Flight{
+		{ID: 1, DepartureTime: day.Add(8 * time.Hour), Price: 300},
+		{ID: 2, DepartureTime: day.Add(9 * time.Hour), Price: 100},
+		{ID: 3, DepartureTime: day.Add(10 * time.Hour), Price: 200},
+		{ID: 4, DepartureTime: day.Add(11 * time.Hour), Price: 150},
+	}
+
+	mockRepo := mocks.NewMockFlightRepository(ctrl)
+	mockRepo.EXPECT().SearchFlights(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, req models.SearchRequest) (*models.FlightPage, error) {
+			// 價格過濾與排序在定價後進行，數據庫按出發時間取出全部航班
+			assert.Equal(t, models.FlightSortDeparture, req.SortBy)
+			assert.Zero(t, req.Filters.MaxPrice)
+			assert.Empty(t, req.Cursor)
+			return &models.FlightPage{Items: slices.Clone(flights), Total: len(flights)}, nil
+		}).Times(2)
+
+	mockRedis, _ := redismock.NewClientMock()
+	service := services.NewFlightService(mockRepo, services.NewSearchCache(mockRedis, mockRepo, 15*time.Minute, services.LRUOptions{}), services.NewMemorySearchQueue(10, services.LRUOptions{}), businessPricing(ctrl, businessFares))
+
+	search := func(cursor string) models.SearchLegResult {
+		_, err := service.SearchFlights(context.Background(), models.SearchRequest{
+			Origin: "TPE", Destination: "NRT", Date: day, Cabin: "business", SortBy: models.FlightSortPrice,
+			Filters: models.SearchFilters{MaxPrice: 500}, Cursor: cursor, PageSize: 2,
+		})
+		assert.NoError(t, err)
+		job, err := service.GetSearchQueue().Dequeue(context.Background())
+		assert.NoError(t, err)
+		service.ProcessSearchJob(context.Background(), job)
+		assert.Equal(t, models.SearchJobDone, job.Status)
+		return job.Legs[0]
+	}
+
+	first := search("")
+	assert.Equal(t, []int{3, 4}, flightIDs(first.Flights))
+	assert.Equal(t, 3, first.Total)
+	assert.NotEmpty(t, first.NextCursor)
+
+	assert.False(t, first.Truncated)
+
+	second := search(first.NextCursor)
+	assert.Equal(t, []int{2}, flightIDs(second.Flights))
+	assert.Empty(t, second.NextCursor)
+}
+
+func TestFlightService_SearchFlights_PricedSearchTruncated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo := mocks.NewMockFlightRepository(ctrl)
+	// 符合條件的航班多於一次取出的上限
+	mockRepo.EXPECT().SearchFlights(gomock.Any(), gomock.Any()).
+		Return(&models.FlightPage{Items: []models.Flight{{ID: 1, DepartureTime: day, Price: 100}}, Total: 600}, nil)
+
+	mockRedis, _ := redismock.NewClientMock()
+	service := services.NewFlightService(mockRepo, services.NewSearchCache(mockRedis, mockRepo, 15*time.Minute, services.LRUOptions{}), services.NewMemorySearchQueue(10, services.LRUOptions{}), stubPricing(ctrl))
+
+	_, err := service.SearchFlights(context.Background(), models.SearchRequest{
+		Origin: "TPE", Destination: "NRT", Date: day, SortBy: models.FlightSortPrice,
+	})
+	assert.NoError(t, err)
+	job, err := service.GetSearchQueue().Dequeue(context.Background())
+	assert.NoError(t, err)
+	service.ProcessSearchJob(context.Background(), job)
+
+	// 結果只涵蓋取出的航班，明確標記而不是當作完整結果返回
+	assert.Equal(t, models.SearchJobDone, job.Status)
+	assert.True(t, job.Legs[0].Truncated)
+	assert.Equal(t, []int{1}, flightIDs(job.Legs[0].Flights))
+}
+
+func TestFlightService_SearchFlights_Cursor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	durationCursor := models.EncodeCursor(models.FlightCursor{SortBy: models.FlightSortDuration, Key: 7200, DepartureTime: day, ID: 4})
+
+	mockRepo := mocks.NewMockFlightRepository(ctrl)
+	mockRepo.EXPECT().SearchFlights(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, req models.SearchRequest) (*models.FlightPage, error) {
+			assert.Equal(t, durationCursor, req.Cursor)
+			assert.Equal(t, 2, req.PageSize)
+			return &models.FlightPage{
+				Items:      []models.Flight{{ID: 5}, {ID: 6}},
+				NextCursor: "next",
+				Total:      7,
+			}, nil
+		})
+
+	mockRedis, redisMock := redismock.NewClientMock()
+	redisMock.ExpectMGet("flights:version:TPE:NRT:2024-05-01").SetVal([]interface{}{"3"})
+	redisMock.Regexp().ExpectGet(`^flights:TPE:NRT:2024-05-01:[0-9a-f]+:v[0-9a-f]+$`).RedisNil()
+
+	service := services.NewFlightService(mockRepo, services.NewSearchCache(mockRedis, mockRepo, 15*time.Minute, services.LRUOptions{}), services.NewMemorySearchQueue(10, services.LRUOptions{}), stubPricing(ctrl))
+
+	_, err := service.SearchFlights(context.Background(), models.SearchRequest{
+		Origin: "TPE", Destination: "NRT", Date: day, SortBy: models.FlightSortDuration, Cursor: durationCursor, PageSize: 2,
+	})
+	assert.NoError(t, err)
+
+	job, err := service.GetSearchQueue().Dequeue(context.Background())
+	assert.NoError(t, err)
+	service.ProcessSearchJob(context.Background(), job)
+
+	if assert.Len(t, job.Legs, 1) {
+		assert.Equal(t, "next", job.Legs[0].NextCursor)
+		assert.Equal(t, 7, job.Legs[0].Total)
+	}
+
+	// 游標只能用於同一排序方式的單程搜索
+	invalid := []models.SearchRequest{
+		{Origin: "TPE", Destination: "NRT", Date: day, Cursor: "***"},
+		{Origin: "TPE", Destination: "NRT", Date: day, SortBy: models.FlightSortDeparture, Cursor: durationCursor},
+		{Origin: "TPE", Destination: "NRT", Date: day, ReturnDate: day.AddDate(0, 0, 3), SortBy: models.FlightSortDuration, Cursor: durationCursor},
+	}
+	for _, req := range invalid {
+		_, err := service.SearchFlights(context.Background(), req)
+		var validationErr *services.ValidationError
+		if assert.ErrorAs(t, err, &validationErr) {
+			assert.Equal(t, "cursor", validationErr.Field)
+		}
 	}
 }
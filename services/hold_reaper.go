@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"airline-booking/logger"
+
+	"go.uber.org/zap"
+)
+
+// holdReapBatchSize 是每輪最多釋放的保留數，積壓時下一輪立即繼續
+const holdReapBatchSize = 100
+
+// HoldReaper 定期釋放到期未支付的座位保留，讓放棄的購物車不再佔用座位
+type HoldReaper struct {
+	service  BookingService
+	interval time.Duration
+
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+func NewHoldReaper(service BookingService, interval time.Duration) *HoldReaper {
+	return &HoldReaper{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Start 啟動後台協程，立即返回
+func (r *HoldReaper) Start() {
+	ctx, stop := context.WithCancel(context.Background())
+	r.stop = stop
+
+	r.wg.Add(1)
+	go r.run(ctx)
+
+	logger.Info("Hold reaper started", zap.Duration("interval", r.interval))
+}
+
+// Shutdown 停止後台協程並等待正在進行的一輪結束；ctx 到期時返回 ctx 的錯誤
+func (r *HoldReaper) Shutdown(ctx context.Context) error {
+	if r.stop == nil {
+		return nil
+	}
+	r.stop()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *HoldReaper) run(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		r.reap(ctx)
+	}
+}
+
+// reap 反覆釋放到期的保留，直到不足一批或出錯
+func (r *HoldReaper) reap(ctx context.Context) {
+	for ctx.Err() == nil {
+		released, err := r.service.ReleaseExpiredHolds(ctx, holdReapBatchSize)
+		if released > 0 {
+			logger.Info("Released expired holds", zap.Int("count", released))
+		}
+		if err != nil {
+			logger.Error("Failed to release expired holds", zap.Error(err))
+			return
+		}
+		if released < holdReapBatchSize {
+			return
+		}
+	}
+}
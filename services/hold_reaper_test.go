@@ -0,0 +1,68 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"airline-booking/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stubBookingService 只實現清理需要的方法，release 決定每次呼叫釋放的數量
+type stubBookingService struct {
+	services.BookingService
+	calls   atomic.Int64
+	release func(call int64) (int, error)
+}
+
+func (s *stubBookingService) ReleaseExpiredHolds(ctx context.Context, limit int) (int, error) {
+	return s.release(s.calls.Add(1))
+}
+
+func TestHoldReaper_DrainsBacklog(t *testing.T) {
+	// 前兩輪各釋放一整批，說明還有積壓，第三批不足一批後等待下一個週期
+	service := &stubBookingService{release: func(call int64) (int, error) {
+		if call <= 2 {
+			return 100, nil
+		}
+		return 3, nil
+	}}
+
+	reaper := services.NewHoldReaper(service, 10*time.Millisecond)
+	reaper.Start()
+
+	assert.Eventually(t, func() bool { return service.calls.Load() >= 3 }, time.Second, 5*time.Millisecond)
+	assert.NoError(t, reaper.Shutdown(context.Background()))
+}
+
+func TestHoldReaper_StopsRoundOnError(t *testing.T) {
+	// 出錯時即使釋放了一整批也結束本輪，不在故障期間空轉
+	service := &stubBookingService{release: func(call int64) (int, error) {
+		return 100, errors.New("database unavailable")
+	}}
+
+	reaper := services.NewHoldReaper(service, 50*time.Millisecond)
+	reaper.Start()
+
+	assert.Eventually(t, func() bool { return service.calls.Load() >= 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int64(1), service.calls.Load())
+	assert.NoError(t, reaper.Shutdown(context.Background()))
+}
+
+func TestHoldReaper_WaitsForInterval(t *testing.T) {
+	service := &stubBookingService{release: func(call int64) (int, error) {
+		return 0, nil
+	}}
+
+	reaper := services.NewHoldReaper(service, time.Hour)
+	reaper.Start()
+	assert.NoError(t, reaper.Shutdown(context.Background()))
+
+	// 週期未到時不會執行
+	assert.Equal(t, int64(0), service.calls.Load())
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRUOptions 是進程內 LRU 緩存的容量限制，任何一項為 0 表示不限制該項
+type LRUOptions struct {
+	// MaxEntries 是最多保存的條目數
+	MaxEntries int
+	// MaxBytes 是所有條目估算大小的上限
+	MaxBytes int64
+	// TTL 是條目寫入後的存活時間
+	TTL time.Duration
+}
+
+// CacheTierStats 是單層緩存的命中與淘汰計數
+type CacheTierStats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions,omitempty"`
+	Entries   int   `json:"entries,omitempty"`
+	Bytes     int64 `json:"bytes,omitempty"`
+}
+
+// LRUCache 是帶 TTL 與容量上限的進程內緩存。
+// 超出條目數或大小上限時從最久未使用的條目開始淘汰。過期條目在讀到時刪除，
+// 未被讀到的過期條目按使用順序被擠出，佔用的空間仍受上限約束。
+type LRUCache[V any] struct {
+	opts   LRUOptions
+	sizeOf func(V) int64
+
+	mutex sync.Mutex
+	order *list.List
+	items map[string]*list.Element
+	bytes int64
+
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+type lruEntry[V any] struct {
+	key       string
+	value     V
+	size      int64
+	expiresAt time.Time
+}
+
+// NewLRUCache 創建 LRU 緩存，sizeOf 估算單個值佔用的字節數，用於 MaxBytes 限制
+func NewLRUCache[V any](opts LRUOptions, sizeOf func(V) int64) *LRUCache[V] {
+	return &LRUCache[V]{
+		opts:   opts,
+		sizeOf: sizeOf,
+		order:  list.New(),
+		items:  make(map[string]*list.Element),
+	}
+}
+
+// Get 返回未過期的值並將其標記為最近使用
+func (c *LRUCache[V]) Get(key string) (V, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.items[key]
+	if ok && c.expired(elem.Value.(*lruEntry[V])) {
+		c.removeElement(elem)
+		ok = false
+	}
+	if !ok {
+		c.misses++
+		var zero V
+		return zero, false
+	}
+
+	c.hits++
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry[V]).value, true
+}
+
+// Set 寫入或覆蓋值並重新計算存活時間。單個值超過 MaxBytes 時不保存
+func (c *LRUCache[V]) Set(key string, value V) {
+	size := c.sizeOf(value)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+	if c.opts.MaxBytes > 0 && size > c.opts.MaxBytes {
+		return
+	}
+
+	entry := &lruEntry[V]{key: key, value: value, size: size}
+	if c.opts.TTL > 0 {
+		entry.expiresAt = time.Now().Add(c.opts.TTL)
+	}
+	c.items[key] = c.order.PushFront(entry)
+	c.bytes += size
+
+	for (c.opts.MaxEntries > 0 && c.order.Len() > c.opts.MaxEntries) ||
+		(c.opts.MaxBytes > 0 && c.bytes > c.opts.MaxBytes) {
+		c.removeElement(c.order.Back())
+		c.evictions++
+	}
+}
+
+// Delete 刪除 key，不存在時不做任何事
+func (c *LRUCache[V]) Delete(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// DeleteFunc 刪除所有 match 返回 true 的條目並返回刪除的數量
+func (c *LRUCache[V]) DeleteFunc(match func(key string, value V) bool) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	deleted := 0
+	for elem := c.order.Front(); elem != nil; {
+		next := elem.Next()
+		entry := elem.Value.(*lruEntry[V])
+		if match(entry.key, entry.value) {
+			c.removeElement(elem)
+			deleted++
+		}
+		elem = next
+	}
+	return deleted
+}
+
+// Stats 返回累計的命中、未命中與淘汰次數以及當前的條目數與大小
+func (c *LRUCache[V]) Stats() CacheTierStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return CacheTierStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Entries:   c.order.Len(),
+		Bytes:     c.bytes,
+	}
+}
+
+func (c *LRUCache[V]) expired(entry *lruEntry[V]) bool {
+	return !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt)
+}
+
+func (c *LRUCache[V]) removeElement(elem *list.Element) {
+	entry := c.order.Remove(elem).(*lruEntry[V])
+	delete(c.items, entry.key)
+	c.bytes -= entry.size
+}
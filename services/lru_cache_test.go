@@ -0,0 +1,67 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"airline-booking/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func byteLen(v []byte) int64 {
+	return int64(len(v))
+}
+
+func TestLRUCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := services.NewLRUCache(services.LRUOptions{MaxEntries: 2}, byteLen)
+	cache.Set("a", []byte("1"))
+	cache.Set("b", []byte("2"))
+
+	// 讀取 a 之後 b 成為最久未使用的條目
+	_, ok := cache.Get("a")
+	assert.True(t, ok)
+	cache.Set("c", []byte("3"))
+
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+	_, ok = cache.Get("a")
+	assert.True(t, ok)
+	_, ok = cache.Get("c")
+	assert.True(t, ok)
+
+	assert.Equal(t, services.CacheTierStats{Hits: 3, Misses: 1, Evictions: 1, Entries: 2, Bytes: 2}, cache.Stats())
+}
+
+func TestLRUCache_MaxBytes(t *testing.T) {
+	cache := services.NewLRUCache(services.LRUOptions{MaxBytes: 10}, byteLen)
+	cache.Set("a", []byte("1234"))
+	cache.Set("b", []byte("5678"))
+	// 超過上限時淘汰最舊的 a
+	cache.Set("c", []byte("90"))
+	cache.Set("c", []byte("9012"))
+	// 單個值超過上限時不保存
+	cache.Set("huge", []byte("12345678901"))
+
+	_, ok := cache.Get("a")
+	assert.False(t, ok)
+	_, ok = cache.Get("huge")
+	assert.False(t, ok)
+
+	stats := cache.Stats()
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, int64(8), stats.Bytes)
+}
+
+func TestLRUCache_TTL(t *testing.T) {
+	cache := services.NewLRUCache(services.LRUOptions{TTL: 20 * time.Millisecond}, byteLen)
+	cache.Set("a", []byte("1"))
+
+	_, ok := cache.Get("a")
+	assert.True(t, ok)
+
+	time.Sleep(30 * time.Millisecond)
+	_, ok = cache.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Stats().Entries)
+}
@@ -22,20 +22,26 @@ type OverbookingService interface {
 }
 
 type overbookingService struct {
+	txManager     repositories.TxManager
 	flightRepo    repositories.FlightRepository
 	bookingRepo   repositories.BookingRepository
 	notifyService NotificationService
+	searchCache   SearchCache
 }
 
 func NewOverbookingService(
+	txManager repositories.TxManager,
 	flightRepo repositories.FlightRepository,
 	bookingRepo repositories.BookingRepository,
 	notifyService NotificationService,
+	searchCache SearchCache,
 ) OverbookingService {
 	return &overbookingService{
+		txManager:     txManager,
 		flightRepo:    flightRepo,
 		bookingRepo:   bookingRepo,
 		notifyService: notifyService,
+		searchCache:   searchCache,
 	}
 }
 
@@ -72,26 +78,36 @@ func (s *overbookingService) HandleOverbooking(ctx context.Context, flightID int
 }
 
 func (s *overbookingService) AdjustOverbookingRatio(ctx context.Context, flightID int) error {
-	flight, err := s.flightRepo.GetFlightByID(ctx, flightID)
-	if err != nil {
-		return err
-	}
+	// UpdateFlight 會寫回整行資料，因此需要鎖住航班，避免覆蓋並發預訂對已訂座位數的修改
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		flight, err := s.flightRepo.GetFlightByIDForUpdate(ctx, flightID)
+		if err != nil {
+			return err
+		}
 
-	historicalData, err := s.flightRepo.GetHistoricalNoShowRate(ctx, flight.Route(), flight.DepartureTime.Weekday())
-	if err != nil {
-		return err
-	}
+		historicalData, err := s.flightRepo.GetHistoricalNoShowRate(ctx, flight.Route(), flight.DepartureTime.Weekday())
+		if err != nil {
+			return err
+		}
 
-	currentBookingTrend, err := s.bookingRepo.GetCurrentBookingTrend(ctx, flightID)
+		currentBookingTrend, err := s.bookingRepo.GetCurrentBookingTrend(ctx, flightID)
+		if err != nil {
+			return err
+		}
+
+		flight.EconomySeats.OverbookingRatio = s.calculateOptimalRatio(historicalData, currentBookingTrend, "economy")
+		flight.BusinessSeats.OverbookingRatio = s.calculateOptimalRatio(historicalData, currentBookingTrend, "business")
+		flight.FirstClassSeats.OverbookingRatio = s.calculateOptimalRatio(historicalData, currentBookingTrend, "first")
+
+		return s.flightRepo.UpdateFlight(ctx, flight)
+	})
 	if err != nil {
 		return err
 	}
 
-	flight.EconomySeats.OverbookingRatio = s.calculateOptimalRatio(historicalData, currentBookingTrend, "economy")
-	flight.BusinessSeats.OverbookingRatio = s.calculateOptimalRatio(historicalData, currentBookingTrend, "business")
-	flight.FirstClassSeats.OverbookingRatio = s.calculateOptimalRatio(historicalData, currentBookingTrend, "first")
-
-	return s.flightRepo.UpdateFlight(ctx, flight)
+	// 超賣比例決定可售座位數，提交後讓搜索緩存失效
+	s.searchCache.InvalidateFlight(ctx, flightID)
+	return nil
 }
 
 func (s *overbookingService) AssessRisk(ctx context.Context, booking *models.Booking) (float64, error) {
@@ -116,7 +132,14 @@ func (s *overbookingService) AssessRisk(ctx context.Context, booking *models.Boo
 	}
 
 	// 考慮預訂時間與起飛時間的接近程度
-	timeUntilDeparture := time.Until(booking.Flight.DepartureTime)
+	flight := booking.Flight
+	if flight == nil {
+		flight, err = s.flightRepo.GetFlightByID(ctx, booking.FlightID)
+		if err != nil {
+			return 0, err
+		}
+	}
+	timeUntilDeparture := time.Until(flight.DepartureTime)
 	if timeUntilDeparture < 24*time.Hour {
 		riskScore -= 0.3
 	}
@@ -125,17 +148,26 @@ func (s *overbookingService) AssessRisk(ctx context.Context, booking *models.Boo
 }
 
 func (s *overbookingService) getOverbookedBookings(ctx context.Context, flight *models.Flight) ([]*models.Booking, error) {
-	allBookings, err := s.bookingRepo.GetBookingsByFlight(ctx, flight.ID)
+	bookings, err := s.bookingRepo.GetBookingsByFlight(ctx, flight.ID)
 	if err != nil {
 		return nil, err
 	}
 
+	// 已取消與保留過期的預訂不佔用座位
+	var allBookings []*models.Booking
+	for _, booking := range bookings {
+		if booking.HoldsInventory() {
+			allBookings = append(allBookings, booking)
+		}
+	}
+
 	var overbooked []*models.Booking
 	totalSeats := flight.EconomySeats.Total + flight.BusinessSeats.Total + flight.FirstClassSeats.Total
 
 	if len(allBookings) > totalSeats {
 		overbooked = allBookings[totalSeats:]
 		for _, booking := range overbooked {
+			booking.Flight = flight
 			riskScore, err := s.AssessRisk(ctx, booking)
 			if err != nil {
 				logger.Error("Failed to assess risk for booking", zap.Error(err), zap.Int("bookingID", booking.ID))
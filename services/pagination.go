@@ -0,0 +1,30 @@
+package services
+
+import (
+	"fmt"
+
+	"airline-booking/models"
+)
+
+// 所有列表與搜索接口共用的分頁大小
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// normalizePage 填充默認分頁大小並檢查上限；cursor 不為空時解碼到 key，
+// 讓格式錯誤的游標在查詢前就以 400 返回
+func normalizePage(cursor string, pageSize *int, key interface{}) error {
+	if *pageSize == 0 {
+		*pageSize = defaultPageSize
+	}
+	if *pageSize < 0 || *pageSize > maxPageSize {
+		return &ValidationError{Field: "page_size", Message: fmt.Sprintf("must be between 1 and %d", maxPageSize)}
+	}
+	if cursor != "" {
+		if err := models.DecodeCursor(cursor, key); err != nil {
+			return &ValidationError{Field: "cursor", Message: "is malformed"}
+		}
+	}
+	return nil
+}
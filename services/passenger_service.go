@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"airline-booking/models"
+	"airline-booking/repositories"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	// 允許國際區號前綴以及空格、連字號分隔，去掉分隔符後需有 7 到 15 位數字（E.164）
+	phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 \-]{5,20}$`)
+)
+
+type PassengerService interface {
+	RegisterPassenger(ctx context.Context, passenger *models.Passenger) error
+	GetPassenger(ctx context.Context, passengerID int) (*models.Passenger, error)
+	UpdatePassenger(ctx context.Context, passengerID int, update models.PassengerUpdate) (*models.Passenger, error)
+	SearchPassengers(ctx context.Context, filter models.PassengerFilter) (*models.PassengerPage, error)
+	GetPassengerHistory(ctx context.Context, passengerID int) (*models.PassengerHistory, error)
+}
+
+type passengerService struct {
+	passengerRepo repositories.PassengerRepository
+	bookingRepo   repositories.BookingRepository
+}
+
+func NewPassengerService(
+	passengerRepo repositories.PassengerRepository,
+	bookingRepo repositories.BookingRepository,
+) PassengerService {
+	return &passengerService{
+		passengerRepo: passengerRepo,
+		bookingRepo:   bookingRepo,
+	}
+}
+
+func (s *passengerService) RegisterPassenger(ctx context.Context, passenger *models.Passenger) error {
+	if strings.TrimSpace(passenger.FirstName) == "" {
+		return &ValidationError{Field: "first_name", Message: "is required"}
+	}
+	if strings.TrimSpace(passenger.LastName) == "" {
+		return &ValidationError{Field: "last_name", Message: "is required"}
+	}
+	if err := validateContact(passenger.Email, passenger.PhoneNumber); err != nil {
+		return err
+	}
+	if passenger.PassportNumber != "" || !passenger.PassportExpiry.IsZero() {
+		if err := validatePassport(passenger.PassportNumber, passenger.PassportExpiry); err != nil {
+			return err
+		}
+	}
+
+	// 統計與積分由系統維護，註冊時一律從零開始
+	passenger.FrequentFlyerPoints = 0
+	passenger.TotalFlights = 0
+	passenger.TotalSpent = 0
+	passenger.LastFlightDate = time.Time{}
+
+	return s.passengerRepo.CreatePassenger(ctx, passenger)
+}
+
+func (s *passengerService) GetPassenger(ctx context.Context, passengerID int) (*models.Passenger, error) {
+	return s.passengerRepo.GetPassengerByID(ctx, passengerID)
+}
+
+func (s *passengerService) UpdatePassenger(ctx context.Context, passengerID int, update models.PassengerUpdate) (*models.Passenger, error) {
+	passenger, err := s.passengerRepo.GetPassengerByID(ctx, passengerID)
+	if err != nil {
+		return nil, err
+	}
+
+	applyPassengerUpdate(passenger, update)
+
+	if update.Email != nil || update.PhoneNumber != nil {
+		if err := validateContact(passenger.Email, passenger.PhoneNumber); err != nil {
+			return nil, err
+		}
+	}
+	if update.PassportNumber != nil || update.PassportExpiry != nil {
+		if err := validatePassport(passenger.PassportNumber, passenger.PassportExpiry); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.passengerRepo.UpdatePassenger(ctx, passenger); err != nil {
+		return nil, err
+	}
+	return passenger, nil
+}
+
+func (s *passengerService) SearchPassengers(ctx context.Context, filter models.PassengerFilter) (*models.PassengerPage, error) {
+	if err := normalizePage(filter.Cursor, &filter.PageSize, &models.PassengerCursor{}); err != nil {
+		return nil, err
+	}
+
+	return s.passengerRepo.ListPassengers(ctx, filter)
+}
+
+func (s *passengerService) GetPassengerHistory(ctx context.Context, passengerID int) (*models.PassengerHistory, error) {
+	// 預訂儲存庫的歷史包含取消率、常飛航線等由預訂記錄推導的統計
+	return s.bookingRepo.GetPassengerHistory(ctx, passengerID)
+}
+
+func validateContact(email, phone string) error {
+	if !emailPattern.MatchString(email) {
+		return &ValidationError{Field: "email", Message: "is not a valid email address"}
+	}
+	if phone == "" {
+		return nil
+	}
+	digits := strings.NewReplacer(" ", "", "-", "", "+", "").Replace(phone)
+	if !phonePattern.MatchString(phone) || len(digits) < 7 || len(digits) > 15 {
+		return &ValidationError{Field: "phone_number", Message: "must contain 7 to 15 digits, optionally prefixed with +"}
+	}
+	return nil
+}
+
+func validatePassport(number string, expiry time.Time) error {
+	if strings.TrimSpace(number) == "" {
+		return &ValidationError{Field: "passport_number", Message: "is required when passport_expiry is set"}
+	}
+	if expiry.IsZero() {
+		return &ValidationError{Field: "passport_expiry", Message: "is required when passport_number is set"}
+	}
+	if !expiry.After(time.Now()) {
+		return &ValidationError{Field: "passport_expiry", Message: "passport has expired"}
+	}
+	return nil
+}
+
+func applyPassengerUpdate(p *models.Passenger, u models.PassengerUpdate) {
+	if u.Email != nil {
+		p.Email = *u.Email
+	}
+	if u.PhoneNumber != nil {
+		p.PhoneNumber = *u.PhoneNumber
+	}
+	if u.Address != nil {
+		p.Address = *u.Address
+	}
+	if u.City != nil {
+		p.City = *u.City
+	}
+	if u.Country != nil {
+		p.Country = *u.Country
+	}
+	if u.PostalCode != nil {
+		p.PostalCode = *u.PostalCode
+	}
+	if u.PassportNumber != nil {
+		p.PassportNumber = *u.PassportNumber
+	}
+	if u.PassportExpiry != nil {
+		p.PassportExpiry = *u.PassportExpiry
+	}
+	if u.FrequentFlyerTier != nil {
+		p.FrequentFlyerTier = *u.FrequentFlyerTier
+	}
+	if u.SpecialMealPreference != nil {
+		p.SpecialMealPreference = *u.SpecialMealPreference
+	}
+	if u.SeatPreference != nil {
+		p.SeatPreference = *u.SeatPreference
+	}
+	if u.SpecialAssistance != nil {
+		p.SpecialAssistance = *u.SpecialAssistance
+	}
+	if u.MarketingConsent != nil {
+		p.MarketingConsent = *u.MarketingConsent
+	}
+	if u.PreferredLanguage != nil {
+		p.PreferredLanguage = *u.PreferredLanguage
+	}
+}
@@ -0,0 +1,99 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"airline-booking/mocks"
+	"airline-booking/models"
+	"airline-booking/services"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPassengerService_RegisterPassenger_Validation(t *testing.T) {
+	valid := func() *models.Passenger {
+		return &models.Passenger{
+			FirstName:      "Ada",
+			LastName:       "Lovelace",
+			Email:          "ada@example.com",
+			PhoneNumber:    "+44 20 7946 0958",
+			PassportNumber: "X1234567",
+			PassportExpiry: time.Now().AddDate(5, 0, 0),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(p *models.Passenger)
+		field  string
+	}{
+		{"invalid email", func(p *models.Passenger) { p.Email = "ada.example.com" }, "email"},
+		{"too few digits", func(p *models.Passenger) { p.PhoneNumber = "+44 12" }, "phone_number"},
+		{"letters in phone", func(p *models.Passenger) { p.PhoneNumber = "call-me-maybe" }, "phone_number"},
+		{"expired passport", func(p *models.Passenger) { p.PassportExpiry = time.Now().AddDate(0, 0, -1) }, "passport_expiry"},
+		{"expiry without number", func(p *models.Passenger) { p.PassportNumber = "" }, "passport_number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repo := mocks.NewMockPassengerRepository(ctrl)
+			repo.EXPECT().CreatePassenger(gomock.Any(), gomock.Any()).Times(0)
+			service := services.NewPassengerService(repo, mocks.NewMockBookingRepository(ctrl))
+
+			p := valid()
+			tt.modify(p)
+			err := service.RegisterPassenger(context.Background(), p)
+
+			var validationErr *services.ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Equal(t, tt.field, validationErr.Field)
+			}
+		})
+	}
+}
+
+func TestPassengerService_RegisterPassenger_ResetsStatistics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockPassengerRepository(ctrl)
+	repo.EXPECT().CreatePassenger(gomock.Any(), gomock.Any()).Return(nil)
+	service := services.NewPassengerService(repo, mocks.NewMockBookingRepository(ctrl))
+
+	p := &models.Passenger{
+		FirstName:           "Ada",
+		LastName:            "Lovelace",
+		Email:               "ada@example.com",
+		FrequentFlyerPoints: 100000,
+		TotalSpent:          99999,
+	}
+	err := service.RegisterPassenger(context.Background(), p)
+
+	assert.NoError(t, err)
+	assert.Zero(t, p.FrequentFlyerPoints)
+	assert.Zero(t, p.TotalSpent)
+}
+
+func TestPassengerService_SearchPassengers_Pagination(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockPassengerRepository(ctrl)
+	service := services.NewPassengerService(repo, mocks.NewMockBookingRepository(ctrl))
+
+	repo.EXPECT().ListPassengers(gomock.Any(), models.PassengerFilter{PageSize: 20}).Return(&models.PassengerPage{}, nil)
+	_, err := service.SearchPassengers(context.Background(), models.PassengerFilter{})
+	assert.NoError(t, err)
+
+	// 游標原樣傳給儲存庫
+	cursor := models.EncodeCursor(models.PassengerCursor{LastName: "Chen", FirstName: "Amy", ID: 7})
+	repo.EXPECT().ListPassengers(gomock.Any(), models.PassengerFilter{Cursor: cursor, PageSize: 5}).Return(&models.PassengerPage{}, nil)
+	_, err = service.SearchPassengers(context.Background(), models.PassengerFilter{Cursor: cursor, PageSize: 5})
+	assert.NoError(t, err)
+
+	for _, filter := range []models.PassengerFilter{{PageSize: 1000}, {PageSize: -1}, {Cursor: "not a cursor"}} {
+		_, err = service.SearchPassengers(context.Background(), filter)
+		var validationErr *services.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	}
+}
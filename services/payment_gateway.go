@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"airline-booking/models"
+)
+
+// PaymentGateway 是外部支付網關的抽象：先預授權，再扣款或撤銷，扣款後可以分次退款。
+// 授權之後的操作都以 Authorize 返回的授權 ID 為準
+type PaymentGateway interface {
+	// Authorize 以支付方式令牌預授權 amount 並返回授權 ID，同一個 idempotencyKey 的重試返回同一個授權。
+	// 被拒時返回 *PaymentDeclinedError，沒有得到明確結果時返回 ErrPaymentGatewayTimeout
+	Authorize(ctx context.Context, idempotencyKey, paymentMethod string, amount models.Money) (string, error)
+	// Capture 扣取預授權的金額，amount 不能超過授權金額
+	Capture(ctx context.Context, reference string, amount models.Money) error
+	// Void 撤銷尚未扣款的預授權
+	Void(ctx context.Context, reference string) error
+	// Refund 退回已扣款的部分或全部金額，累計退款不能超過扣款金額；同一個 idempotencyKey 的重試只退款一次
+	Refund(ctx context.Context, idempotencyKey, reference string, amount models.Money) error
+}
+
+// FakePaymentGateway 的支付方式令牌，其他令牌的預授權總是成功
+const (
+	// FakeCardDeclined 的預授權被拒
+	FakeCardDeclined = "tok_declined"
+	// FakeCardInsufficientFunds 的預授權因餘額不足被拒
+	FakeCardInsufficientFunds = "tok_insufficient_funds"
+	// FakeCardTimeout 的預授權總是超時
+	FakeCardTimeout = "tok_timeout"
+	// FakeCardTimeoutOnce 的預授權在網關完成授權後返回超時，以同一個冪等鍵重試時返回該授權
+	FakeCardTimeoutOnce = "tok_timeout_once"
+)
+
+// fakeAuthorization 是假網關記錄的一筆授權，金額以分為單位
+type fakeAuthorization struct {
+	authorized int64
+	captured   int64
+	refunded   int64
+	voided     bool
+}
+
+// FakePaymentGateway 是確定性的進程內支付網關，按支付方式令牌模擬成功、被拒與超時，
+// 用於本地開發與測試，不連接任何外部服務
+type FakePaymentGateway struct {
+	mu             sync.Mutex
+	seq            int
+	authorizations map[string]*fakeAuthorization
+	// byKey 記錄冪等鍵對應的授權 ID
+	byKey map[string]string
+	// refundKeys 記錄已完成的退款的冪等鍵
+	refundKeys map[string]bool
+}
+
+func NewFakePaymentGateway() *FakePaymentGateway {
+	return &FakePaymentGateway{
+		authorizations: make(map[string]*fakeAuthorization),
+		byKey:          make(map[string]string),
+		refundKeys:     make(map[string]bool),
+	}
+}
+
+func (g *FakePaymentGateway) Authorize(ctx context.Context, idempotencyKey, paymentMethod string, amount models.Money) (string, error) {
+	if ctx.Err() != nil {
+		return "", ErrPaymentGatewayTimeout
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if reference, ok := g.byKey[idempotencyKey]; ok {
+		return reference, nil
+	}
+
+	switch paymentMethod {
+	case FakeCardDeclined:
+		return "", &PaymentDeclinedError{Reason: "card_declined"}
+	case FakeCardInsufficientFunds:
+		return "", &PaymentDeclinedError{Reason: "insufficient_funds"}
+	case FakeCardTimeout:
+		return "", ErrPaymentGatewayTimeout
+	}
+
+	g.seq++
+	reference := fmt.Sprintf("fake_auth_%d", g.seq)
+	g.authorizations[reference] = &fakeAuthorization{authorized: cents(amount.Amount)}
+	g.byKey[idempotencyKey] = reference
+
+	// 授權已經完成，但回應在途中丟失
+	if paymentMethod == FakeCardTimeoutOnce {
+		return "", ErrPaymentGatewayTimeout
+	}
+	return reference, nil
+}
+
+func (g *FakePaymentGateway) Capture(ctx context.Context, reference string, amount models.Money) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	auth, err := g.authorization(reference)
+	if err != nil {
+		return err
+	}
+	if auth.voided || auth.captured > 0 {
+		return fmt.Errorf("authorization %s cannot be captured", reference)
+	}
+	if cents(amount.Amount) > auth.authorized {
+		return fmt.Errorf("capture of %.2f exceeds the authorized amount", amount.Amount)
+	}
+	auth.captured = cents(amount.Amount)
+	return nil
+}
+
+func (g *FakePaymentGateway) Void(ctx context.Context, reference string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	auth, err := g.authorization(reference)
+	if err != nil {
+		return err
+	}
+	if auth.captured > 0 {
+		return fmt.Errorf("authorization %s is already captured", reference)
+	}
+	auth.voided = true
+	return nil
+}
+
+func (g *FakePaymentGateway) Refund(ctx context.Context, idempotencyKey, reference string, amount models.Money) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.refundKeys[idempotencyKey] {
+		return nil
+	}
+	auth, err := g.authorization(reference)
+	if err != nil {
+		return err
+	}
+	if cents(amount.Amount) <= 0 || auth.refunded+cents(amount.Amount) > auth.captured {
+		return fmt.Errorf("refund of %.2f exceeds the captured amount", amount.Amount)
+	}
+	auth.refunded += cents(amount.Amount)
+	g.refundKeys[idempotencyKey] = true
+	return nil
+}
+
+func (g *FakePaymentGateway) authorization(reference string) (*fakeAuthorization, error) {
+	auth, ok := g.authorizations[reference]
+	if !ok {
+		return nil, fmt.Errorf("unknown authorization %s", reference)
+	}
+	return auth, nil
+}
+
+// cents 把金額換算為分，避免浮點數累加的誤差
+func cents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
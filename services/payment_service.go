@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"airline-booking/models"
+	"airline-booking/repositories"
+)
+
+// maxIdempotencyKeyLength 與 payments.idempotency_key 的欄位長度一致
+const maxIdempotencyKeyLength = 255
+
+// PaymentService 通過支付網關收取預訂的票款並記錄每筆付款的狀態。
+// 付款狀態按 models.Payment 的狀態機轉換，網關操作成功後才寫入新狀態
+type PaymentService interface {
+	// Authorize 為預訂預授權 booking.Price。同一個冪等鍵的重試返回同一筆付款：
+	// pending 的付款以同一個鍵重新請求網關，其他狀態的付款原樣返回。
+	// 被拒時返回狀態為 declined 的付款與 *PaymentDeclinedError，網關超時時付款保持 pending
+	Authorize(ctx context.Context, booking *models.Booking, req models.PaymentRequest) (*models.Payment, error)
+	// Capture 扣取已預授權的付款
+	Capture(ctx context.Context, payment *models.Payment) error
+	// Void 撤銷尚未扣款的預授權
+	Void(ctx context.Context, payment *models.Payment) error
+	// RequestRefund 從預訂已扣款的付款中預留 amount 的退款並記錄為 pending，不請求網關；
+	// 需與導致退款的變更在同一個事務中呼叫，事務提交後再呼叫 SubmitRefunds。冪等鍵由 purpose、
+	// 預訂與付款得出，同一用途對同一筆付款的重複請求返回同一筆退款。沒有已扣款的付款時返回 ErrPaymentNotCollected
+	RequestRefund(ctx context.Context, bookingID int, amount float64, purpose string) (*models.Refund, error)
+	// SubmitRefunds 以各自的冪等鍵向網關提交預訂所有 pending 的退款並標記為 completed，返回本次完成的退款。
+	// 可以重複呼叫，失敗時未完成的退款保持 pending
+	SubmitRefunds(ctx context.Context, bookingID int) ([]*models.Refund, error)
+	// ListPayments 按創建先後返回預訂的所有付款
+	ListPayments(ctx context.Context, bookingID int) ([]*models.Payment, error)
+}
+
+type paymentService struct {
+	paymentRepo repositories.PaymentRepository
+	gateway     PaymentGateway
+}
+
+func NewPaymentService(paymentRepo repositories.PaymentRepository, gateway PaymentGateway) PaymentService {
+	return &paymentService{
+		paymentRepo: paymentRepo,
+		gateway:     gateway,
+	}
+}
+
+func (s *paymentService) Authorize(ctx context.Context, booking *models.Booking, req models.PaymentRequest) (*models.Payment, error) {
+	if req.IdempotencyKey == "" || len(req.IdempotencyKey) > maxIdempotencyKeyLength {
+		return nil, &ValidationError{Field: "idempotency_key", Message: fmt.Sprintf("is required and must be at most %d characters", maxIdempotencyKeyLength)}
+	}
+	if req.PaymentMethod == "" {
+		return nil, &ValidationError{Field: "payment_method", Message: "is required"}
+	}
+
+	payment, err := s.paymentRepo.GetPaymentByIdempotencyKey(ctx, req.IdempotencyKey)
+	switch {
+	case err == nil:
+		if payment.BookingID != booking.ID {
+			return nil, &ValidationError{Field: "idempotency_key", Message: "was already used for another booking"}
+		}
+		if payment.Status == models.PaymentDeclined {
+			return payment, &PaymentDeclinedError{Reason: payment.FailureReason}
+		}
+		if payment.Status != models.PaymentPending {
+			return payment, nil
+		}
+	case errors.Is(err, repositories.ErrNotFound):
+		// 先記錄 pending 的付款再請求網關，網關超時後仍可以用同一個鍵找回這筆付款
+		payment = &models.Payment{
+			BookingID:      booking.ID,
+			IdempotencyKey: req.IdempotencyKey,
+			Status:         models.PaymentPending,
+			Amount:         booking.Price,
+			Refunded:       models.Money{Currency: booking.Price.Currency},
+		}
+		if err := s.paymentRepo.CreatePayment(ctx, payment); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	reference, err := s.gateway.Authorize(ctx, payment.IdempotencyKey, req.PaymentMethod, payment.Amount)
+	var declined *PaymentDeclinedError
+	switch {
+	case errors.As(err, &declined):
+		payment.FailureReason = declined.Reason
+		if err := s.transition(ctx, payment, models.PaymentDeclined); err != nil {
+			return nil, err
+		}
+		return payment, declined
+	case err != nil:
+		return payment, err
+	}
+
+	payment.GatewayReference = reference
+	if err := s.transition(ctx, payment, models.PaymentAuthorized); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+func (s *paymentService) Capture(ctx context.Context, payment *models.Payment) error {
+	if !payment.CanTransition(models.PaymentCaptured) {
+		return invalidTransition(payment, models.PaymentCaptured)
+	}
+	if err := s.gateway.Capture(ctx, payment.GatewayReference, payment.Amount); err != nil {
+		return err
+	}
+	return s.transition(ctx, payment, models.PaymentCaptured)
+}
+
+func (s *paymentService) Void(ctx context.Context, payment *models.Payment) error {
+	if !payment.CanTransition(models.PaymentVoided) {
+		return invalidTransition(payment, models.PaymentVoided)
+	}
+	if err := s.gateway.Void(ctx, payment.GatewayReference); err != nil {
+		return err
+	}
+	return s.transition(ctx, payment, models.PaymentVoided)
+}
+
+func (s *paymentService) RequestRefund(ctx context.Context, bookingID int, amount float64, purpose string) (*models.Refund, error) {
+	payments, err := s.paymentRepo.GetPaymentsByBooking(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	var payment *models.Payment
+	for _, p := range payments {
+		if p.Collected() {
+			payment = p
+			break
+		}
+	}
+	if payment == nil {
+		return nil, ErrPaymentNotCollected
+	}
+
+	key := fmt.Sprintf("refund:%s:%d:%d", purpose, bookingID, payment.ID)
+	refund, err := s.paymentRepo.GetRefundByIdempotencyKey(ctx, key)
+	switch {
+	case err == nil:
+		return refund, nil
+	case !errors.Is(err, repositories.ErrNotFound):
+		return nil, err
+	}
+
+	amount = roundCents(amount)
+	if amount <= 0 {
+		return nil, &ValidationError{Field: "amount", Message: "must be positive"}
+	}
+	if amount > payment.Refundable() {
+		return nil, &ValidationError{Field: "amount", Message: fmt.Sprintf("exceeds the refundable amount %.2f", payment.Refundable())}
+	}
+
+	// 退款金額在記錄時就從付款中扣除，之後的退款不會超出扣款金額
+	payment.Refunded.Amount = roundCents(payment.Refunded.Amount + amount)
+	status := models.PaymentPartiallyRefunded
+	if payment.Refundable() == 0 {
+		status = models.PaymentRefunded
+	}
+	if err := s.transition(ctx, payment, status); err != nil {
+		return nil, err
+	}
+
+	refund = &models.Refund{
+		PaymentID:      payment.ID,
+		BookingID:      bookingID,
+		IdempotencyKey: key,
+		Status:         models.RefundPending,
+		Amount:         models.Money{Amount: amount, Currency: payment.Amount.Currency},
+	}
+	if err := s.paymentRepo.CreateRefund(ctx, refund); err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+func (s *paymentService) SubmitRefunds(ctx context.Context, bookingID int) ([]*models.Refund, error) {
+	refunds, err := s.paymentRepo.GetRefundsByBooking(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.paymentRepo.GetPaymentsByBooking(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	references := make(map[int]string, len(payments))
+	for _, p := range payments {
+		references[p.ID] = p.GatewayReference
+	}
+
+	var submitted []*models.Refund
+	for _, refund := range refunds {
+		if refund.Status != models.RefundPending {
+			continue
+		}
+		if err := s.gateway.Refund(ctx, refund.IdempotencyKey, references[refund.PaymentID], refund.Amount); err != nil {
+			return submitted, err
+		}
+		refund.Status = models.RefundCompleted
+		if err := s.paymentRepo.UpdateRefundStatus(ctx, refund); err != nil {
+			return submitted, err
+		}
+		submitted = append(submitted, refund)
+	}
+	return submitted, nil
+}
+
+func (s *paymentService) ListPayments(ctx context.Context, bookingID int) ([]*models.Payment, error) {
+	return s.paymentRepo.GetPaymentsByBooking(ctx, bookingID)
+}
+
+// transition 把付款轉換到 status 並保存，狀態機不允許時返回 ErrInvalidPaymentTransition
+func (s *paymentService) transition(ctx context.Context, payment *models.Payment, status string) error {
+	if !payment.CanTransition(status) {
+		return invalidTransition(payment, status)
+	}
+	payment.Status = status
+	return s.paymentRepo.UpdatePayment(ctx, payment)
+}
+
+func invalidTransition(payment *models.Payment, status string) error {
+	return fmt.Errorf("%w: payment %d is %s, cannot become %s", ErrInvalidPaymentTransition, payment.ID, payment.Status, status)
+}
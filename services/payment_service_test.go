@@ -0,0 +1,224 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"airline-booking/mocks"
+	"airline-booking/models"
+	"airline-booking/repositories"
+	"airline-booking/services"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var testPaidBooking = &models.Booking{ID: 11, Status: "held", Price: models.Money{Amount: 300, Currency: "USD"}}
+
+// newPaymentService 使用假網關與記錄在內存中的付款儲存庫，退款以冪等鍵記錄在 refunds 中
+func newPaymentService(t *testing.T) (services.PaymentService, map[string]*models.Payment, map[string]*models.Refund) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockPaymentRepository(ctrl)
+	stored := make(map[string]*models.Payment)
+	refunds := make(map[string]*models.Refund)
+
+	repo.EXPECT().GetPaymentByIdempotencyKey(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, key string) (*models.Payment, error) {
+			if p, ok := stored[key]; ok {
+				copied := *p
+				return &copied, nil
+			}
+			return nil, fmt.Errorf("payment with idempotency key %q %w", key, repositories.ErrNotFound)
+		}).AnyTimes()
+	repo.EXPECT().CreatePayment(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, p *models.Payment) error {
+			p.ID = len(stored) + 1
+			copied := *p
+			stored[p.IdempotencyKey] = &copied
+			return nil
+		}).AnyTimes()
+	repo.EXPECT().UpdatePayment(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, p *models.Payment) error {
+			copied := *p
+			stored[p.IdempotencyKey] = &copied
+			return nil
+		}).AnyTimes()
+	repo.EXPECT().GetPaymentsByBooking(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, bookingID int) ([]*models.Payment, error) {
+			var payments []*models.Payment
+			for _, p := range stored {
+				if p.BookingID == bookingID {
+					copied := *p
+					payments = append(payments, &copied)
+				}
+			}
+			return payments, nil
+		}).AnyTimes()
+
+	repo.EXPECT().GetRefundByIdempotencyKey(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, key string) (*models.Refund, error) {
+			if r, ok := refunds[key]; ok {
+				copied := *r
+				return &copied, nil
+			}
+			return nil, fmt.Errorf("refund with idempotency key %q %w", key, repositories.ErrNotFound)
+		}).AnyTimes()
+	repo.EXPECT().CreateRefund(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, r *models.Refund) error {
+			r.ID = len(refunds) + 1
+			copied := *r
+			refunds[r.IdempotencyKey] = &copied
+			return nil
+		}).AnyTimes()
+	repo.EXPECT().UpdateRefundStatus(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, r *models.Refund) error {
+			copied := *r
+			refunds[r.IdempotencyKey] = &copied
+			return nil
+		}).AnyTimes()
+	repo.EXPECT().GetRefundsByBooking(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, bookingID int) ([]*models.Refund, error) {
+			var list []*models.Refund
+			for _, r := range refunds {
+				if r.BookingID == bookingID {
+					copied := *r
+					list = append(list, &copied)
+				}
+			}
+			return list, nil
+		}).AnyTimes()
+
+	return services.NewPaymentService(repo, services.NewFakePaymentGateway()), stored, refunds
+}
+
+func TestPaymentService_Authorize_Declined(t *testing.T) {
+	service, stored, _ := newPaymentService(t)
+	ctx := context.Background()
+
+	payment, err := service.Authorize(ctx, testPaidBooking, models.PaymentRequest{IdempotencyKey: "k1", PaymentMethod: services.FakeCardInsufficientFunds})
+
+	assert.ErrorIs(t, err, services.ErrPaymentDeclined)
+	assert.Equal(t, models.PaymentDeclined, payment.Status)
+	assert.Equal(t, "insufficient_funds", stored["k1"].FailureReason)
+
+	// 重試同一個鍵返回同樣的結果，換一個鍵和另一張卡可以重新付款
+	_, err = service.Authorize(ctx, testPaidBooking, models.PaymentRequest{IdempotencyKey: "k1", PaymentMethod: "tok_visa"})
+	assert.ErrorIs(t, err, services.ErrPaymentDeclined)
+
+	payment, err = service.Authorize(ctx, testPaidBooking, models.PaymentRequest{IdempotencyKey: "k2", PaymentMethod: "tok_visa"})
+	assert.NoError(t, err)
+	assert.Equal(t, models.PaymentAuthorized, payment.Status)
+	assert.Equal(t, models.Money{Amount: 300, Currency: "USD"}, payment.Amount)
+}
+
+func TestPaymentService_Authorize_TimeoutRetry(t *testing.T) {
+	service, stored, _ := newPaymentService(t)
+	ctx := context.Background()
+	req := models.PaymentRequest{IdempotencyKey: "k1", PaymentMethod: services.FakeCardTimeoutOnce}
+
+	// 網關已經授權但回應丟失，付款保持 pending
+	_, err := service.Authorize(ctx, testPaidBooking, req)
+	assert.ErrorIs(t, err, services.ErrPaymentGatewayTimeout)
+	assert.Equal(t, models.PaymentPending, stored["k1"].Status)
+
+	// 以同一個鍵重試取回同一個授權，不會重複預授權
+	payment, err := service.Authorize(ctx, testPaidBooking, req)
+	assert.NoError(t, err)
+	assert.Equal(t, models.PaymentAuthorized, payment.Status)
+	assert.Equal(t, "fake_auth_1", payment.GatewayReference)
+	assert.Len(t, stored, 1)
+}
+
+func TestPaymentService_Authorize_KeyReusedForAnotherBooking(t *testing.T) {
+	service, _, _ := newPaymentService(t)
+	ctx := context.Background()
+	req := models.PaymentRequest{IdempotencyKey: "k1", PaymentMethod: "tok_visa"}
+
+	_, err := service.Authorize(ctx, testPaidBooking, req)
+	assert.NoError(t, err)
+
+	_, err = service.Authorize(ctx, &models.Booking{ID: 12, Price: testPaidBooking.Price}, req)
+	var validationErr *services.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func TestPaymentService_Refund_Partial(t *testing.T) {
+	service, stored, refunds := newPaymentService(t)
+	ctx := context.Background()
+
+	// 扣款前沒有可退的付款
+	_, err := service.RequestRefund(ctx, 11, 100, "goodwill")
+	assert.ErrorIs(t, err, services.ErrPaymentNotCollected)
+
+	payment, err := service.Authorize(ctx, testPaidBooking, models.PaymentRequest{IdempotencyKey: "k1", PaymentMethod: "tok_visa"})
+	assert.NoError(t, err)
+	assert.NoError(t, service.Capture(ctx, payment))
+	// 已扣款的付款不能撤銷
+	assert.ErrorIs(t, service.Void(ctx, payment), services.ErrInvalidPaymentTransition)
+
+	refund, err := service.RequestRefund(ctx, 11, 120.5, "goodwill")
+	assert.NoError(t, err)
+	assert.Equal(t, models.RefundPending, refund.Status)
+	assert.Equal(t, models.PaymentPartiallyRefunded, stored["k1"].Status)
+	assert.Equal(t, 179.5, stored["k1"].Refundable())
+
+	// 同一用途的重複請求返回同一筆退款，不會再次扣減
+	again, err := service.RequestRefund(ctx, 11, 120.5, "goodwill")
+	assert.NoError(t, err)
+	assert.Equal(t, refund.IdempotencyKey, again.IdempotencyKey)
+	assert.Equal(t, 179.5, stored["k1"].Refundable())
+
+	_, err = service.RequestRefund(ctx, 11, 200, "cancellation")
+	var validationErr *services.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+
+	_, err = service.RequestRefund(ctx, 11, 179.5, "cancellation")
+	assert.NoError(t, err)
+	assert.Equal(t, models.PaymentRefunded, stored["k1"].Status)
+	assert.Equal(t, models.Money{Amount: 300, Currency: "USD"}, stored["k1"].Refunded)
+
+	submitted, err := service.SubmitRefunds(ctx, 11)
+	assert.NoError(t, err)
+	assert.Len(t, submitted, 2)
+	for _, r := range refunds {
+		assert.Equal(t, models.RefundCompleted, r.Status)
+	}
+}
+
+func TestPaymentService_SubmitRefunds_Retry(t *testing.T) {
+	service, _, refunds := newPaymentService(t)
+	ctx := context.Background()
+
+	payment, err := service.Authorize(ctx, testPaidBooking, models.PaymentRequest{IdempotencyKey: "k1", PaymentMethod: "tok_visa"})
+	assert.NoError(t, err)
+	assert.NoError(t, service.Capture(ctx, payment))
+	refund, err := service.RequestRefund(ctx, 11, 300, "cancellation")
+	assert.NoError(t, err)
+
+	_, err = service.SubmitRefunds(ctx, 11)
+	assert.NoError(t, err)
+
+	// 網關已經退款但標記完成前中斷，重試以同一個冪等鍵提交，網關不會再退一次
+	refunds[refund.IdempotencyKey].Status = models.RefundPending
+	submitted, err := service.SubmitRefunds(ctx, 11)
+	assert.NoError(t, err)
+	assert.Len(t, submitted, 1)
+
+	// 沒有待提交的退款時不請求網關
+	submitted, err = service.SubmitRefunds(ctx, 11)
+	assert.NoError(t, err)
+	assert.Empty(t, submitted)
+}
+
+func TestPaymentService_Void(t *testing.T) {
+	service, stored, _ := newPaymentService(t)
+	ctx := context.Background()
+
+	payment, err := service.Authorize(ctx, testPaidBooking, models.PaymentRequest{IdempotencyKey: "k1", PaymentMethod: "tok_visa"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, service.Void(ctx, payment))
+	assert.Equal(t, models.PaymentVoided, stored["k1"].Status)
+	assert.ErrorIs(t, service.Capture(ctx, payment), services.ErrInvalidPaymentTransition)
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"airline-booking/logger"
+	"airline-booking/models"
+	"airline-booking/repositories"
+
+	"go.uber.org/zap"
+)
+
+// cabinMultipliers 是沒有票價艙等時各艙位相對航班基礎票價的倍數
+var cabinMultipliers = map[string]float64{
+	"economy":  1,
+	"business": 3,
+	"first":    5,
+}
+
+// advancePurchaseFactors 按距離起飛的天數調整票價，越接近起飛越貴；按 minDays 由大到小匹配
+var advancePurchaseFactors = []struct {
+	minDays int
+	factor  float64
+}{
+	{21, 0.9},
+	{7, 1},
+	{3, 1.15},
+	{0, 1.3},
+}
+
+const (
+	// defaultCurrency 是航班票價的幣別，flights 表目前只存金額
+	defaultCurrency = "USD"
+	// baselineLoadFactor 是歷史平均載客率的基準，高於它的星期幾視為需求旺盛
+	baselineLoadFactor = 0.75
+	// demandSensitivity 是歷史載客率偏離基準時票價的變化幅度
+	demandSensitivity = 0.4
+	// maxDemandAdjustment 限制按星期幾調整的幅度，避免歷史數據異常時票價失控
+	maxDemandAdjustment = 0.1
+)
+
+// PricingService 按艙位計算航班的當前票價。
+//
+// 航班定義了票價艙等時，售出該艙位最便宜且配額未售完的艙等，票價以艙等的票價為基礎；
+// 沒有定義時按艙位載客率選出默認票價等級，票價為航班基礎票價 × 艙位倍數 × 等級倍數。
+// 兩種情況都再乘以提前購票係數與星期需求係數，後者來自該航線同一星期幾的歷史平均載客率。
+type PricingService interface {
+	// Quote 計算航班某艙位當前的票價。fareClass 為空時選擇最便宜的可售艙等；
+	// 指定的艙等不屬於該艙位時返回 ValidationError，配額已售完時返回 repositories.ErrNoSeatsAvailable
+	Quote(ctx context.Context, flight *models.Flight, cabin, fareClass string) (models.Fare, error)
+	// PriceFlights 為每個航班填入各艙位的當前票價，配額全部售完的艙位不列出
+	PriceFlights(ctx context.Context, flights []models.Flight) error
+}
+
+type pricingService struct {
+	flightRepo repositories.FlightRepository
+	buckets    []models.FareBucket
+}
+
+// NewPricingService 創建定價服務，buckets 按 MaxLoadFactor 由低到高排列且至少有一個
+func NewPricingService(flightRepo repositories.FlightRepository, buckets []models.FareBucket) PricingService {
+	return &pricingService{
+		flightRepo: flightRepo,
+		buckets:    buckets,
+	}
+}
+
+func (s *pricingService) Quote(ctx context.Context, flight *models.Flight, cabin, fareClass string) (models.Fare, error) {
+	if _, ok := flight.Seats(cabin); !ok {
+		return models.Fare{}, fmt.Errorf("unknown cabin %q", cabin)
+	}
+	classes, err := s.flightRepo.GetFareClasses(ctx, []int{flight.ID})
+	if err != nil {
+		return models.Fare{}, err
+	}
+	return s.fare(flight, cabin, fareClass, classes[flight.ID], s.demandFactor(ctx, flight, nil), time.Now())
+}
+
+func (s *pricingService) PriceFlights(ctx context.Context, flights []models.Flight) error {
+	if len(flights) == 0 {
+		return nil
+	}
+	ids := make([]int, len(flights))
+	for i, flight := range flights {
+		ids[i] = flight.ID
+	}
+	classes, err := s.flightRepo.GetFareClasses(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	// 同一次搜索的航班大多是同一航線同一天，歷史數據只查一次
+	demand := make(map[string]float64)
+	now := time.Now()
+
+	for i := range flights {
+		flight := &flights[i]
+		factor := s.demandFactor(ctx, flight, demand)
+
+		flight.Fares = make(map[string]models.Fare, len(cabinMultipliers))
+		for cabin := range cabinMultipliers {
+			if seats, _ := flight.Seats(cabin); seats.Total == 0 {
+				continue
+			}
+			fare, err := s.fare(flight, cabin, "", classes[flight.ID], factor, now)
+			if errors.Is(err, repositories.ErrNoSeatsAvailable) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			flight.Fares[cabin] = fare
+		}
+	}
+	return nil
+}
+
+// fare 計算艙位的票價，classes 是航班的全部票價艙等
+func (s *pricingService) fare(flight *models.Flight, cabin, code string, classes []models.FareClass, demand float64, now time.Time) (models.Fare, error) {
+	adjustment := advancePurchaseFactor(flight.DepartureTime.Sub(now)) * demand
+
+	var offered []models.FareClass
+	for _, class := range classes {
+		if class.Cabin == cabin {
+			offered = append(offered, class)
+		}
+	}
+	if len(offered) == 0 {
+		if code != "" {
+			return models.Fare{}, &ValidationError{Field: "fare_class", Message: "flight has no fare classes in this cabin"}
+		}
+		seats, _ := flight.Seats(cabin)
+		bucket := s.bucket(seats)
+		return models.Fare{
+			FareClass: bucket.Name,
+			Price:     money(flight.Price * cabinMultipliers[cabin] * bucket.Multiplier * adjustment),
+			Lowest:    bucket.Name == s.buckets[0].Name,
+		}, nil
+	}
+
+	// offered 已按票價由低到高排列
+	i := slices.IndexFunc(offered, func(c models.FareClass) bool {
+		return (code == "" && c.Available() > 0) || c.Code == code
+	})
+	if i < 0 {
+		if code != "" {
+			return models.Fare{}, &ValidationError{Field: "fare_class", Message: fmt.Sprintf("%q is not offered in %s", code, cabin)}
+		}
+		return models.Fare{}, repositories.ErrNoSeatsAvailable
+	}
+	class := offered[i]
+	if class.Available() == 0 {
+		return models.Fare{}, repositories.ErrNoSeatsAvailable
+	}
+
+	rules := class.Rules
+	return models.Fare{
+		FareClass: class.Code,
+		Price:     money(class.Price * adjustment),
+		Rules:     &rules,
+		Lowest:    class.Price == offered[0].Price,
+	}, nil
+}
+
+// money 將金額四捨五入到分
+func money(amount float64) models.Money {
+	return models.Money{Amount: math.Round(amount*100) / 100, Currency: defaultCurrency}
+}
+
+// bucket 返回艙位載客率所屬的票價等級；超過所有等級時（例如超賣）使用最高的等級
+func (s *pricingService) bucket(seats models.SeatInventory) models.FareBucket {
+	loadFactor := 1.0
+	if seats.Total > 0 {
+		loadFactor = float64(seats.Booked) / float64(seats.Total)
+	}
+	for _, bucket := range s.buckets {
+		if loadFactor <= bucket.MaxLoadFactor {
+			return bucket
+		}
+	}
+	return s.buckets[len(s.buckets)-1]
+}
+
+// demandFactor 根據航線在出發當地星期幾的歷史平均載客率返回需求係數。
+// 沒有歷史數據或查詢失敗時返回 1，定價不因此失敗；cache 不為 nil 時按航線與星期幾記住結果
+func (s *pricingService) demandFactor(ctx context.Context, flight *models.Flight, cache map[string]float64) float64 {
+	departure := flight.DepartureTime
+	if loc, err := time.LoadLocation(flight.OriginTimezone); err == nil {
+		departure = departure.In(loc)
+	}
+
+	key := fmt.Sprintf("%s:%d", flight.Route(), departure.Weekday())
+	if factor, ok := cache[key]; ok {
+		return factor
+	}
+
+	factor := 1.0
+	history, err := s.flightRepo.GetHistoricalNoShowRate(ctx, flight.Route(), departure.Weekday())
+	if err != nil {
+		logger.Error("Failed to load historical demand", zap.Error(err), zap.String("route", flight.Route()))
+	} else if history.AverageLoadFactor > 0 {
+		adjustment := (history.AverageLoadFactor - baselineLoadFactor) * demandSensitivity
+		factor = 1 + max(-maxDemandAdjustment, min(adjustment, maxDemandAdjustment))
+	}
+
+	if cache != nil {
+		cache[key] = factor
+	}
+	return factor
+}
+
+// advancePurchaseFactor 返回距離起飛 untilDeparture 時的提前購票係數
+func advancePurchaseFactor(untilDeparture time.Duration) float64 {
+	days := int(untilDeparture / (24 * time.Hour))
+	for _, tier := range advancePurchaseFactors {
+		if days >= tier.minDays {
+			return tier.factor
+		}
+	}
+	// 已經起飛的航班不會被搜索或預訂，按最晚的檔位計算
+	return advancePurchaseFactors[len(advancePurchaseFactors)-1].factor
+}
@@ -0,0 +1,141 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"airline-booking/mocks"
+	"airline-booking/models"
+	"airline-booking/repositories"
+	"airline-booking/services"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var testFareBuckets = []models.FareBucket{
+	{Name: "Q", MaxLoadFactor: 0.5, Multiplier: 0.8},
+	{Name: "M", MaxLoadFactor: 0.7, Multiplier: 1},
+	{Name: "Y", MaxLoadFactor: 1, Multiplier: 1.7},
+}
+
+func TestPricingService_Quote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockFlightRepository(ctrl)
+	// 歷史載客率 0.9 高於基準 0.75，需求係數為 1 + 0.15*0.4
+	repo.EXPECT().GetHistoricalNoShowRate(gomock.Any(), "TPE-NRT", gomock.Any()).
+		Return(models.HistoricalData{AverageLoadFactor: 0.9}, nil).AnyTimes()
+	// 航班沒有定義票價艙等，按載客率選擇默認等級
+	repo.EXPECT().GetFareClasses(gomock.Any(), []int{7}).Return(map[int][]models.FareClass{}, nil).Times(3)
+	service := services.NewPricingService(repo, testFareBuckets)
+
+	flight := &models.Flight{
+		ID: 7, Origin: "TPE", Destination: "NRT", Price: 100,
+		DepartureTime: time.Now().Add(30 * 24 * time.Hour),
+		EconomySeats:  models.SeatInventory{Total: 100, Booked: 60},
+		// 滿座時使用最高的票價等級
+		BusinessSeats: models.SeatInventory{Total: 10, Booked: 10},
+	}
+
+	// 100 × 經濟艙 1 × M 1 × 提前 30 天 0.9 × 需求 1.06
+	fare, err := service.Quote(context.Background(), flight, "economy", "")
+	assert.NoError(t, err)
+	assert.Equal(t, models.Fare{FareClass: "M", Price: models.Money{Amount: 95.4, Currency: "USD"}}, fare)
+
+	// 100 × 商務艙 3 × Y 1.7 × 0.9 × 1.06
+	fare, err = service.Quote(context.Background(), flight, "business", "")
+	assert.NoError(t, err)
+	assert.Equal(t, models.Fare{FareClass: "Y", Price: models.Money{Amount: 486.54, Currency: "USD"}}, fare)
+
+	// 沒有票價艙等時不能指定艙等
+	_, err = service.Quote(context.Background(), flight, "economy", "Q")
+	var validationErr *services.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+
+	_, err = service.Quote(context.Background(), flight, "premium", "")
+	assert.Error(t, err)
+}
+
+func TestPricingService_PriceFlights(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockFlightRepository(ctrl)
+	// 同航線同一天的航班只查一次歷史數據；查詢失敗時不調整票價
+	repo.EXPECT().GetHistoricalNoShowRate(gomock.Any(), "TPE-NRT", gomock.Any()).
+		Return(models.HistoricalData{}, errors.New("db down")).Times(1)
+	repo.EXPECT().GetFareClasses(gomock.Any(), []int{1, 2}).Return(map[int][]models.FareClass{}, nil)
+	service := services.NewPricingService(repo, testFareBuckets)
+
+	departure := time.Now().Add(2*24*time.Hour + time.Hour)
+	flights := []models.Flight{
+		{ID: 1, Origin: "TPE", Destination: "NRT", Price: 200, DepartureTime: departure,
+			EconomySeats: models.SeatInventory{Total: 100, Booked: 20}},
+		{ID: 2, Origin: "TPE", Destination: "NRT", Price: 100, DepartureTime: departure,
+			EconomySeats: models.SeatInventory{Total: 100, Booked: 90}},
+	}
+
+	assert.NoError(t, service.PriceFlights(context.Background(), flights))
+
+	// 200 × Q 0.8 × 起飛前 2 天 1.3；沒有座位的艙位不列出
+	assert.Equal(t, map[string]models.Fare{
+		"economy": {FareClass: "Q", Price: models.Money{Amount: 208, Currency: "USD"}, Lowest: true},
+	}, flights[0].Fares)
+	assert.Equal(t, "Y", flights[1].Fares["economy"].FareClass)
+	assert.Equal(t, 221.0, flights[1].Fares["economy"].Price.Amount)
+}
+
+func TestPricingService_FareClasses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockFlightRepository(ctrl)
+	repo.EXPECT().GetHistoricalNoShowRate(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(models.HistoricalData{}, nil).AnyTimes()
+	refundable := models.FareRules{Refundable: true, RefundFee: 30, Changeable: true}
+	repo.EXPECT().GetFareClasses(gomock.Any(), []int{7}).Return(map[int][]models.FareClass{
+		7: {
+			{FlightID: 7, Cabin: "economy", Code: "Q", Price: 80, SeatsTotal: 10, SeatsBooked: 10},
+			{FlightID: 7, Cabin: "economy", Code: "M", Price: 120, SeatsTotal: 20, SeatsBooked: 5},
+			{FlightID: 7, Cabin: "economy", Code: "Y", Price: 300, SeatsTotal: 100, Rules: refundable},
+			{FlightID: 7, Cabin: "business", Code: "C", Price: 900, SeatsTotal: 5, SeatsBooked: 5},
+		},
+	}, nil).AnyTimes()
+	service := services.NewPricingService(repo, testFareBuckets)
+
+	flight := &models.Flight{
+		ID: 7, Origin: "TPE", Destination: "NRT", Price: 100,
+		DepartureTime: time.Now().Add(10 * 24 * time.Hour),
+		EconomySeats:  models.SeatInventory{Total: 100, Booked: 15},
+		BusinessSeats: models.SeatInventory{Total: 5, Booked: 5},
+	}
+
+	// Q 已售完，售出次便宜的 M；票價以艙等票價為準，不再套用艙位倍數與載客率等級
+	fare, err := service.Quote(context.Background(), flight, "economy", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "M", fare.FareClass)
+	assert.Equal(t, 120.0, fare.Price.Amount)
+	assert.False(t, fare.Lowest)
+
+	fare, err = service.Quote(context.Background(), flight, "economy", "Y")
+	assert.NoError(t, err)
+	assert.Equal(t, &refundable, fare.Rules)
+
+	_, err = service.Quote(context.Background(), flight, "economy", "Q")
+	assert.ErrorIs(t, err, repositories.ErrNoSeatsAvailable)
+
+	// 其他艙位的艙等不能指定
+	_, err = service.Quote(context.Background(), flight, "economy", "C")
+	var validationErr *services.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+
+	// 搜索結果不列出配額全部售完的艙位
+	flights := []models.Flight{*flight}
+	assert.NoError(t, service.PriceFlights(context.Background(), flights))
+	assert.Len(t, flights[0].Fares, 1)
+	assert.Contains(t, flights[0].Fares, "economy")
+}
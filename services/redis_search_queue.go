@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"airline-booking/models"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	searchQueueKey = "search:queue"
+	// 取出的任務 ID 移到處理中列表，結束後才移除；searchClaimsKey 記錄每個任務的認領期限
+	searchProcessingKey = "search:processing"
+	searchClaimsKey     = "search:claims"
+	searchJobKeyPrefix  = "search:job:"
+	// 任務結束時在此頻道發布通知，等待結果的實例訂閱它而不是輪詢
+	searchDoneChannelPrefix = "search:done:"
+	// dequeuePollTimeout 是單次 BRPOPLPUSH 的阻塞時間，期間結束後重新檢查 ctx 是否已取消
+	dequeuePollTimeout = time.Second
+)
+
+// enqueueScript 在一次原子操作中檢查隊列長度、保存任務並入隊，避免多個實例同時入隊時超出上限
+var enqueueScript = redis.NewScript(`
+if redis.call('LLEN', KEYS[1]) >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('SET', KEYS[2], ARGV[2], 'EX', ARGV[3])
+redis.call('LPUSH', KEYS[1], ARGV[4])
+return 1
+`)
+
+// requeueScript 把認領期限已過的處理中任務放回隊列的出隊端，優先重新執行。
+// 沒有認領期限的任務可能剛被取出還沒來得及記錄，也可能是記錄前實例就崩潰了，從現在開始計算期限
+var requeueScript = redis.NewScript(`
+local requeued = 0
+for _, id in ipairs(redis.call('LRANGE', KEYS[1], 0, -1)) do
+	local deadline = redis.call('HGET', KEYS[2], id)
+	if not deadline then
+		redis.call('HSET', KEYS[2], id, tonumber(ARGV[1]) + tonumber(ARGV[2]))
+	elseif tonumber(deadline) < tonumber(ARGV[1]) then
+		redis.call('LREM', KEYS[1], 1, id)
+		redis.call('HDEL', KEYS[2], id)
+		redis.call('RPUSH', KEYS[3], id)
+		requeued = requeued + 1
+	end
+end
+return requeued
+`)
+
+type redisSearchQueue struct {
+	client    *redis.Client
+	maxLength int
+	jobTTL    time.Duration
+	claimTTL  time.Duration
+}
+
+// NewRedisSearchQueue 創建以 Redis 列表為隊列、字符串鍵保存任務的搜索隊列。
+// 任務 ID 存放在列表中，任務本身以 JSON 存放並在 jobTTL 後過期，所有實例共享。
+// 取出的任務在結束前保留在處理中列表，超過 jobTimeout 加寫回時間仍未結束的任務視為執行中斷，
+// 由 RequeueStale 放回隊列，實例崩潰或重啟不會讓任務永遠停在 queued 或 running
+func NewRedisSearchQueue(client *redis.Client, maxLength int, jobTTL, jobTimeout time.Duration) SearchQueue {
+	return &redisSearchQueue{
+		client:    client,
+		maxLength: maxLength,
+		jobTTL:    jobTTL,
+		claimTTL:  jobTimeout + searchJobSaveTimeout,
+	}
+}
+
+func (q *redisSearchQueue) Enqueue(ctx context.Context, job *models.SearchJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	queued, err := enqueueScript.Run(ctx, q.client,
+		[]string{searchQueueKey, searchJobKeyPrefix + job.ID},
+		q.maxLength, data, int(q.jobTTL.Seconds()), job.ID,
+	).Int()
+	if err != nil {
+		return err
+	}
+	if queued == 0 {
+		return ErrSearchQueueFull
+	}
+	return nil
+}
+
+func (q *redisSearchQueue) Dequeue(ctx context.Context) (*models.SearchJob, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// 任務 ID 原子地移到處理中列表，取出後實例崩潰也不會丟失；超時返回 redis.Nil
+		requestID, err := q.client.BRPopLPush(ctx, searchQueueKey, searchProcessingKey, dequeuePollTimeout).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		deadline := time.Now().Add(q.claimTTL).UnixMilli()
+		if err := q.client.HSet(ctx, searchClaimsKey, requestID, deadline).Err(); err != nil {
+			return nil, err
+		}
+
+		job, err := q.GetJob(ctx, requestID)
+		if errors.Is(err, ErrSearchJobNotFound) {
+			// 任務在排隊期間已經過期，跳過
+			if err := q.release(ctx, requestID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		return job, err
+	}
+}
+
+func (q *redisSearchQueue) SaveJob(ctx context.Context, job *models.SearchJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	if err := q.client.Set(ctx, searchJobKeyPrefix+job.ID, data, q.jobTTL).Err(); err != nil {
+		return err
+	}
+
+	if job.IsFinished() {
+		if err := q.release(ctx, job.ID); err != nil {
+			return err
+		}
+		return q.client.Publish(ctx, searchDoneChannelPrefix+job.ID, job.Status).Err()
+	}
+	return nil
+}
+
+// release 把任務移出處理中列表，之後不會再被放回隊列
+func (q *redisSearchQueue) release(ctx context.Context, requestID string) error {
+	_, err := q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LRem(ctx, searchProcessingKey, 1, requestID)
+		pipe.HDel(ctx, searchClaimsKey, requestID)
+		return nil
+	})
+	return err
+}
+
+func (q *redisSearchQueue) RequeueStale(ctx context.Context) (int, error) {
+	return requeueScript.Run(ctx, q.client,
+		[]string{searchProcessingKey, searchClaimsKey, searchQueueKey},
+		time.Now().UnixMilli(), q.claimTTL.Milliseconds(),
+	).Int()
+}
+
+func (q *redisSearchQueue) GetJob(ctx context.Context, requestID string) (*models.SearchJob, error) {
+	data, err := q.client.Get(ctx, searchJobKeyPrefix+requestID).Bytes()
+	if err == redis.Nil {
+		return nil, ErrSearchJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var job models.SearchJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (q *redisSearchQueue) WaitForJob(ctx context.Context, requestID string) (*models.SearchJob, error) {
+	// 先訂閱再讀取任務，避免任務在兩者之間結束而錯過通知
+	sub := q.client.Subscribe(ctx, searchDoneChannelPrefix+requestID)
+	defer sub.Close()
+	// 等待訂閱確認，確保之後發布的通知一定能收到。ctx 到期時 Redis 命令返回的是連接超時而不是 ctx 的錯誤，
+	// 因此每一步失敗後都先檢查 ctx，到期時統一返回任務快照
+	if _, err := sub.Receive(ctx); err != nil {
+		if ctx.Err() != nil {
+			return q.jobSnapshot(ctx, requestID)
+		}
+		return nil, err
+	}
+
+	job, err := q.GetJob(ctx, requestID)
+	if err != nil && ctx.Err() != nil {
+		return q.jobSnapshot(ctx, requestID)
+	}
+	if err != nil || job.IsFinished() {
+		return job, err
+	}
+
+	select {
+	case <-sub.Channel():
+		job, err := q.GetJob(ctx, requestID)
+		if err != nil && ctx.Err() != nil {
+			return q.jobSnapshot(ctx, requestID)
+		}
+		return job, err
+	case <-ctx.Done():
+		return q.jobSnapshot(ctx, requestID)
+	}
+}
+
+// jobSnapshot 在等待的 ctx 結束後以獨立的 ctx 讀取任務的當前狀態，與 ctx 的錯誤一起返回
+func (q *redisSearchQueue) jobSnapshot(ctx context.Context, requestID string) (*models.SearchJob, error) {
+	job, err := q.GetJob(context.Background(), requestID)
+	if err != nil {
+		return nil, err
+	}
+	return job, ctx.Err()
+}
+
+func (q *redisSearchQueue) Len(ctx context.Context) (int, error) {
+	n, err := q.client.LLen(ctx, searchQueueKey).Result()
+	return int(n), err
+}
@@ -0,0 +1,149 @@
+package services_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"airline-booking/models"
+	"airline-booking/services"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-redis/redismock/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRedis 是只支持 GET 的最小 RESP 服務端，SUBSCRIBE 永遠不確認，
+// 用來模擬等待時間短於訂閱往返的情況；redismock 不支持 pub/sub
+type fakeRedis struct {
+	listener net.Listener
+	values   map[string]string
+}
+
+func newFakeRedis(t *testing.T, values map[string]string) *redis.Client {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeRedis{listener: listener, values: values}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+
+	client := redis.NewClient(&redis.Options{Addr: listener.Addr().String()})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func (s *fakeRedis) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		switch strings.ToUpper(args[0]) {
+		case "GET":
+			value, ok := s.values[args[1]]
+			if !ok {
+				fmt.Fprint(conn, "$-1\r\n")
+				continue
+			}
+			fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(value), value)
+		case "SUBSCRIBE":
+		default:
+			fmt.Fprint(conn, "+OK\r\n")
+		}
+	}
+}
+
+// readCommand 讀取一個以 RESP 數組編碼的命令
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		if _, err := reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args[i] = strings.TrimSuffix(arg, "\r\n")
+	}
+	return args, nil
+}
+
+func TestRedisSearchQueue_WaitForJob_ShortWaitReturnsSnapshot(t *testing.T) {
+	data, _ := json.Marshal(models.SearchJob{ID: "r1", Status: models.SearchJobRunning})
+	client := newFakeRedis(t, map[string]string{"search:job:r1": string(data)})
+	queue := services.NewRedisSearchQueue(client, 10, time.Minute, time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	job, err := queue.WaitForJob(ctx, "r1")
+
+	// 訂閱在等待到期前沒有確認，仍然返回任務的當前狀態而不是 nil
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	if assert.NotNil(t, job) {
+		assert.Equal(t, models.SearchJobRunning, job.Status)
+	}
+}
+
+func TestRedisSearchQueue_DequeueKeepsJobUntilFinished(t *testing.T) {
+	ctx := context.Background()
+	client, mock := redismock.NewClientMock()
+	queue := services.NewRedisSearchQueue(client, 10, time.Minute, time.Second)
+
+	job := models.SearchJob{ID: "r1", Status: models.SearchJobQueued}
+	data, _ := json.Marshal(job)
+	// 取出的任務留在處理中列表並記錄認領期限，實例崩潰後可以被放回隊列
+	mock.ExpectBRPopLPush("search:queue", "search:processing", time.Second).SetVal("r1")
+	mock.CustomMatch(func(expected, actual []interface{}) error {
+		if len(actual) != 4 || actual[0] != "hset" || actual[1] != "search:claims" || actual[2] != "r1" {
+			return fmt.Errorf("unexpected command %v", actual)
+		}
+		return nil
+	}).ExpectHSet("search:claims", "r1", 0).SetVal(1)
+	mock.ExpectGet("search:job:r1").SetVal(string(data))
+
+	dequeued, err := queue.Dequeue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "r1", dequeued.ID)
+
+	// 任務結束時才移出處理中列表
+	dequeued.Status = models.SearchJobDone
+	finished, _ := json.Marshal(dequeued)
+	mock.ExpectSet("search:job:r1", finished, time.Minute).SetVal("OK")
+	mock.ExpectTxPipeline()
+	mock.ExpectLRem("search:processing", 1, "r1").SetVal(1)
+	mock.ExpectHDel("search:claims", "r1").SetVal(1)
+	mock.ExpectTxPipelineExec()
+	mock.ExpectPublish("search:done:r1", models.SearchJobDone).SetVal(0)
+
+	assert.NoError(t, queue.SaveJob(ctx, dequeued))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"airline-booking/models"
+	"airline-booking/repositories"
+)
+
+// refundWindows 按取消時距離起飛的時間決定可退比例；按 minBefore 由大到小匹配
+var refundWindows = []struct {
+	minBefore time.Duration
+	rate      float64
+}{
+	{7 * 24 * time.Hour, 1},
+	{72 * time.Hour, 0.75},
+	{24 * time.Hour, 0.5},
+	{0, 0},
+}
+
+// loyaltyRefundBenefits 是常旅客等級的退票優惠：feeDiscount 是取消費的減免比例，
+// fullRefund 表示起飛前任何時候取消都不按時間扣減
+var loyaltyRefundBenefits = map[string]struct {
+	feeDiscount float64
+	fullRefund  bool
+}{
+	"silver":   {feeDiscount: 0.5},
+	"gold":     {feeDiscount: 1},
+	"platinum": {feeDiscount: 1, fullRefund: true},
+}
+
+// RefundPolicy 按退票規則計算取消預訂時的退款。
+//
+// 可退金額是已扣款且尚未退回的金額，乘以按距離起飛時間得出的比例後減去票價艙等的取消費；
+// 不可退的艙等與已起飛的航班不退款。常旅客等級可以減免取消費，白金卡在起飛前取消不按時間扣減。
+// 按默認票價等級售出的預訂沒有退票限制與取消費
+type RefundPolicy interface {
+	// Quote 計算現在取消預訂可以得到的退款，不修改任何數據
+	Quote(ctx context.Context, booking *models.Booking) (*models.CancellationQuote, error)
+}
+
+type refundPolicy struct {
+	flightRepo    repositories.FlightRepository
+	passengerRepo repositories.PassengerRepository
+	payments      PaymentService
+}
+
+func NewRefundPolicy(flightRepo repositories.FlightRepository, passengerRepo repositories.PassengerRepository, payments PaymentService) RefundPolicy {
+	return &refundPolicy{
+		flightRepo:    flightRepo,
+		passengerRepo: passengerRepo,
+		payments:      payments,
+	}
+}
+
+func (p *refundPolicy) Quote(ctx context.Context, booking *models.Booking) (*models.CancellationQuote, error) {
+	quote := &models.CancellationQuote{
+		BookingID: booking.ID,
+		QuotedAt:  time.Now(),
+	}
+
+	payments, err := p.payments.ListPayments(ctx, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	quote.Paid = models.Money{Currency: booking.Price.Currency}
+	for _, payment := range payments {
+		if payment.Collected() {
+			quote.Paid = models.Money{Amount: payment.Refundable(), Currency: payment.Amount.Currency}
+			break
+		}
+	}
+	quote.CancellationFee = models.Money{Currency: quote.Paid.Currency}
+	quote.Refund = models.Money{Currency: quote.Paid.Currency}
+	if quote.Paid.Amount == 0 {
+		quote.Reason = "no payment has been collected"
+		return quote, nil
+	}
+
+	flight, err := p.flightRepo.GetFlightByID(ctx, booking.FlightID)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := fareRules(ctx, p.flightRepo, booking.FlightID, booking.FareClass)
+	if err != nil {
+		return nil, err
+	}
+	passenger, err := p.passengerRepo.GetPassengerByID(ctx, booking.PassengerID)
+	if err != nil {
+		return nil, err
+	}
+	benefits := loyaltyRefundBenefits[strings.ToLower(strings.TrimSpace(passenger.FrequentFlyerTier))]
+
+	before := flight.DepartureTime.Sub(quote.QuotedAt)
+	switch {
+	case before <= 0:
+		quote.Reason = "the flight has departed"
+		return quote, nil
+	case rules != nil && !rules.Refundable:
+		quote.Reason = fmt.Sprintf("fare class %s is non-refundable", booking.FareClass)
+		return quote, nil
+	case benefits.fullRefund:
+		quote.RefundRate = 1
+	default:
+		for _, w := range refundWindows {
+			if before >= w.minBefore {
+				quote.RefundRate = w.rate
+				break
+			}
+		}
+	}
+	if quote.RefundRate == 0 {
+		quote.Reason = "cancelled too close to departure"
+		return quote, nil
+	}
+
+	gross := quote.Paid.Amount * quote.RefundRate
+	var fee float64
+	if rules != nil {
+		fee = rules.RefundFee * (1 - benefits.feeDiscount)
+	}
+	// 取消費最多扣到退款為 0
+	quote.CancellationFee.Amount = roundCents(math.Min(fee, gross))
+	quote.Refund.Amount = roundCents(gross - quote.CancellationFee.Amount)
+	return quote, nil
+}
+
+// roundCents 把金額四捨五入到分
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
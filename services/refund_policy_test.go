@@ -0,0 +1,109 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"airline-booking/mocks"
+	"airline-booking/models"
+	"airline-booking/services"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefundPolicy_Quote(t *testing.T) {
+	fareClasses := map[int][]models.FareClass{
+		7: {
+			{FlightID: 7, Cabin: "economy", Code: "M", Rules: models.FareRules{Refundable: true, RefundFee: 50}},
+			{FlightID: 7, Cabin: "economy", Code: "K", Rules: models.FareRules{Refundable: true, RefundFee: 200}},
+			{FlightID: 7, Cabin: "economy", Code: "Q"},
+		},
+	}
+	captured := &models.Payment{Status: models.PaymentCaptured, Amount: models.Money{Amount: 300, Currency: "USD"}}
+
+	tests := []struct {
+		name       string
+		fareClass  string
+		before     time.Duration
+		tier       string
+		payment    *models.Payment
+		wantRate   float64
+		wantFee    float64
+		wantRefund float64
+	}{
+		// 按默認票價等級售出的預訂沒有取消費
+		{name: "default bucket", fareClass: "B", before: 10 * 24 * time.Hour, wantRate: 1, wantRefund: 300},
+		{name: "more than a week out", fareClass: "M", before: 10 * 24 * time.Hour, wantRate: 1, wantFee: 50, wantRefund: 250},
+		{name: "three to seven days", fareClass: "M", before: 4 * 24 * time.Hour, wantRate: 0.75, wantFee: 50, wantRefund: 175},
+		{name: "one to three days", fareClass: "M", before: 48 * time.Hour, wantRate: 0.5, wantFee: 50, wantRefund: 100},
+		{name: "within a day", fareClass: "M", before: 12 * time.Hour},
+		{name: "non-refundable fare", fareClass: "Q", before: 30 * 24 * time.Hour, tier: "Platinum"},
+		{name: "departed", fareClass: "M", before: -time.Hour, tier: "Platinum"},
+		// 取消費最多扣到 0
+		{name: "fee exceeds refund", fareClass: "K", before: 48 * time.Hour, wantRate: 0.5, wantFee: 150},
+		{name: "silver halves the fee", fareClass: "M", before: 4 * 24 * time.Hour, tier: "Silver", wantRate: 0.75, wantFee: 25, wantRefund: 200},
+		{name: "gold waives the fee", fareClass: "M", before: 4 * 24 * time.Hour, tier: "gold", wantRate: 0.75, wantRefund: 225},
+		{name: "platinum ignores the window", fareClass: "M", before: 12 * time.Hour, tier: "Platinum", wantRate: 1, wantRefund: 300},
+		// 已部分退款的付款只按剩餘金額計算
+		{
+			name: "partially refunded", fareClass: "M", before: 10 * 24 * time.Hour, wantRate: 1, wantFee: 50, wantRefund: 70,
+			payment: &models.Payment{
+				Status:   models.PaymentPartiallyRefunded,
+				Amount:   models.Money{Amount: 300, Currency: "USD"},
+				Refunded: models.Money{Amount: 180, Currency: "USD"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			flights := mocks.NewMockFlightRepository(ctrl)
+			passengers := mocks.NewMockPassengerRepository(ctrl)
+			payments := mocks.NewMockPaymentService(ctrl)
+			policy := services.NewRefundPolicy(flights, passengers, payments)
+
+			payment := tt.payment
+			if payment == nil {
+				payment = captured
+			}
+			payments.EXPECT().ListPayments(gomock.Any(), 11).
+				Return([]*models.Payment{{Status: models.PaymentDeclined}, payment}, nil)
+			flights.EXPECT().GetFlightByID(gomock.Any(), 7).
+				Return(&models.Flight{ID: 7, DepartureTime: time.Now().Add(tt.before)}, nil)
+			flights.EXPECT().GetFareClasses(gomock.Any(), []int{7}).Return(fareClasses, nil)
+			passengers.EXPECT().GetPassengerByID(gomock.Any(), 3).Return(&models.Passenger{ID: 3, FrequentFlyerTier: tt.tier}, nil)
+
+			quote, err := policy.Quote(context.Background(), &models.Booking{ID: 11, PassengerID: 3, FlightID: 7, FareClass: tt.fareClass})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRate, quote.RefundRate)
+			assert.Equal(t, models.Money{Amount: tt.wantFee, Currency: "USD"}, quote.CancellationFee)
+			assert.Equal(t, models.Money{Amount: tt.wantRefund, Currency: "USD"}, quote.Refund)
+			if tt.wantRate == 0 {
+				assert.NotEmpty(t, quote.Reason)
+			}
+		})
+	}
+}
+
+func TestRefundPolicy_Quote_NothingCollected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	flights := mocks.NewMockFlightRepository(ctrl)
+	passengers := mocks.NewMockPassengerRepository(ctrl)
+	payments := mocks.NewMockPaymentService(ctrl)
+	policy := services.NewRefundPolicy(flights, passengers, payments)
+
+	// 保留中的預訂只有被拒的付款
+	payments.EXPECT().ListPayments(gomock.Any(), 11).Return([]*models.Payment{{Status: models.PaymentDeclined}}, nil)
+	flights.EXPECT().GetFlightByID(gomock.Any(), gomock.Any()).Times(0)
+
+	quote, err := policy.Quote(context.Background(), &models.Booking{ID: 11, FlightID: 7, Price: models.Money{Amount: 300, Currency: "USD"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.Money{Currency: "USD"}, quote.Paid)
+	assert.Equal(t, models.Money{Currency: "USD"}, quote.Refund)
+	assert.NotEmpty(t, quote.Reason)
+}
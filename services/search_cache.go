@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"airline-booking/logger"
+	"airline-booking/repositories"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// SearchCache 緩存航班搜索、轉機行程與低價日曆的結果。
+//
+// 緩存按「航線 + 出發日期」分代：每個航線日期在 Redis 中有一個版本號，緩存鍵帶著讀取時的版本。
+// 座位庫存變化後提升版本號，之後的讀取使用新的鍵，舊版本的緩存不再被讀到並隨 TTL 過期。
+// 與直接刪除緩存相比，分代不會被「刪除之後、提交之前」讀到舊數據的並發搜索重新寫回。
+type SearchCache interface {
+	// Fetch 讀取 key 在 scopes 當前版本下的緩存並解碼到 dst；未命中時執行 load 並寫入緩存。
+	// 同一進程內相同鍵的並發未命中只執行一次 load，其餘呼叫等待並共用結果。
+	Fetch(ctx context.Context, key string, scopes []string, dst interface{}, load func(ctx context.Context) (interface{}, error)) error
+	// InvalidateFlight 提升航班所在航線與出發日期的版本，應在修改座位庫存的事務提交後呼叫；
+	// 失敗只記錄日誌，緩存最遲在 TTL 後過期
+	InvalidateFlight(ctx context.Context, flightID int)
+	// Stats 返回本地與 Redis 兩層緩存的命中統計
+	Stats() SearchCacheStats
+}
+
+// SearchCacheStats 是搜索緩存各層的命中統計。本地層未啟用時 Local 為零值
+type SearchCacheStats struct {
+	Local CacheTierStats `json:"local"`
+	Redis CacheTierStats `json:"redis"`
+}
+
+// localSearchEntry 是本地層保存的結果，記錄依賴的版本鍵以便本進程內的失效能精確刪除
+type localSearchEntry struct {
+	scopes []string
+	data   []byte
+}
+
+type searchCache struct {
+	redis      *redis.Client
+	flightRepo repositories.FlightRepository
+	ttl        time.Duration
+	group      singleflight.Group
+
+	// local 是 Redis 前面的進程內緩存，命中時不需要訪問 Redis；nil 表示未啟用
+	local *LRUCache[localSearchEntry]
+	// invalidations 在每次失效時遞增，查詢期間發生過失效的結果不寫入本地層
+	invalidations atomic.Int64
+
+	redisHits   atomic.Int64
+	redisMisses atomic.Int64
+}
+
+// NewSearchCache 創建搜索緩存，ttl 是單筆結果在 Redis 中的緩存時間。
+//
+// local.TTL 大於 0 時在 Redis 前面增加一層進程內 LRU 緩存。本地層按不帶版本的鍵保存，
+// 本進程內的失效會立即刪除相關條目，其他實例造成的座位變化最遲在 local.TTL 後可見，
+// 因此 local.TTL 應遠短於 ttl。
+func NewSearchCache(redis *redis.Client, flightRepo repositories.FlightRepository, ttl time.Duration, local LRUOptions) SearchCache {
+	c := &searchCache{
+		redis:      redis,
+		flightRepo: flightRepo,
+		ttl:        ttl,
+	}
+	if local.TTL > 0 {
+		c.local = NewLRUCache(local, func(e localSearchEntry) int64 { return int64(len(e.data)) })
+	}
+	return c
+}
+
+// routeScope 是航線在出發機場當地某天的版本鍵，覆蓋該航線當天的直飛航班
+func routeScope(origin, destination string, day time.Time) string {
+	return fmt.Sprintf("flights:version:%s:%s:%s", origin, destination, day.Format("2006-01-02"))
+}
+
+// dayScope 是某天所有航線的版本鍵。轉機行程經過的航線無法預先列出，只能按日期整體失效
+func dayScope(day time.Time) string {
+	return "flights:version:" + day.Format("2006-01-02")
+}
+
+// connectionScopes 返回從 day 出發的轉機行程依賴的版本鍵：
+// 後續航段可能在當地的第二、三天起飛（轉機時間最長 24 小時，加上飛行時間與時差）
+func connectionScopes(day time.Time) []string {
+	return []string{dayScope(day), dayScope(day.AddDate(0, 0, 1)), dayScope(day.AddDate(0, 0, 2))}
+}
+
+func (c *searchCache) Fetch(ctx context.Context, key string, scopes []string, dst interface{}, load func(ctx context.Context) (interface{}, error)) error {
+	if c.local != nil {
+		if entry, ok := c.local.Get(key); ok && json.Unmarshal(entry.data, dst) == nil {
+			return nil
+		}
+	}
+	generation := c.invalidations.Load()
+
+	versionedKey, err := c.versionedKey(ctx, key, scopes)
+	if err != nil {
+		// 讀不到版本就無法判斷緩存是否仍然有效，直接查詢且不寫入緩存
+		logger.Error("Failed to read cache version", zap.Error(err), zap.String("cacheKey", key))
+		value, err := load(ctx)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, dst)
+	}
+
+	if data, err := c.redis.Get(ctx, versionedKey).Bytes(); err == nil && json.Unmarshal(data, dst) == nil {
+		c.redisHits.Add(1)
+		c.setLocal(key, scopes, data, generation)
+		return nil
+	}
+	c.redisMisses.Add(1)
+
+	// 以第一個呼叫者的 ctx 執行查詢；其餘呼叫者只等待結果，自己的 ctx 到期時提前返回
+	ch := c.group.DoChan(versionedKey, func() (interface{}, error) {
+		value, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.redis.Set(ctx, versionedKey, data, c.ttl).Err(); err != nil {
+			logger.Error("Failed to set cache", zap.Error(err), zap.String("cacheKey", versionedKey))
+		}
+		c.setLocal(key, scopes, data, generation)
+		return data, nil
+	})
+
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return res.Err
+		}
+		// 每個呼叫者各自解碼，不會共用同一份可變的結果
+		return json.Unmarshal(res.Val.([]byte), dst)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// setLocal 將結果寫入本地層。讀取版本之後發生過失效時跳過，
+// 避免失效前開始的查詢把舊結果寫回剛被清理的本地層
+func (c *searchCache) setLocal(key string, scopes []string, data []byte, generation int64) {
+	if c.local == nil || c.invalidations.Load() != generation {
+		return
+	}
+	c.local.Set(key, localSearchEntry{scopes: scopes, data: data})
+}
+
+// versionedKey 在 key 後附上 scopes 當前版本的摘要；不存在的版本鍵視為 0
+func (c *searchCache) versionedKey(ctx context.Context, key string, scopes []string) (string, error) {
+	if len(scopes) == 0 {
+		return key, nil
+	}
+
+	values, err := c.redis.MGet(ctx, scopes...).Result()
+	if err != nil {
+		return "", err
+	}
+	versions := make([]string, len(values))
+	for i, v := range values {
+		version, _ := v.(string)
+		if version == "" {
+			version = "0"
+		}
+		versions[i] = version
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(versions, ".")))
+	return fmt.Sprintf("%s:v%x", key, sum[:6]), nil
+}
+
+func (c *searchCache) InvalidateFlight(ctx context.Context, flightID int) {
+	flight, err := c.flightRepo.GetFlightByID(ctx, flightID)
+	if err != nil {
+		logger.Error("Failed to load flight for cache invalidation", zap.Error(err), zap.Int("flightID", flightID))
+		return
+	}
+
+	// 搜索日期按出發機場的當地日期解釋，版本鍵也要用同一個日期
+	departure := flight.DepartureTime
+	if loc, err := time.LoadLocation(flight.OriginTimezone); err == nil {
+		departure = departure.In(loc)
+	}
+
+	scopes := []string{routeScope(flight.Origin, flight.Destination, departure), dayScope(departure)}
+
+	// 版本鍵的存活時間必須長於緩存，否則版本號歸零後可能重新讀到舊版本的緩存
+	pipe := c.redis.TxPipeline()
+	for _, scope := range scopes {
+		pipe.Incr(ctx, scope)
+		pipe.Expire(ctx, scope, 2*c.ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Error("Failed to invalidate search cache", zap.Error(err), zap.Int("flightID", flightID))
+	}
+	// 本地層在版本提升之後清理：此後開始的查詢一定讀到新版本
+	c.invalidateLocal(scopes)
+}
+
+// invalidateLocal 刪除本地層中依賴任一 scopes 的條目
+func (c *searchCache) invalidateLocal(scopes []string) {
+	if c.local == nil {
+		return
+	}
+	c.invalidations.Add(1)
+	c.local.DeleteFunc(func(_ string, entry localSearchEntry) bool {
+		return slices.ContainsFunc(entry.scopes, func(scope string) bool { return slices.Contains(scopes, scope) })
+	})
+}
+
+func (c *searchCache) Stats() SearchCacheStats {
+	stats := SearchCacheStats{
+		Redis: CacheTierStats{Hits: c.redisHits.Load(), Misses: c.redisMisses.Load()},
+	}
+	if c.local != nil {
+		stats.Local = c.local.Stats()
+	}
+	return stats
+}
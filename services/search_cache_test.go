@@ -0,0 +1,177 @@
+package services_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"airline-booking/mocks"
+	"airline-booking/models"
+	"airline-booking/services"
+
+	"github.com/go-redis/redismock/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchCache_InvalidateFlight_UsesLocalDepartureDate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// 20:00 UTC 在台北已經是第二天
+	repo := mocks.NewMockFlightRepository(ctrl)
+	repo.EXPECT().GetFlightByID(gomock.Any(), 7).Return(&models.Flight{
+		ID: 7, Origin: "TPE", Destination: "NRT", OriginTimezone: "Asia/Taipei",
+		DepartureTime: time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC),
+	}, nil)
+
+	mockRedis, redisMock := redismock.NewClientMock()
+	redisMock.ExpectTxPipeline()
+	redisMock.ExpectIncr("flights:version:TPE:NRT:2024-05-02").SetVal(1)
+	redisMock.ExpectExpire("flights:version:TPE:NRT:2024-05-02", 30*time.Minute).SetVal(true)
+	redisMock.ExpectIncr("flights:version:2024-05-02").SetVal(4)
+	redisMock.ExpectExpire("flights:version:2024-05-02", 30*time.Minute).SetVal(true)
+	redisMock.ExpectTxPipelineExec()
+
+	cache := services.NewSearchCache(mockRedis, repo, 15*time.Minute, services.LRUOptions{})
+	cache.InvalidateFlight(context.Background(), 7)
+
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
+
+func TestSearchCache_Fetch_VersionChangesKey(t *testing.T) {
+	mockRedis, redisMock := redismock.NewClientMock()
+	cache := services.NewSearchCache(mockRedis, nil, 15*time.Minute, services.LRUOptions{})
+	scopes := []string{"flights:version:TPE:NRT:2024-05-01"}
+
+	var keys []string
+	captureKey := func(expected, actual []interface{}) error {
+		keys = append(keys, actual[1].(string))
+		return nil
+	}
+	// 第一次讀取時版本為 0 並命中緩存；版本提升後使用新的鍵，未命中而重新查詢
+	redisMock.ExpectMGet(scopes...).SetVal([]interface{}{nil})
+	redisMock.CustomMatch(captureKey).ExpectGet("").SetVal(`["cached"]`)
+	redisMock.ExpectMGet(scopes...).SetVal([]interface{}{"1"})
+	redisMock.CustomMatch(captureKey).ExpectGet("").RedisNil()
+	redisMock.CustomMatch(captureKey).ExpectSet("", nil, 15*time.Minute).SetVal("OK")
+
+	load := func(ctx context.Context) (interface{}, error) {
+		return []string{"fresh"}, nil
+	}
+
+	var first, second []string
+	assert.NoError(t, cache.Fetch(context.Background(), "flights:TPE:NRT:2024-05-01:abc", scopes, &first, load))
+	assert.NoError(t, cache.Fetch(context.Background(), "flights:TPE:NRT:2024-05-01:abc", scopes, &second, load))
+
+	assert.Equal(t, []string{"cached"}, first)
+	assert.Equal(t, []string{"fresh"}, second)
+	if assert.Len(t, keys, 3) {
+		assert.NotEqual(t, keys[0], keys[1])
+		assert.Equal(t, keys[1], keys[2])
+	}
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
+
+func TestSearchCache_Fetch_CoalescesConcurrentMisses(t *testing.T) {
+	const callers = 5
+
+	mockRedis, redisMock := redismock.NewClientMock()
+	redisMock.MatchExpectationsInOrder(false)
+	scopes := []string{"flights:version:TPE:NRT:2024-05-01"}
+	for i := 0; i < callers; i++ {
+		redisMock.ExpectMGet(scopes...).SetVal([]interface{}{nil})
+		redisMock.Regexp().ExpectGet(`^flights:TPE:NRT:2024-05-01:abc:v`).RedisNil()
+	}
+	redisMock.Regexp().ExpectSet(`^flights:TPE:NRT:2024-05-01:abc:v`, `.*`, 15*time.Minute).SetVal("OK")
+
+	cache := services.NewSearchCache(mockRedis, nil, 15*time.Minute, services.LRUOptions{})
+
+	var loads atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	load := func(ctx context.Context) (interface{}, error) {
+		if loads.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return []int{1, 2, 3}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([][]int, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, cache.Fetch(context.Background(), "flights:TPE:NRT:2024-05-01:abc", scopes, &results[i], load))
+		}(i)
+	}
+
+	// 第一個查詢開始後留出時間讓其他呼叫者加入等待，再讓查詢返回
+	<-started
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), loads.Load())
+	for _, result := range results {
+		assert.Equal(t, []int{1, 2, 3}, result)
+	}
+}
+
+func TestSearchCache_Fetch_LocalTier(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockFlightRepository(ctrl)
+	repo.EXPECT().GetFlightByID(gomock.Any(), 7).Return(&models.Flight{
+		ID: 7, Origin: "TPE", Destination: "NRT", OriginTimezone: "Asia/Taipei",
+		DepartureTime: time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC),
+	}, nil)
+
+	mockRedis, redisMock := redismock.NewClientMock()
+	cache := services.NewSearchCache(mockRedis, repo, 15*time.Minute, services.LRUOptions{MaxEntries: 10, TTL: time.Minute})
+	key := "flights:TPE:NRT:2024-05-01:abc"
+	scopes := []string{"flights:version:TPE:NRT:2024-05-01"}
+
+	// 第一次未命中兩層並寫入；第二次由本地層回答，不訪問 Redis
+	redisMock.ExpectMGet(scopes...).SetVal([]interface{}{nil})
+	redisMock.Regexp().ExpectGet(`^flights:TPE:NRT:2024-05-01:abc:v`).RedisNil()
+	redisMock.Regexp().ExpectSet(`^flights:TPE:NRT:2024-05-01:abc:v`, `.*`, 15*time.Minute).SetVal("OK")
+	// 失效後本地條目被刪除，第三次讀取回到 Redis 的新版本
+	redisMock.ExpectTxPipeline()
+	redisMock.ExpectIncr("flights:version:TPE:NRT:2024-05-01").SetVal(1)
+	redisMock.ExpectExpire("flights:version:TPE:NRT:2024-05-01", 30*time.Minute).SetVal(true)
+	redisMock.ExpectIncr("flights:version:2024-05-01").SetVal(1)
+	redisMock.ExpectExpire("flights:version:2024-05-01", 30*time.Minute).SetVal(true)
+	redisMock.ExpectTxPipelineExec()
+	redisMock.ExpectMGet(scopes...).SetVal([]interface{}{"1"})
+	redisMock.Regexp().ExpectGet(`^flights:TPE:NRT:2024-05-01:abc:v`).SetVal(`["after"]`)
+
+	var loads int
+	load := func(ctx context.Context) (interface{}, error) {
+		loads++
+		return []string{"before"}, nil
+	}
+
+	var first, second, third []string
+	assert.NoError(t, cache.Fetch(context.Background(), key, scopes, &first, load))
+	assert.NoError(t, cache.Fetch(context.Background(), key, scopes, &second, load))
+	cache.InvalidateFlight(context.Background(), 7)
+	assert.NoError(t, cache.Fetch(context.Background(), key, scopes, &third, load))
+
+	assert.Equal(t, 1, loads)
+	assert.Equal(t, []string{"before"}, first)
+	assert.Equal(t, []string{"before"}, second)
+	assert.Equal(t, []string{"after"}, third)
+
+	stats := cache.Stats()
+	assert.Equal(t, int64(1), stats.Local.Hits)
+	assert.Equal(t, int64(2), stats.Local.Misses)
+	assert.Equal(t, 1, stats.Local.Entries)
+	assert.Equal(t, services.CacheTierStats{Hits: 1, Misses: 1}, stats.Redis)
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
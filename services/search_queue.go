@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"airline-booking/models"
+)
+
+// searchJobSaveTimeout 是寫回任務最終狀態的超時，這一步不使用任務本身可能已取消的 ctx
+const searchJobSaveTimeout = 5 * time.Second
+
+var (
+	// ErrSearchQueueFull 表示搜索隊列已滿，調用方應稍後重試而不是阻塞等待
+	ErrSearchQueueFull = errors.New("search queue is full")
+	// ErrSearchJobNotFound 表示請求 ID 不存在或結果已經過期
+	ErrSearchJobNotFound = errors.New("search request not found")
+)
+
+// SearchQueue 是異步搜索任務的隊列與任務存儲。
+// 進程內實現只能由接收請求的實例回答；Redis 實現讓多個實例共享任務與結果。
+type SearchQueue interface {
+	// Enqueue 保存任務並加入隊列，隊列已滿時立即返回 ErrSearchQueueFull
+	Enqueue(ctx context.Context, job *models.SearchJob) error
+	// Dequeue 阻塞直到取得下一個任務或 ctx 結束
+	Dequeue(ctx context.Context) (*models.SearchJob, error)
+	// SaveJob 寫回任務的狀態與結果
+	SaveJob(ctx context.Context, job *models.SearchJob) error
+	// GetJob 按請求 ID 查詢任務，不存在或已過期時返回 ErrSearchJobNotFound
+	GetJob(ctx context.Context, requestID string) (*models.SearchJob, error)
+	// WaitForJob 阻塞直到任務結束（成功或失敗）並返回任務；
+	// ctx 先結束時返回當前的任務快照與 ctx 的錯誤
+	WaitForJob(ctx context.Context, requestID string) (*models.SearchJob, error)
+	// RequeueStale 把已被取出但執行中斷（實例崩潰或重啟）的任務放回隊列，返回放回的數量
+	RequeueStale(ctx context.Context) (int, error)
+	// Len 返回尚未被取走的任務數
+	Len(ctx context.Context) (int, error)
+}
+
+type memorySearchQueue struct {
+	queue chan string
+	// pending 保存排隊中與執行中的任務，數量受隊列容量與工作協程數限制，不做淘汰
+	pending map[string]models.SearchJob
+	// jobs 保存已結束的任務，按容量與 TTL 淘汰，長時間運行不會無限增長；被淘汰的任務與過期一樣返回 ErrSearchJobNotFound
+	jobs *LRUCache[models.SearchJob]
+	// waiters 記錄等待任務結束的調用方，任務結束時關閉對應的 channel 喚醒它們
+	waiters map[string][]chan struct{}
+	mutex   sync.Mutex
+}
+
+// NewMemorySearchQueue 創建進程內的搜索隊列，size 為隊列容量，jobs 限制保存的已結束任務與結果
+func NewMemorySearchQueue(size int, jobs LRUOptions) SearchQueue {
+	return &memorySearchQueue{
+		queue:   make(chan string, size),
+		pending: make(map[string]models.SearchJob),
+		jobs:    NewLRUCache(jobs, searchJobSize),
+		waiters: make(map[string][]chan struct{}),
+	}
+}
+
+// searchJobSize 以 JSON 編碼後的長度估算任務佔用的內存，結果越多的任務越大
+func searchJobSize(job models.SearchJob) int64 {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return 0
+	}
+	return int64(len(data))
+}
+
+func (q *memorySearchQueue) Enqueue(ctx context.Context, job *models.SearchJob) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	select {
+	case q.queue <- job.ID:
+		q.pending[job.ID] = *job
+		return nil
+	default:
+		return ErrSearchQueueFull
+	}
+}
+
+func (q *memorySearchQueue) Dequeue(ctx context.Context) (*models.SearchJob, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case requestID := <-q.queue:
+			job, err := q.GetJob(ctx, requestID)
+			if errors.Is(err, ErrSearchJobNotFound) {
+				continue
+			}
+			return job, err
+		}
+	}
+}
+
+func (q *memorySearchQueue) SaveJob(ctx context.Context, job *models.SearchJob) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if !job.IsFinished() {
+		q.pending[job.ID] = *job
+		return nil
+	}
+	delete(q.pending, job.ID)
+	q.jobs.Set(job.ID, *job)
+	for _, waiter := range q.waiters[job.ID] {
+		close(waiter)
+	}
+	delete(q.waiters, job.ID)
+	return nil
+}
+
+func (q *memorySearchQueue) GetJob(ctx context.Context, requestID string) (*models.SearchJob, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	// 存的是值而不是指針，返回的副本可以安全地被調用方修改
+	job, ok := q.lookup(requestID)
+	if !ok {
+		return nil, ErrSearchJobNotFound
+	}
+	return &job, nil
+}
+
+// lookup 先查排隊中與執行中的任務再查已結束的任務，調用方需持有 mutex
+func (q *memorySearchQueue) lookup(requestID string) (models.SearchJob, bool) {
+	if job, ok := q.pending[requestID]; ok {
+		return job, true
+	}
+	return q.jobs.Get(requestID)
+}
+
+func (q *memorySearchQueue) WaitForJob(ctx context.Context, requestID string) (*models.SearchJob, error) {
+	// 檢查狀態與註冊等待在同一把鎖內完成，不會錯過兩者之間結束的任務
+	q.mutex.Lock()
+	job, ok := q.lookup(requestID)
+	if !ok {
+		q.mutex.Unlock()
+		return nil, ErrSearchJobNotFound
+	}
+	if job.IsFinished() {
+		q.mutex.Unlock()
+		return &job, nil
+	}
+	waiter := make(chan struct{})
+	q.waiters[requestID] = append(q.waiters[requestID], waiter)
+	q.mutex.Unlock()
+
+	select {
+	case <-waiter:
+		return q.GetJob(ctx, requestID)
+	case <-ctx.Done():
+		q.removeWaiter(requestID, waiter)
+		job, err := q.GetJob(context.Background(), requestID)
+		if err != nil {
+			return nil, err
+		}
+		return job, ctx.Err()
+	}
+}
+
+func (q *memorySearchQueue) removeWaiter(requestID string, waiter chan struct{}) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	waiters := q.waiters[requestID]
+	for i, w := range waiters {
+		if w == waiter {
+			q.waiters[requestID] = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(q.waiters[requestID]) == 0 {
+		delete(q.waiters, requestID)
+	}
+}
+
+// RequeueStale 沒有可以放回的任務：進程內的任務與執行它的實例一起消失
+func (q *memorySearchQueue) RequeueStale(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (q *memorySearchQueue) Len(ctx context.Context) (int, error) {
+	return len(q.queue), nil
+}
@@ -0,0 +1,53 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"airline-booking/models"
+	"airline-booking/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemorySearchQueue_EvictsOldJobs(t *testing.T) {
+	ctx := context.Background()
+	queue := services.NewMemorySearchQueue(10, services.LRUOptions{MaxEntries: 2})
+
+	for _, id := range []string{"a", "b", "c"} {
+		assert.NoError(t, queue.SaveJob(ctx, &models.SearchJob{ID: id, Status: models.SearchJobDone}))
+	}
+
+	// 超出容量的最舊任務與過期一樣查不到
+	_, err := queue.GetJob(ctx, "a")
+	assert.ErrorIs(t, err, services.ErrSearchJobNotFound)
+	job, err := queue.GetJob(ctx, "c")
+	assert.NoError(t, err)
+	assert.Equal(t, "c", job.ID)
+}
+
+func TestMemorySearchQueue_KeepsPendingJobs(t *testing.T) {
+	ctx := context.Background()
+	queue := services.NewMemorySearchQueue(10, services.LRUOptions{MaxEntries: 1})
+
+	for _, id := range []string{"a", "b", "c"} {
+		assert.NoError(t, queue.Enqueue(ctx, &models.SearchJob{ID: id, Status: models.SearchJobQueued}))
+	}
+	assert.NoError(t, queue.SaveJob(ctx, &models.SearchJob{ID: "d", Status: models.SearchJobDone}))
+
+	// 已結束任務的容量不影響還沒執行的任務
+	dequeueCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	for _, id := range []string{"a", "b", "c"} {
+		job, err := queue.Dequeue(dequeueCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, id, job.ID)
+	}
+	assert.NoError(t, queue.SaveJob(ctx, &models.SearchJob{ID: "a", Status: models.SearchJobDone}))
+	_, err := queue.GetJob(ctx, "d")
+	assert.ErrorIs(t, err, services.ErrSearchJobNotFound)
+	job, err := queue.GetJob(ctx, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, models.SearchJobQueued, job.Status)
+}
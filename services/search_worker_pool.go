@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"airline-booking/logger"
+	"airline-booking/models"
+
+	"go.uber.org/zap"
+)
+
+// SearchWorkerPoolStats 是工作池的即時指標，用於評估工作協程數量是否足夠
+type SearchWorkerPoolStats struct {
+	Workers    int   `json:"workers"`
+	QueueDepth int   `json:"queue_depth"`
+	InFlight   int64 `json:"in_flight"`
+	Processed  int64 `json:"processed"`
+	Panics     int64 `json:"panics"`
+}
+
+// SearchWorkerPool 以固定數量的工作協程從 SearchQueue 取出任務並交給 FlightService 處理
+type SearchWorkerPool struct {
+	service    FlightService
+	queue      SearchQueue
+	size       int
+	jobTimeout time.Duration
+
+	inFlight  atomic.Int64
+	processed atomic.Int64
+	panics    atomic.Int64
+
+	// stop 讓工作協程停止取新任務；abort 在關閉超時後取消仍在執行的任務
+	stop  context.CancelFunc
+	abort context.CancelFunc
+	wg    sync.WaitGroup
+}
+
+func NewSearchWorkerPool(service FlightService, size int, jobTimeout time.Duration) *SearchWorkerPool {
+	return &SearchWorkerPool{
+		service:    service,
+		queue:      service.GetSearchQueue(),
+		size:       size,
+		jobTimeout: jobTimeout,
+	}
+}
+
+// Start 啟動工作協程，立即返回
+func (p *SearchWorkerPool) Start() {
+	stopCtx, stop := context.WithCancel(context.Background())
+	jobCtx, abort := context.WithCancel(context.Background())
+	p.stop = stop
+	p.abort = abort
+
+	for i := 0; i < p.size; i++ {
+		p.wg.Add(1)
+		go p.work(stopCtx, jobCtx)
+	}
+	p.wg.Add(1)
+	go p.requeueStale(stopCtx)
+
+	logger.Info("Search worker pool started", zap.Int("workers", p.size), zap.Duration("jobTimeout", p.jobTimeout))
+}
+
+// Shutdown 停止取新任務並等待執行中的任務完成；
+// ctx 到期時取消仍在執行的任務並返回 ctx 的錯誤
+func (p *SearchWorkerPool) Shutdown(ctx context.Context) error {
+	if p.stop == nil {
+		return nil
+	}
+	p.stop()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.abort()
+		return nil
+	case <-ctx.Done():
+		p.abort()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (p *SearchWorkerPool) Stats(ctx context.Context) (SearchWorkerPoolStats, error) {
+	depth, err := p.queue.Len(ctx)
+	if err != nil {
+		return SearchWorkerPoolStats{}, err
+	}
+
+	return SearchWorkerPoolStats{
+		Workers:    p.size,
+		QueueDepth: depth,
+		InFlight:   p.inFlight.Load(),
+		Processed:  p.processed.Load(),
+		Panics:     p.panics.Load(),
+	}, nil
+}
+
+func (p *SearchWorkerPool) work(stopCtx, jobCtx context.Context) {
+	defer p.wg.Done()
+
+	for {
+		job, err := p.queue.Dequeue(stopCtx)
+		if stopCtx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to dequeue search request", zap.Error(err))
+			// 避免隊列後端故障時空轉
+			select {
+			case <-stopCtx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		p.run(jobCtx, job)
+	}
+}
+
+// requeueStale 啟動時以及之後每個 jobTimeout 把執行中斷的任務放回隊列，
+// 包括本實例上次運行時未完成的任務與其他實例崩潰時留下的任務
+func (p *SearchWorkerPool) requeueStale(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.jobTimeout)
+	defer ticker.Stop()
+	for {
+		requeued, err := p.queue.RequeueStale(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error("Failed to requeue stale search requests", zap.Error(err))
+		}
+		if requeued > 0 {
+			logger.Info("Requeued stale search requests", zap.Int("count", requeued))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// run 以獨立的超時 context 執行單個任務，任務 panic 時將其標記為失敗而不影響工作協程
+func (p *SearchWorkerPool) run(parent context.Context, job *models.SearchJob) {
+	ctx, cancel := context.WithTimeout(parent, p.jobTimeout)
+	defer cancel()
+
+	p.inFlight.Add(1)
+	defer p.inFlight.Add(-1)
+	defer p.processed.Add(1)
+
+	defer func() {
+		if r := recover(); r != nil {
+			p.panics.Add(1)
+			logger.Error("Search job panicked",
+				zap.String("requestID", job.ID),
+				zap.Any("panic", r))
+
+			job.Status = models.SearchJobFailed
+			job.Error = fmt.Sprintf("internal error: %v", r)
+			job.UpdatedAt = time.Now()
+			saveCtx, cancel := context.WithTimeout(context.Background(), searchJobSaveTimeout)
+			defer cancel()
+			if err := p.queue.SaveJob(saveCtx, job); err != nil {
+				logger.Error("Failed to save search job", zap.Error(err), zap.String("requestID", job.ID))
+			}
+		}
+	}()
+
+	p.service.ProcessSearchJob(ctx, job)
+}
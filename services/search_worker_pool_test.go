@@ -0,0 +1,151 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"airline-booking/mocks"
+	"airline-booking/models"
+	"airline-booking/services"
+
+	"github.com/go-redis/redismock/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubFlightService 只實現工作池需要的方法，process 決定每個任務的行為
+type stubFlightService struct {
+	services.FlightService
+	queue   services.SearchQueue
+	process func(ctx context.Context, job *models.SearchJob)
+}
+
+func (s *stubFlightService) GetSearchQueue() services.SearchQueue {
+	return s.queue
+}
+
+func (s *stubFlightService) ProcessSearchJob(ctx context.Context, job *models.SearchJob) {
+	s.process(ctx, job)
+}
+
+func enqueueJob(t *testing.T, queue services.SearchQueue, id string) {
+	t.Helper()
+	err := queue.Enqueue(context.Background(), &models.SearchJob{ID: id, Status: models.SearchJobQueued})
+	assert.NoError(t, err)
+}
+
+func TestSearchWorkerPool_RecoversFromPanic(t *testing.T) {
+	queue := services.NewMemorySearchQueue(10, services.LRUOptions{})
+	service := &stubFlightService{queue: queue, process: func(ctx context.Context, job *models.SearchJob) {
+		panic("boom")
+	}}
+
+	pool := services.NewSearchWorkerPool(service, 1, time.Second)
+	pool.Start()
+	defer pool.Shutdown(context.Background())
+
+	enqueueJob(t, queue, "a")
+	enqueueJob(t, queue, "b")
+
+	// 第一個任務 panic 後工作協程仍然繼續處理下一個任務
+	assert.Eventually(t, func() bool {
+		job, err := queue.GetJob(context.Background(), "b")
+		return err == nil && job.Status == models.SearchJobFailed
+	}, time.Second, 10*time.Millisecond)
+
+	stats, err := pool.Stats(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Panics)
+}
+
+func TestSearchWorkerPool_JobTimeout(t *testing.T) {
+	queue := services.NewMemorySearchQueue(10, services.LRUOptions{})
+	deadlines := make(chan bool, 1)
+	service := &stubFlightService{queue: queue, process: func(ctx context.Context, job *models.SearchJob) {
+		<-ctx.Done()
+		deadlines <- ctx.Err() == context.DeadlineExceeded
+	}}
+
+	pool := services.NewSearchWorkerPool(service, 1, 20*time.Millisecond)
+	pool.Start()
+	defer pool.Shutdown(context.Background())
+
+	enqueueJob(t, queue, "slow")
+
+	select {
+	case exceeded := <-deadlines:
+		assert.True(t, exceeded)
+	case <-time.After(time.Second):
+		t.Fatal("job context was not cancelled by the per-job timeout")
+	}
+}
+
+// contextAwareQueue 像 Redis 實現一樣在 ctx 已結束時寫入失敗
+type contextAwareQueue struct {
+	services.SearchQueue
+}
+
+func (q *contextAwareQueue) SaveJob(ctx context.Context, job *models.SearchJob) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return q.SearchQueue.SaveJob(ctx, job)
+}
+
+func TestSearchWorkerPool_JobTimeoutMarksFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockFlightRepository(ctrl)
+	mockRedis, _ := redismock.NewClientMock()
+	// 查詢一直阻塞到任務超時
+	mockRepo.EXPECT().SearchFlights(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ models.SearchRequest) (*models.FlightPage, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+
+	queue := &contextAwareQueue{services.NewMemorySearchQueue(10, services.LRUOptions{})}
+	service := services.NewFlightService(mockRepo, services.NewSearchCache(mockRedis, mockRepo, 15*time.Minute, services.LRUOptions{}), queue, stubPricing(ctrl))
+	pool := services.NewSearchWorkerPool(service, 1, 20*time.Millisecond)
+	pool.Start()
+	defer pool.Shutdown(context.Background())
+
+	requestID, err := service.SearchFlights(context.Background(), models.SearchRequest{Origin: "TPE", Destination: "NRT", Date: time.Now()})
+	assert.NoError(t, err)
+
+	// 超時後任務的 ctx 已取消，最終狀態仍然寫回
+	assert.Eventually(t, func() bool {
+		job, err := queue.GetJob(context.Background(), requestID)
+		return err == nil && job.Status == models.SearchJobFailed
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSearchWorkerPool_ShutdownDrainsInFlight(t *testing.T) {
+	queue := services.NewMemorySearchQueue(10, services.LRUOptions{})
+	started := make(chan struct{})
+	finished := make(chan struct{})
+	service := &stubFlightService{queue: queue, process: func(ctx context.Context, job *models.SearchJob) {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		close(finished)
+	}}
+
+	pool := services.NewSearchWorkerPool(service, 1, time.Second)
+	pool.Start()
+	enqueueJob(t, queue, "in-flight")
+	<-started
+
+	stats, _ := pool.Stats(context.Background())
+	assert.Equal(t, int64(1), stats.InFlight)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, pool.Shutdown(ctx))
+
+	// Shutdown 返回時執行中的任務已經完成
+	select {
+	case <-finished:
+	default:
+		t.Fatal("Shutdown returned before the in-flight job finished")
+	}
+}
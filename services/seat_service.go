@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"airline-booking/models"
+	"airline-booking/repositories"
+)
+
+// SeatService 按機型的座位配置管理航班的選座。
+// 座位唯一性最終由 bookings 表的部分唯一索引保證，這裡的檢查只是為了返回更明確的錯誤
+type SeatService interface {
+	// GetSeatMap 返回航班的即時座位圖；機型沒有座位配置時 Seats 為空
+	GetSeatMap(ctx context.Context, flightID int) (*models.FlightSeatMap, error)
+	// AssignSeat 為預訂確定座位：booking.SeatNumber 不為空時檢查該座位存在、屬於預訂的艙位且未被佔用，
+	// 否則按乘客偏好自動分配艙位內的空座位，艙位已滿時保持未分配。
+	// 需在事務中且航班已被鎖住時呼叫，避免並發的分配選中同一座位
+	AssignSeat(ctx context.Context, flight *models.Flight, booking *models.Booking, preference string) error
+	// LoadSeatLayouts 把機型的座位配置寫入數據庫，已存在的座位保持不變，可以重複執行
+	LoadSeatLayouts(ctx context.Context, layouts []models.SeatLayout) error
+}
+
+type seatService struct {
+	flightRepo repositories.FlightRepository
+	seatRepo   repositories.SeatRepository
+}
+
+func NewSeatService(flightRepo repositories.FlightRepository, seatRepo repositories.SeatRepository) SeatService {
+	return &seatService{
+		flightRepo: flightRepo,
+		seatRepo:   seatRepo,
+	}
+}
+
+func (s *seatService) GetSeatMap(ctx context.Context, flightID int) (*models.FlightSeatMap, error) {
+	flight, err := s.flightRepo.GetFlightByID(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+	seats, err := s.seatRepo.GetSeatMap(ctx, flight.AircraftType)
+	if err != nil {
+		return nil, err
+	}
+	occupied, err := s.seatRepo.GetOccupiedSeats(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+
+	seatMap := &models.FlightSeatMap{
+		FlightID:     flightID,
+		AircraftType: flight.AircraftType,
+		Seats:        make([]models.FlightSeat, len(seats)),
+	}
+	for i, seat := range seats {
+		_, taken := occupied[seat.Number]
+		seatMap.Seats[i] = models.FlightSeat{Seat: seat, Available: !taken}
+	}
+	return seatMap, nil
+}
+
+func (s *seatService) AssignSeat(ctx context.Context, flight *models.Flight, booking *models.Booking, preference string) error {
+	seats, err := s.seatRepo.GetSeatMap(ctx, flight.AircraftType)
+	if err != nil {
+		return err
+	}
+	if len(seats) == 0 {
+		if booking.SeatNumber != "" {
+			return &ValidationError{Field: "seat_number", Message: fmt.Sprintf("aircraft %q has no seat map", flight.AircraftType)}
+		}
+		return nil
+	}
+
+	occupied, err := s.seatRepo.GetOccupiedSeats(ctx, flight.ID)
+	if err != nil {
+		return err
+	}
+	// 預訂自己已佔用的座位不算被佔用，改簽時可以保留原座位
+	free := func(seat models.Seat) bool {
+		id, taken := occupied[seat.Number]
+		return seat.Cabin == booking.Class && (!taken || id == booking.ID)
+	}
+
+	if booking.SeatNumber != "" {
+		number := strings.ToUpper(strings.TrimSpace(booking.SeatNumber))
+		i := slices.IndexFunc(seats, func(seat models.Seat) bool { return seat.Number == number })
+		if i < 0 {
+			return &ValidationError{Field: "seat_number", Message: fmt.Sprintf("seat %s does not exist on this aircraft", number)}
+		}
+		if seats[i].Cabin != booking.Class {
+			return &ValidationError{Field: "seat_number", Message: fmt.Sprintf("seat %s is not in the %s cabin", number, booking.Class)}
+		}
+		if !free(seats[i]) {
+			return repositories.ErrSeatTaken
+		}
+		booking.SeatNumber = number
+		return nil
+	}
+
+	// 優先選符合偏好的座位，沒有時選艙位內第一個空座位；都沒有時（例如超賣）留待登機時處理
+	preference = models.NormalizeSeatPreference(preference)
+	i := slices.IndexFunc(seats, func(seat models.Seat) bool { return free(seat) && seat.Matches(preference) })
+	if i < 0 {
+		i = slices.IndexFunc(seats, free)
+	}
+	if i >= 0 {
+		booking.SeatNumber = seats[i].Number
+	}
+	return nil
+}
+
+func (s *seatService) LoadSeatLayouts(ctx context.Context, layouts []models.SeatLayout) error {
+	for _, layout := range layouts {
+		if err := s.seatRepo.SaveSeatMap(ctx, layout.AircraftType, layout.Seats()); err != nil {
+			return fmt.Errorf("load seat layout %s: %w", layout.AircraftType, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"airline-booking/mocks"
+	"airline-booking/models"
+	"airline-booking/repositories"
+	"airline-booking/services"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// testSeatMap 是一個兩排商務艙、兩排經濟艙的小型機型，第 3 排是緊急出口
+var testSeatMap = []models.Seat{
+	{Number: "1A", Row: 1, Letter: "A", Cabin: "business", Window: true},
+	{Number: "1C", Row: 1, Letter: "C", Cabin: "business", Aisle: true},
+	{Number: "2A", Row: 2, Letter: "A", Cabin: "economy", Window: true},
+	{Number: "2B", Row: 2, Letter: "B", Cabin: "economy"},
+	{Number: "2C", Row: 2, Letter: "C", Cabin: "economy", Aisle: true},
+	{Number: "3A", Row: 3, Letter: "A", Cabin: "economy", Window: true, ExtraLegroom: true},
+	{Number: "3B", Row: 3, Letter: "B", Cabin: "economy", ExtraLegroom: true},
+	{Number: "3C", Row: 3, Letter: "C", Cabin: "economy", Aisle: true, ExtraLegroom: true},
+}
+
+func newSeatService(t *testing.T, occupied map[string]int) (services.SeatService, *mocks.MockFlightRepository) {
+	ctrl := gomock.NewController(t)
+	flights := mocks.NewMockFlightRepository(ctrl)
+	seats := mocks.NewMockSeatRepository(ctrl)
+	seats.EXPECT().GetSeatMap(gomock.Any(), "E190").Return(testSeatMap, nil).AnyTimes()
+	seats.EXPECT().GetOccupiedSeats(gomock.Any(), 7).Return(occupied, nil).AnyTimes()
+	return services.NewSeatService(flights, seats), flights
+}
+
+func TestSeatService_GetSeatMap(t *testing.T) {
+	service, flights := newSeatService(t, map[string]int{"2A": 11})
+	flights.EXPECT().GetFlightByID(gomock.Any(), 7).Return(&models.Flight{ID: 7, AircraftType: "E190"}, nil)
+
+	seatMap, err := service.GetSeatMap(context.Background(), 7)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "E190", seatMap.AircraftType)
+	assert.Len(t, seatMap.Seats, len(testSeatMap))
+	assert.False(t, seatMap.Seats[2].Available)
+	assert.True(t, seatMap.Seats[3].Available)
+}
+
+func TestSeatService_AssignSeat_Preference(t *testing.T) {
+	flight := &models.Flight{ID: 7, AircraftType: "E190"}
+	occupied := map[string]int{"2A": 11, "3C": 12}
+
+	tests := []struct {
+		name       string
+		class      string
+		preference string
+		want       string
+	}{
+		{"window skips occupied seats", "economy", "Window", "3A"},
+		{"aisle", "economy", "aisle", "2C"},
+		{"extra legroom", "economy", "Extra Legroom", "3A"},
+		{"no preference takes first free seat", "economy", "", "2B"},
+		{"unknown preference takes first free seat", "economy", "near the galley", "2B"},
+		{"stays within the cabin", "business", "aisle", "1C"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newSeatService(t, occupied)
+			booking := &models.Booking{ID: 20, FlightID: 7, Class: tt.class}
+
+			err := service.AssignSeat(context.Background(), flight, booking, tt.preference)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, booking.SeatNumber)
+		})
+	}
+}
+
+func TestSeatService_AssignSeat_StandardLayout(t *testing.T) {
+	var a320 models.SeatLayout
+	for _, layout := range models.StandardSeatLayouts {
+		if layout.AircraftType == "A320" {
+			a320 = layout
+		}
+	}
+	flight := &models.Flight{ID: 7, AircraftType: "A320"}
+	occupied := map[string]int{"4A": 11, "12A": 12, "1C": 13}
+
+	tests := []struct {
+		name       string
+		class      string
+		preference string
+		want       string
+	}{
+		{"window", "economy", "window", "4F"},
+		{"aisle", "economy", "aisle", "4C"},
+		{"extra legroom", "economy", "extra_legroom", "12B"},
+		{"business aisle", "business", "aisle", "1D"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			seats := mocks.NewMockSeatRepository(ctrl)
+			seats.EXPECT().GetSeatMap(gomock.Any(), "A320").Return(a320.Seats(), nil)
+			seats.EXPECT().GetOccupiedSeats(gomock.Any(), 7).Return(occupied, nil)
+			service := services.NewSeatService(mocks.NewMockFlightRepository(ctrl), seats)
+			booking := &models.Booking{ID: 20, FlightID: 7, Class: tt.class}
+
+			err := service.AssignSeat(context.Background(), flight, booking, tt.preference)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, booking.SeatNumber)
+		})
+	}
+}
+
+func TestSeatService_LoadSeatLayouts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	seats := mocks.NewMockSeatRepository(ctrl)
+	service := services.NewSeatService(mocks.NewMockFlightRepository(ctrl), seats)
+
+	// A320：3 排商務艙每排 4 座，27 排經濟艙每排 6 座
+	seats.EXPECT().SaveSeatMap(gomock.Any(), "A320", gomock.Len(3*4+27*6)).Return(nil)
+	seats.EXPECT().SaveSeatMap(gomock.Any(), "B738", gomock.Any()).Return(errors.New("connection refused"))
+
+	err := service.LoadSeatLayouts(context.Background(), models.StandardSeatLayouts)
+
+	assert.ErrorContains(t, err, "B738")
+}
+
+func TestSeatService_AssignSeat_CabinFull(t *testing.T) {
+	service, _ := newSeatService(t, map[string]int{"1A": 11, "1C": 12})
+	booking := &models.Booking{ID: 20, FlightID: 7, Class: "business"}
+
+	err := service.AssignSeat(context.Background(), &models.Flight{ID: 7, AircraftType: "E190"}, booking, "window")
+
+	// 超賣的乘客暫不分配座位
+	assert.NoError(t, err)
+	assert.Empty(t, booking.SeatNumber)
+}
+
+func TestSeatService_AssignSeat_Requested(t *testing.T) {
+	flight := &models.Flight{ID: 7, AircraftType: "E190"}
+	occupied := map[string]int{"2A": 11, "2B": 20}
+
+	tests := []struct {
+		name    string
+		seat    string
+		want    string
+		wantErr error
+		invalid bool
+	}{
+		{"free seat", "3b", "3B", nil, false},
+		{"own seat", "2B", "2B", nil, false},
+		{"taken", "2A", "", repositories.ErrSeatTaken, false},
+		{"other cabin", "1A", "", nil, true},
+		{"unknown seat", "40F", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newSeatService(t, occupied)
+			booking := &models.Booking{ID: 20, FlightID: 7, Class: "economy", SeatNumber: tt.seat}
+
+			err := service.AssignSeat(context.Background(), flight, booking, "window")
+
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			case tt.invalid:
+				var validationErr *services.ValidationError
+				assert.ErrorAs(t, err, &validationErr)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, booking.SeatNumber)
+			}
+		})
+	}
+}
+
+func TestSeatService_AssignSeat_NoSeatMap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	seats := mocks.NewMockSeatRepository(ctrl)
+	seats.EXPECT().GetSeatMap(gomock.Any(), "B738").Return(nil, nil).Times(2)
+	service := services.NewSeatService(mocks.NewMockFlightRepository(ctrl), seats)
+	flight := &models.Flight{ID: 7, AircraftType: "B738"}
+
+	booking := &models.Booking{ID: 20, FlightID: 7, Class: "economy"}
+	assert.NoError(t, service.AssignSeat(context.Background(), flight, booking, "window"))
+	assert.Empty(t, booking.SeatNumber)
+
+	// 沒有座位配置時無法驗證指定的座位
+	booking.SeatNumber = "12A"
+	var validationErr *services.ValidationError
+	assert.ErrorAs(t, service.AssignSeat(context.Background(), flight, booking, ""), &validationErr)
+}